@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/codec/bookcsv"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/codec/marc"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/usecase"
+)
+
+const (
+	fileCSV     = "csv"
+	fileMARC21  = "marc21"
+	fileMARCXML = "marcxml"
+)
+
+func (a *app) book(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "import":
+		return a.bookImport(ctx, args[1:])
+	case "export":
+		return a.bookExport(ctx, args[1:])
+	default:
+		return errUsage
+	}
+}
+
+func (a *app) bookImport(ctx context.Context, args []string) error {
+	flags := newFlagSet("book import")
+	format := flags.String("format", fileCSV, "file format: csv, marc21 or marcxml")
+	dryRun := flags.Bool("dry-run", false, "only validate the file")
+	mapping := columnMapping{}
+	flags.Var(mapping, "map", "CSV column to field mapping, e.g. Nome=title (repeatable)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errUsage
+	}
+
+	var source io.Reader = os.Stdin
+	if name := flags.Arg(0); name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		source = file
+	}
+	source = bufio.NewReader(source)
+
+	var reader usecase.BookReader
+	var err error
+	switch *format {
+	case fileCSV:
+		reader, err = bookcsv.NewReader(source, mapping)
+	case fileMARC21:
+		reader = marc.NewReader(source)
+	case fileMARCXML:
+		reader = marc.NewXMLReader(source)
+	default:
+		return fmt.Errorf("unsupported format %q", *format)
+	}
+	if err != nil {
+		return err
+	}
+
+	report, err := a.books.ImportBooks(ctx, reader, usecase.ImportOptions{DryRun: *dryRun})
+	if err != nil {
+		// Os lotes gravados antes do erro permanecem no catálogo
+		if report.Imported > 0 {
+			a.out.importReport(report)
+		}
+		return err
+	}
+
+	return a.out.importReport(report)
+}
+
+func (a *app) bookExport(ctx context.Context, args []string) error {
+	flags := newFlagSet("book export")
+	format := flags.String("format", fileCSV, "file format: csv, marc21 or marcxml")
+	out := flags.String("out", "-", "output file, - for stdout")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errUsage
+	}
+	if *format != fileCSV && *format != fileMARC21 && *format != fileMARCXML {
+		return fmt.Errorf("unsupported format %q", *format)
+	}
+
+	var target io.WriteCloser = os.Stdout
+	if *out != "-" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		target = file
+	}
+	buffered := bufio.NewWriter(target)
+
+	var writer usecase.BookWriter
+	switch *format {
+	case fileCSV:
+		writer = bookcsv.NewWriter(buffered)
+	case fileMARC21:
+		writer = marc.NewWriter(buffered)
+	case fileMARCXML:
+		writer = marc.NewXMLWriter(buffered)
+	}
+
+	// Página zero exporta o catálogo inteiro
+	if err := a.books.ExportBooks(ctx, writer, 0, 0); err != nil {
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+
+	if target != os.Stdout {
+		return target.Close()
+	}
+	return nil
+}
+
+// columnMapping acumula as opções -map coluna=campo da importação CSV
+type columnMapping map[string]string
+
+func (m columnMapping) String() string {
+	pairs := make([]string, 0, len(m))
+	for column, field := range m {
+		pairs = append(pairs, column+"="+field)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (m columnMapping) Set(value string) error {
+	column, field, ok := strings.Cut(value, "=")
+	if !ok || column == "" || field == "" {
+		return errors.New("expected <column>=<field>")
+	}
+	m[column] = field
+	return nil
+}
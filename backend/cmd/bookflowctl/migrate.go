@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/migrate"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/migrations"
+)
+
+func (a *app) migrate(ctx context.Context, args []string) error {
+	migrator, err := migrate.New(a.db, migrations.For(a.db.DriverName()))
+	if err != nil {
+		return err
+	}
+
+	result, err := migrator.Run(ctx, args)
+	if errors.Is(err, migrate.ErrUsage) {
+		if err == migrate.ErrUsage {
+			return errUsage
+		}
+		return fmt.Errorf("migrate: %v: %w", err, errUsage)
+	}
+	if err != nil {
+		return err
+	}
+
+	switch result.Command {
+	case "up":
+		return a.out.message(map[string]int{"applied": result.Applied}, "%d migrations applied", result.Applied)
+	case "down":
+		return a.out.message(map[string]int{"reverted": result.Reverted}, "%d migrations reverted", result.Reverted)
+	case "force":
+		return a.out.message(map[string]int64{"version": result.Version}, "Database forced to version %d", result.Version)
+	default:
+		return a.out.print(result.Statuses, func(w io.Writer) {
+			migrate.WriteStatus(w, result.Statuses)
+		})
+	}
+}
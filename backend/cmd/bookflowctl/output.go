@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/usecase"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+// printer escreve os resultados dos comandos no formato escolhido com -o
+type printer struct {
+	format string
+	w      io.Writer
+}
+
+// print escreve value como JSON ou, no formato tabela, chama table com um
+// writer que alinha as colunas separadas por tabulação
+func (p *printer) print(value any, table func(w io.Writer)) error {
+	if p.format == formatJSON {
+		encoder := json.NewEncoder(p.w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	table(tw)
+	return tw.Flush()
+}
+
+func (p *printer) users(users []*domain.User) error {
+	return p.print(users, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tNAME\tEMAIL\tSTATUS\tCREATED AT")
+		for _, user := range users {
+			status := "active"
+			if user.Disabled {
+				status = "disabled"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", user.ID, user.Name, user.Email, status, formatTime(user.CreatedAt))
+		}
+	})
+}
+
+func (p *printer) importReport(report *usecase.ImportReport) error {
+	return p.print(report, func(w io.Writer) {
+		writeImportReport(w, report)
+	})
+}
+
+func writeImportReport(w io.Writer, report *usecase.ImportReport) {
+	fmt.Fprintf(w, "Total:\t%d\n", report.Total)
+	fmt.Fprintf(w, "Valid:\t%d\n", report.Valid)
+	fmt.Fprintf(w, "Imported:\t%d\n", report.Imported)
+	fmt.Fprintf(w, "Duplicates:\t%d\n", report.Duplicates)
+	fmt.Fprintf(w, "Invalid:\t%d\n", report.Invalid)
+	if report.DryRun {
+		fmt.Fprintln(w, "Dry run:\tnothing was saved")
+	}
+
+	if len(report.Errors) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "RECORD\tFIELD\tMESSAGE")
+	for _, recordErr := range report.Errors {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", recordErr.Position, recordErr.Field, recordErr.Message)
+	}
+	if report.ErrorsTruncated {
+		fmt.Fprintln(w, "...\t\tmore errors omitted")
+	}
+}
+
+// message escreve uma confirmação simples; em JSON, o próprio value
+func (p *printer) message(value any, format string, args ...any) error {
+	return p.print(value, func(w io.Writer) {
+		fmt.Fprintf(w, format+"\n", args...)
+	})
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
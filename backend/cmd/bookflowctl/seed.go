@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/usecase"
+)
+
+// seedFile é o formato do arquivo de dados de exemplo. As senhas ficam em
+// texto puro e são gravadas com hash pelo UserService.
+type seedFile struct {
+	Users []struct {
+		Name     string `json:"name"`
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	} `json:"users"`
+	Books []*domain.Book `json:"books"`
+}
+
+type seedResult struct {
+	UsersCreated int                   `json:"users_created"`
+	UsersSkipped int                   `json:"users_skipped"`
+	Books        *usecase.ImportReport `json:"books"`
+}
+
+// seed cadastra os usuários e livros do arquivo. Usuários com email já
+// cadastrado e livros com ISBN já cadastrado são ignorados, então o comando
+// pode ser repetido.
+func (a *app) seed(ctx context.Context, args []string) error {
+	flags := newFlagSet("seed")
+	file := flags.String("file", "fixtures/seed.json", "seed file")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errUsage
+	}
+
+	content, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+	var data seedFile
+	if err := json.Unmarshal(content, &data); err != nil {
+		return fmt.Errorf("read %s: %w", *file, err)
+	}
+
+	result := seedResult{}
+	for _, seedUser := range data.Users {
+		_, err := a.users.GetUserByEmail(ctx, seedUser.Email)
+		if err == nil {
+			result.UsersSkipped++
+			continue
+		}
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			return err
+		}
+
+		user := &domain.User{Name: seedUser.Name, Email: seedUser.Email, Password: seedUser.Password}
+		if err := a.users.CreateUser(ctx, user); err != nil {
+			return fmt.Errorf("create user %s: %w", seedUser.Email, err)
+		}
+		result.UsersCreated++
+	}
+
+	if result.Books, err = a.books.ImportBooks(ctx, &bookList{books: data.Books}, usecase.ImportOptions{}); err != nil {
+		return err
+	}
+
+	return a.out.print(result, func(w io.Writer) {
+		fmt.Fprintf(w, "Users created:\t%d\n", result.UsersCreated)
+		fmt.Fprintf(w, "Users skipped:\t%d\n", result.UsersSkipped)
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Books")
+		writeImportReport(w, result.Books)
+	})
+}
+
+// bookList é uma origem de importação com livros já decodificados
+type bookList struct {
+	books []*domain.Book
+	next  int
+}
+
+func (l *bookList) Next() (int, *domain.Book, error) {
+	if l.next == len(l.books) {
+		return 0, nil, io.EOF
+	}
+	l.next++
+	return l.next, l.books[l.next-1], nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+func (a *app) user(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "create":
+		return a.userCreate(ctx, args[1:])
+	case "list":
+		return a.userList(ctx, args[1:])
+	case "disable":
+		return a.userSetDisabled(ctx, args[1:], true)
+	case "enable":
+		return a.userSetDisabled(ctx, args[1:], false)
+	case "reset-password":
+		return a.userResetPassword(ctx, args[1:])
+	default:
+		return errUsage
+	}
+}
+
+func (a *app) userCreate(ctx context.Context, args []string) error {
+	flags := newFlagSet("user create")
+	name := flags.String("name", "", "user name")
+	email := flags.String("email", "", "user email")
+	password := flags.String("password", "", "user password")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *name == "" || *email == "" || flags.NArg() != 0 {
+		return errUsage
+	}
+
+	user := &domain.User{Name: *name, Email: *email, Password: *password}
+	if err := user.Validate(); err != nil {
+		return errors.New("invalid name or email")
+	}
+
+	if user.Password == "" {
+		var err error
+		if user.Password, err = readPassword(); err != nil {
+			return err
+		}
+	}
+
+	if err := a.users.CreateUser(ctx, user); err != nil {
+		return err
+	}
+
+	return a.out.users([]*domain.User{user})
+}
+
+func (a *app) userList(ctx context.Context, args []string) error {
+	flags := newFlagSet("user list")
+	page := flags.Int("page", 1, "page number")
+	pageSize := flags.Int("page-size", 100, "users per page (at most 100)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	users, err := a.users.ListUsers(ctx, *page, *pageSize)
+	if err != nil {
+		return err
+	}
+	if users == nil {
+		users = []*domain.User{}
+	}
+
+	return a.out.users(users)
+}
+
+func (a *app) userSetDisabled(ctx context.Context, args []string, disabled bool) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	user, err := a.findUser(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	if user, err = a.users.SetUserDisabled(ctx, user.ID, disabled); err != nil {
+		return err
+	}
+
+	return a.out.users([]*domain.User{user})
+}
+
+func (a *app) userResetPassword(ctx context.Context, args []string) error {
+	flags := newFlagSet("user reset-password")
+	password := flags.String("password", "", "new password")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errUsage
+	}
+
+	user, err := a.findUser(ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *password == "" {
+		if *password, err = readPassword(); err != nil {
+			return err
+		}
+	}
+
+	if err := a.users.ResetPassword(ctx, user.ID, *password); err != nil {
+		return err
+	}
+
+	return a.out.message(map[string]string{"id": user.ID, "email": user.Email},
+		"Password of %s reset", user.Email)
+}
+
+// findUser busca o usuário pelo email, se ref contiver "@", ou pelo ID
+func (a *app) findUser(ctx context.Context, ref string) (*domain.User, error) {
+	var user *domain.User
+	var err error
+	if strings.Contains(ref, "@") {
+		user, err = a.users.GetUserByEmail(ctx, ref)
+	} else {
+		user, err = a.users.GetUser(ctx, ref)
+	}
+
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, fmt.Errorf("user %q not found", ref)
+	}
+	return user, err
+}
+
+// readPassword lê a senha sem eco no terminal ou a primeira linha da entrada
+// padrão, para uso em scripts
+func readPassword() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, "Password: ")
+		password, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return string(password), err
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -1,19 +1,50 @@
 package main
 
 import (
-    "log"
+    "context"
+    "errors"
+    "flag"
+    "fmt"
+    "log/slog"
+    "net/http"
+    "os"
+    "os/signal"
+    "sync"
+    "syscall"
+    "text/tabwriter"
+    "time"
 
     "github.com/gin-gonic/gin"
+    "github.com/jmoiron/sqlx"
     swaggerFiles "github.com/swaggo/files"
     ginSwagger "github.com/swaggo/gin-swagger"
+    "go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
     // Import the generated docs
     _ "github.com/diogo-aparecido-smartfit/bookflow/backend/docs"
     "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/handler"
+    "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/imaging"
     "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/config"
     "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/database"
+    "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/health"
+    "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/httpserver"
+    "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/logging"
+    "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/metrics"
+    "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/migrate"
+    "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/tracing"
+    "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/metadata"
+    "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/metadata/fixture"
+    "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/metadata/googlebooks"
+    "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/metadata/openlibrary"
+    "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
+    "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository/cache"
     "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository/postgres"
+    "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository/sqlite"
+    "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/storage"
+    "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/storage/local"
+    "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/storage/s3"
     "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/usecase"
+    "github.com/diogo-aparecido-smartfit/bookflow/backend/migrations"
 )
 
 // @title           BookFlow API
@@ -36,44 +67,328 @@ import (
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 func main() {
-    // Carregar configurações
-    cfg, err := config.Load()
+    // Configuração: padrões, arquivo YAML, variáveis de ambiente e flags,
+    // seguidas de um subcomando opcional (migrate ou config print)
+    flags := flag.NewFlagSet("server", flag.ExitOnError)
+    flags.Usage = func() {
+        fmt.Fprintf(flags.Output(), "usage: server [flags] [migrate <command> | config print]\n\nFlags:\n")
+        flags.PrintDefaults()
+    }
+    configFlags := config.RegisterFlags(flags)
+    flags.Parse(os.Args[1:])
+    args := flags.Args()
+
+    cfg, err := config.Load(configFlags)
     if err != nil {
-        log.Fatalf("Failed to load config: %v", err)
+        fatal("Failed to load config", err)
     }
 
-    // Conectar ao banco de dados (Singleton)
-    db, err := database.NewPostgresConnection(cfg.Database)
+    // Subcomando config print: a configuração efetiva, sem os segredos
+    if len(args) > 0 && args[0] == "config" {
+        if len(args) != 2 || args[1] != "print" {
+            flags.Usage()
+            os.Exit(2)
+        }
+        if err := cfg.WriteYAML(os.Stdout); err != nil {
+            fatal("Failed to print config", err)
+        }
+        return
+    }
+
+    // Logs estruturados; o pacote log padrão também passa por este logger
+    logger, err := logging.New(os.Stdout, cfg.Log.Level, cfg.Log.Format)
     if err != nil {
-        log.Fatalf("Failed to connect to database: %v", err)
+        fatal("Invalid log configuration", err)
+    }
+    slog.SetDefault(logger)
+
+    // Tracing das requisições, dos serviços e das consultas SQL
+    shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+    if err != nil {
+        fatal("Invalid tracing configuration", err)
+    }
+    defer func() {
+        ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+        defer cancel()
+        if err := shutdownTracing(ctx); err != nil {
+            slog.Error("Failed to flush traces", "error", err)
+        }
+    }()
+
+    // Conectar ao banco de dados (Postgres ou SQLite), esperando-o subir
+    db, err := database.Open(context.Background(), cfg.Database)
+    if err != nil {
+        fatal("Failed to connect to database", err)
+    }
+    metrics.RegisterDB(db.DB, cfg.Database.DBName)
+
+    // Subcomando de manutenção: server migrate up|down [n]|status|force <versão>
+    if len(args) > 0 && args[0] == "migrate" {
+        if err := runMigrate(db, args[1:]); err != nil {
+            fatal("Migration failed", err)
+        }
+        return
+    }
+
+    migrator, err := migrate.New(db, migrations.For(db.DriverName()))
+    if err != nil {
+        fatal("Failed to load migrations", err)
+    }
+    if cfg.Database.MigrateOnStart {
+        if _, err := migrator.Up(context.Background()); err != nil {
+            fatal("Failed to apply migrations", err)
+        }
     }
     
-    bookRepo := postgres.NewBookRepository(db)
-    userRepo := postgres.NewUserRepository(db)
+    metadataProvider, err := newMetadataProvider(cfg.Metadata)
+    if err != nil {
+        fatal("Failed to configure metadata providers", err)
+    }
+
+    blobStore, err := newBlobStore(cfg.Storage)
+    if err != nil {
+        fatal("Failed to configure blob storage", err)
+    }
+
+    // Réplicas de leitura para as listagens e buscas
+    replicas, err := database.OpenReplicas(context.Background(), cfg.Database)
+    if err != nil {
+        fatal("Failed to connect to read replica", err)
+    }
+    for i, replica := range replicas {
+        metrics.RegisterDB(replica.DB, fmt.Sprintf("%s_replica%d", cfg.Database.DBName, i+1))
+    }
+
+    var (
+        bookRepo        repository.BookRepository
+        userRepo        repository.UserRepository
+        idempotencyRepo repository.IdempotencyRepository
+        txManager       repository.TxManager
+    )
+    switch db.DriverName() {
+    case "sqlite":
+        bookRepo = sqlite.NewBookRepository(db)
+        userRepo = sqlite.NewUserRepository(db)
+        idempotencyRepo = sqlite.NewIdempotencyRepository(db)
+        txManager = sqlite.NewTxManager(db)
+    default:
+        bookRepo = postgres.NewBookRepository(db, replicas...)
+        userRepo = postgres.NewUserRepository(db, replicas...)
+        idempotencyRepo = postgres.NewIdempotencyRepository(db)
+        txManager = postgres.NewTxManager(db)
+    }
+
+    // Cache das consultas mais frequentes de livros. Sem o Redis as consultas
+    // vão direto ao banco, então ele não entra no /readyz.
+    var redisCache *cache.Redis
+    switch cfg.Cache.Backend {
+    case "memory":
+        bookRepo = cache.NewBookRepository(bookRepo, cache.NewLRU(cfg.Cache.Size), cfg.Cache.TTL)
+    case "redis":
+        redisCache = cache.NewRedis(cfg.Cache.Redis.Addr, cfg.Cache.Redis.Password, cfg.Cache.Redis.DB)
+        bookRepo = cache.NewBookRepository(bookRepo, redisCache, cfg.Cache.TTL)
+    }
     
-    bookService := usecase.NewBookService(bookRepo)
+    coverWorker := usecase.NewCoverWorker(1000, cfg.Storage.CoverSweepInterval)
+    
+    bookService := usecase.NewBookService(bookRepo, txManager, coverWorker)
     userService := usecase.NewUserService(userRepo)
+    idempotencyService := usecase.NewIdempotencyService(idempotencyRepo, cfg.Idempotency.TTL, cfg.Idempotency.LockTimeout)
+    metadataService := usecase.NewMetadataService(metadataProvider)
+    coverService := usecase.NewCoverService(bookRepo, blobStore, imaging.NewFetcher(cfg.Storage.CoverFetchTimeout),
+        coverWorker, cfg.Storage.MaxCoverSize, cfg.Server.PublicURL+"/api/covers")
+    
+    // Workers em segundo plano, parados no desligamento depois do servidor HTTP
+    workerCtx, stopWorkers := context.WithCancel(context.Background())
+    var workers sync.WaitGroup
+
+    // Versões redimensionadas das capas
+    workers.Add(1)
+    go func() {
+        defer workers.Done()
+        coverWorker.Run(workerCtx, coverService)
+    }()
     
     bookHandler := handler.NewBookHandler(bookService)
     userHandler := handler.NewUserHandler(userService)
+    metadataHandler := handler.NewMetadataHandler(metadataService)
+    coverHandler := handler.NewCoverHandler(coverService)
+    idempotency := handler.IdempotencyMiddleware(idempotencyService)
 
-    healthHandler := handler.NewHealthHandler(db)
+    // Dependências verificadas por /readyz
+    readiness := health.NewRegistry()
+    readiness.Register("database", cfg.Health.CheckTimeout, db.PingContext)
+    for i, replica := range replicas {
+        readiness.Register(fmt.Sprintf("database_replica%d", i+1), cfg.Health.CheckTimeout, replica.PingContext)
+    }
+    readiness.Register("migrations", cfg.Health.CheckTimeout, migrator.Check)
+    readiness.Register("storage", cfg.Health.CheckTimeout, func(ctx context.Context) error {
+        return storage.Check(ctx, blobStore)
+    })
+    healthHandler := handler.NewHealthHandler(readiness, cfg.Health.Token)
     
-    router := gin.Default()
+    // Sem GIN_MODE, o gin não imprime mensagens de depuração em texto no meio dos logs
+    if os.Getenv(gin.EnvGinMode) == "" {
+        gin.SetMode(gin.ReleaseMode)
+    }
+    router := gin.New()
     
+    // O span da requisição vem primeiro para que os logs levem o trace_id
+    router.Use(otelgin.Middleware(cfg.Tracing.ServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+        switch r.URL.Path {
+        case "/metrics", "/healthz", "/readyz":
+            return false
+        }
+        return true
+    })))
+    router.Use(handler.RequestIDMiddleware(logger), handler.AccessLogMiddleware(), handler.MetricsMiddleware(), handler.RecoveryMiddleware())
     router.Use(handler.CORSMiddleware())
+    if cfg.Server.Compression {
+        router.Use(handler.CompressionMiddleware(cfg.Server.CompressionMinSize))
+    }
     
     router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+    router.GET("/metrics", gin.WrapH(metrics.Handler()))
+    healthHandler.RegisterRoutes(router)
     
     api := router.Group("/api")
     {
-        bookHandler.RegisterRoutes(api)
-        userHandler.RegisterRoutes(api)
-        healthHandler.RegisterRoutes(api)
+        bookHandler.RegisterRoutes(api, idempotency)
+        userHandler.RegisterRoutes(api, idempotency)
+        metadataHandler.RegisterRoutes(api)
+        coverHandler.RegisterRoutes(api)
     }
     
-    log.Printf("Starting server on %s", cfg.Server.Address)
-    if err := router.Run(cfg.Server.Address); err != nil {
-        log.Fatalf("Failed to start server: %v", err)
+    server, err := httpserver.New(router, cfg.Server)
+    if err != nil {
+        fatal("Failed to configure server", err)
+    }
+
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+
+    serveErr := make(chan error, 1)
+    go func() {
+        slog.Info("Starting server", "address", cfg.Server.Address, "tls", server.TLS())
+        serveErr <- server.ListenAndServe()
+    }()
+
+    var failure error
+    select {
+    case failure = <-serveErr:
+    case <-ctx.Done():
+        slog.Info("Shutting down, waiting for in-flight requests", "timeout", cfg.Server.ShutdownTimeout.String())
+    }
+    // Daqui em diante um segundo sinal encerra o processo imediatamente
+    stop()
+
+    shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+    defer cancel()
+
+    if err := server.Shutdown(shutdownCtx); err != nil {
+        slog.Error("Failed to drain connections", "error", err)
+    }
+
+    stopWorkers()
+    workers.Wait()
+
+    if err := db.Close(); err != nil {
+        slog.Error("Failed to close database", "error", err)
+    }
+    for _, replica := range replicas {
+        if err := replica.Close(); err != nil {
+            slog.Error("Failed to close read replica", "error", err)
+        }
+    }
+    if redisCache != nil {
+        if err := redisCache.Close(); err != nil {
+            slog.Error("Failed to close cache", "error", err)
+        }
+    }
+    if failure != nil {
+        fatal("Failed to start server", failure)
+    }
+    slog.Info("Server stopped")
+}
+
+// fatal registra o erro e encerra o processo
+func fatal(msg string, err error) {
+    slog.Error(msg, "error", err)
+    os.Exit(1)
+}
+
+// newMetadataProvider monta os catálogos externos na ordem configurada
+func newMetadataProvider(cfg config.MetadataConfig) (metadata.MetadataProvider, error) {
+    client := &http.Client{Timeout: cfg.Timeout}
+
+    var providers []metadata.MetadataProvider
+    for _, name := range cfg.Providers {
+        switch name {
+        case openlibrary.Name:
+            providers = append(providers, openlibrary.NewProvider(client, ""))
+        case googlebooks.Name:
+            providers = append(providers, googlebooks.NewProvider(client, "", cfg.GoogleBooksAPIKey))
+        case fixture.Name:
+            providers = append(providers, fixture.NewProvider(cfg.FixturesDir))
+        default:
+            return nil, fmt.Errorf("unknown metadata provider %q", name)
+        }
+    }
+
+    return metadata.New(metadata.Options{
+        Timeout:          cfg.Timeout,
+        CacheTTL:         cfg.CacheTTL,
+        CacheSize:        10000,
+        FailureThreshold: 5,
+        Cooldown:         30 * time.Second,
+    }, providers...), nil
+}
+
+// newBlobStore cria o armazenamento dos arquivos enviados (capas)
+func newBlobStore(cfg config.StorageConfig) (storage.BlobStore, error) {
+    switch cfg.Backend {
+    case "local":
+        return local.NewBlobStore(cfg.Dir)
+    case "s3":
+        ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+        defer cancel()
+        return s3.NewBlobStore(ctx, s3.Config{
+            Endpoint:  cfg.S3.Endpoint,
+            AccessKey: cfg.S3.AccessKey,
+            SecretKey: cfg.S3.SecretKey,
+            Bucket:    cfg.S3.Bucket,
+            Region:    cfg.S3.Region,
+            UseSSL:    cfg.S3.UseSSL,
+        })
+    default:
+        return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+    }
+}
+
+// runMigrate executa os subcomandos de migração do banco
+func runMigrate(db *sqlx.DB, args []string) error {
+    migrator, err := migrate.New(db, migrations.For(db.DriverName()))
+    if err != nil {
+        return err
+    }
+
+    result, err := migrator.Run(context.Background(), args)
+    if errors.Is(err, migrate.ErrUsage) {
+        return fmt.Errorf("%w (usage: migrate %s)", err, migrate.Usage)
+    }
+    if err != nil {
+        return err
+    }
+
+    switch result.Command {
+    case "up":
+        slog.Info("Migrations applied", "count", result.Applied)
+    case "down":
+        slog.Info("Migrations reverted", "count", result.Reverted)
+    case "status":
+        w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+        migrate.WriteStatus(w, result.Statuses)
+        return w.Flush()
     }
-}
\ No newline at end of file
+    return nil
+}
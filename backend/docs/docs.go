@@ -51,6 +51,12 @@ const docTemplate = `{
                         "description": "Items per page",
                         "name": "page_size",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -61,12 +67,29 @@ const docTemplate = `{
                             "items": {
                                 "$ref": "#/definitions/domain.Book"
                             }
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "Caching policy: the page must be revalidated"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the page"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time of the latest update among the books in the page"
+                            }
                         }
                     },
+                    "304": {
+                        "description": "Not modified"
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.Problem"
                         }
                     }
                 }
@@ -84,6 +107,12 @@ const docTemplate = `{
                 ],
                 "summary": "Create a book",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
                     {
                         "description": "Book information",
                         "name": "book",
@@ -99,26 +128,44 @@ const docTemplate = `{
                         "description": "Created",
                         "schema": {
                             "$ref": "#/definitions/domain.Book"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current version of the book"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.Problem"
                         }
                     }
                 }
             }
         },
-        "/books/{id}": {
-            "get": {
-                "description": "Get a book by its ID",
+        "/books/batch": {
+            "post": {
+                "description": "Apply up to 1000 operations. In atomic mode (default) all operations run in one transaction and any failure rolls back the whole batch. In partial mode each operation is applied on its own and the response is 207 Multi-Status with one result per operation.",
                 "consumes": [
                     "application/json"
                 ],
@@ -128,98 +175,133 @@ const docTemplate = `{
                 "tags": [
                     "books"
                 ],
-                "summary": "Get a book",
+                "summary": "Create, update and delete books in bulk",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Book ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Batch operations",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.BookBatchRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Atomic batch applied",
                         "schema": {
-                            "$ref": "#/definitions/domain.Book"
+                            "$ref": "#/definitions/dto.BookBatchResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Partial batch applied, see each result",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BookBatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BookBatchResponse"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/dto.BookBatchResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BookBatchResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.Problem"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update an existing book by ID",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/books/export": {
+            "get": {
+                "description": "Stream the catalog as a file. Accepts the same pagination as the book listing; without page the whole catalog is exported.",
                 "produces": [
-                    "application/json"
+                    "text/csv",
+                    "application/marc",
+                    "application/marcxml+xml"
                 ],
                 "tags": [
                     "books"
                 ],
-                "summary": "Update a book",
+                "summary": "Export books",
                 "parameters": [
                     {
+                        "enum": [
+                            "csv",
+                            "marc21",
+                            "marcxml"
+                        ],
                         "type": "string",
-                        "description": "Book ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "default": "csv",
+                        "description": "File format",
+                        "name": "format",
+                        "in": "query"
                     },
                     {
-                        "description": "Book information",
-                        "name": "book",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.Book"
-                        }
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Items per page",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Book"
+                            "type": "file"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.Problem"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Remove a book by ID",
+            }
+        },
+        "/books/import": {
+            "post": {
+                "description": "Import books from a file sent as the request body or as the \"file\" field of a multipart form. Supported formats are CSV, MARC 21 (ISO 2709, UTF-8) and MARCXML. For CSV the header row is matched against known column names (title/titulo, author/autor, isbn, description/descricao, cover_url/capa, status, publisher/editora, publication_year/ano, subjects/assuntos); use map[\u003ccolumn\u003e]=\u003cfield\u003e to map other columns. MARC records are read from fields 020, 100, 245, 260/264, 520 and 650. Invalid records and ISBNs already in the file or in the catalog are skipped and reported. Books are saved in batches of 500, so if the import fails midway, the batches already saved are kept and the error response carries the partial report. With dry_run=true nothing is saved.",
                 "consumes": [
-                    "application/json"
+                    "text/csv",
+                    "application/marc",
+                    "application/marcxml+xml",
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
@@ -227,92 +309,117 @@ const docTemplate = `{
                 "tags": [
                     "books"
                 ],
-                "summary": "Delete a book",
+                "summary": "Import books",
                 "parameters": [
                     {
+                        "enum": [
+                            "csv",
+                            "marc21",
+                            "marcxml"
+                        ],
                         "type": "string",
-                        "description": "Book ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "default": "csv",
+                        "description": "File format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the file",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "object",
+                        "description": "Column to field mapping, e.g. map[Nome]=title",
+                        "name": "map",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "File to import (multipart)",
+                        "name": "file",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/usecase.ImportReport"
+                        }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ImportProblem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.ImportProblem"
                         }
                     }
                 }
             }
         },
-        "/login": {
-            "post": {
-                "description": "Authenticate a user with email and password",
-                "consumes": [
-                    "application/json"
-                ],
+        "/books/isbn/{isbn}": {
+            "get": {
+                "description": "Find the books with an ISBN, given as ISBN-10 or ISBN-13, with or without hyphens. Books are found regardless of the format their ISBN was entered in.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "books"
                 ],
-                "summary": "Login user",
+                "summary": "Find books by ISBN",
                 "parameters": [
                     {
-                        "description": "Login credentials",
-                        "name": "credentials",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.UserLoginRequest"
-                        }
+                        "type": "string",
+                        "description": "ISBN-10 or ISBN-13",
+                        "name": "isbn",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "properties": {
-                                "token": {
-                                    "type": "string"
-                                },
-                                "user": {
-                                    "$ref": "#/definitions/dto.UserResponse"
-                                }
-                            }
+                            "$ref": "#/definitions/dto.ISBNLookupResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.Problem"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.Problem"
                         }
                     }
                 }
             }
         },
-        "/register": {
+        "/books/metadata": {
             "post": {
-                "description": "Create a new user account with email and password",
+                "description": "Look up the book's ISBN in the configured catalogs (Open Library, Google Books) and merge the result into the book sent in the body. Only the ISBN is required. By default only empty fields are filled; with overwrite=true the catalog data replaces the sent values. Nothing is saved.",
                 "consumes": [
                     "application/json"
                 ],
@@ -320,50 +427,63 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "books"
                 ],
-                "summary": "Register a new user",
+                "summary": "Fill in book metadata from external catalogs",
                 "parameters": [
                     {
-                        "description": "User registration data",
-                        "name": "registration",
+                        "type": "boolean",
+                        "description": "Replace fields that already have a value",
+                        "name": "overwrite",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Book with at least the ISBN",
+                        "name": "book",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UserRegistrationRequest"
+                            "$ref": "#/definitions/domain.Book"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "properties": {
-                                "user": {
-                                    "$ref": "#/definitions/dto.UserResponse"
-                                }
-                            }
+                            "$ref": "#/definitions/dto.BookMetadataResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
                         }
                     }
                 }
             }
         },
-        "/users": {
+        "/books/{id}": {
             "get": {
-                "description": "Get a paginated list of all users",
+                "description": "Get a book by its ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -371,45 +491,70 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "books"
                 ],
-                "summary": "List users",
+                "summary": "Get a book",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
+                        "type": "string",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Items per page",
-                        "name": "page_size",
-                        "in": "query"
+                        "type": "string",
+                        "description": "ETag from a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified from a previous response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.User"
+                            "$ref": "#/definitions/domain.Book"
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "Caching policy: the book must be revalidated"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current version of the book"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time of the last update to the book"
                             }
                         }
                     },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.Problem"
                         }
                     }
                 }
             },
-            "post": {
-                "description": "Add a new user to the database",
+            "put": {
+                "description": "Update an existing book by ID. Empty cover_url, status, publisher and publication_year and absent subjects keep the current values; use PATCH to clear them.",
                 "consumes": [
                     "application/json"
                 ],
@@ -417,37 +562,611 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "books"
                 ],
-                "summary": "Create a user",
+                "summary": "Update a book",
                 "parameters": [
                     {
-                        "description": "User information",
-                        "name": "user",
+                        "type": "string",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being replaced",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Book information",
+                        "name": "book",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.User"
+                            "$ref": "#/definitions/domain.Book"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Book"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current version of the book"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a book by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Delete a book",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being deleted",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Partially update a book using JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902). Absent fields are kept and null clears a field.",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Patch a book",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being patched",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Patch document",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.BookPatchDocument"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Book"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current version of the book"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/cover": {
+            "put": {
+                "description": "Store a cover image for the book and point its cover_url to it. The image is sent as the request body or as the \"file\" field of a multipart form; its type is detected from the content and must be JPEG, PNG, GIF or WebP. A previously uploaded cover is removed.",
+                "consumes": [
+                    "image/jpeg",
+                    "image/png",
+                    "image/gif",
+                    "image/webp",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Upload a book cover",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being updated",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Cover image (multipart)",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Book"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/covers/{name}": {
+            "get": {
+                "description": "Serve a cover image stored by the upload endpoint. Covers never change once stored, so responses can be cached indefinitely.",
+                "produces": [
+                    "image/jpeg",
+                    "image/png",
+                    "image/gif",
+                    "image/webp"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Get an uploaded cover",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cover file name, as in the book's cover_url",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Authenticate a user with email and password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Login user",
+                "parameters": [
+                    {
+                        "description": "Login credentials",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "token": {
+                                    "type": "string"
+                                },
+                                "user": {
+                                    "$ref": "#/definitions/dto.UserResponse"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Create a new user account with email and password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "User registration data",
+                        "name": "registration",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserRegistrationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "user": {
+                                    "$ref": "#/definitions/dto.UserResponse"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "Get a paginated list of all users",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Items per page",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.User"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a new user to the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "User information",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "$ref": "#/definitions/domain.User"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current version of the user"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.Problem"
                         }
                     }
                 }
@@ -473,6 +1192,12 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -480,18 +1205,27 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/domain.User"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current version of the user"
+                            }
                         }
                     },
+                    "304": {
+                        "description": "Not modified"
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.Problem"
                         }
                     }
                 }
@@ -516,6 +1250,13 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being replaced",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
                     {
                         "description": "User information",
                         "name": "user",
@@ -531,24 +1272,48 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/domain.User"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current version of the user"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.Problem"
                         }
                     }
                 }
@@ -572,22 +1337,136 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being deleted",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "204": {
                         "description": "No Content"
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Partially update a user using JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902). Absent fields are kept and null clears a field.",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Patch a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being patched",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Patch document",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserPatchDocument"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current version of the user"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.Problem"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/handler.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.ErrorResponse"
+                            "$ref": "#/definitions/handler.Problem"
                         }
                     }
                 }
@@ -613,6 +1492,14 @@ const docTemplate = `{
                     "type": "string",
                     "example": "https://example.com/cover.jpg"
                 },
+                "covers": {
+                    "description": "Versões redimensionadas da capa; null enquanto não forem geradas",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.CoverVariants"
+                        }
+                    ]
+                },
                 "created_at": {
                     "description": "Data de criação do registro",
                     "type": "string"
@@ -632,6 +1519,16 @@ const docTemplate = `{
                     "type": "string",
                     "example": "9788533615120"
                 },
+                "publication_year": {
+                    "description": "Ano de publicação (0 quando desconhecido)",
+                    "type": "integer",
+                    "example": 2000
+                },
+                "publisher": {
+                    "description": "Editora",
+                    "type": "string",
+                    "example": "Martins Fontes"
+                },
                 "status": {
                     "description": "Status do livro (available, borrowed, lost)",
                     "type": "string",
@@ -642,6 +1539,17 @@ const docTemplate = `{
                     ],
                     "example": "available"
                 },
+                "subjects": {
+                    "description": "Assuntos do livro",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Fantasia",
+                        "Literatura inglesa"
+                    ]
+                },
                 "title": {
                     "description": "Título do livro",
                     "type": "string",
@@ -650,6 +1558,110 @@ const docTemplate = `{
                 "updated_at": {
                     "description": "Data de atualização do registro",
                     "type": "string"
+                },
+                "version": {
+                    "description": "Versão do registro, usada para controle de concorrência otimista (ETag)",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "domain.BookMetadata": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "J.R.R. Tolkien"
+                },
+                "cover_url": {
+                    "type": "string",
+                    "example": "https://covers.openlibrary.org/b/id/123-L.jpg"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Uma história épica de fantasia..."
+                },
+                "isbn": {
+                    "type": "string",
+                    "example": "9788533615120"
+                },
+                "publication_year": {
+                    "type": "integer",
+                    "example": 2000
+                },
+                "publisher": {
+                    "type": "string",
+                    "example": "Martins Fontes"
+                },
+                "source": {
+                    "description": "Catálogo de origem dos dados",
+                    "type": "string",
+                    "example": "openlibrary"
+                },
+                "subjects": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Fantasia"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "example": "O Senhor dos Anéis"
+                }
+            }
+        },
+        "domain.CoverVariants": {
+            "type": "object",
+            "properties": {
+                "large": {
+                    "description": "Versão grande (até 800x1200)",
+                    "type": "string",
+                    "example": "http://localhost:8080/api/covers/e0c7f36a-9c5e-4c7d-b0a1-596b344f3a0b-1f2e3d4c5b6a7980-large.jpg"
+                },
+                "medium": {
+                    "description": "Versão média (até 400x600)",
+                    "type": "string",
+                    "example": "http://localhost:8080/api/covers/e0c7f36a-9c5e-4c7d-b0a1-596b344f3a0b-1f2e3d4c5b6a7980-medium.jpg"
+                },
+                "thumbnail": {
+                    "description": "Miniatura para listagens (até 200x300)",
+                    "type": "string",
+                    "example": "http://localhost:8080/api/covers/e0c7f36a-9c5e-4c7d-b0a1-596b344f3a0b-1f2e3d4c5b6a7980-thumbnail.jpg"
+                }
+            }
+        },
+        "domain.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "title"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "title is required"
+                }
+            }
+        },
+        "domain.RecordError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "Campo com problema, quando conhecido",
+                    "type": "string",
+                    "example": "isbn"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "duplicate ISBN"
+                },
+                "position": {
+                    "description": "Posição do registro na origem (linha do CSV, índice do registro MARC)",
+                    "type": "integer",
+                    "example": 3
                 }
             }
         },
@@ -665,6 +1677,11 @@ const docTemplate = `{
                     "description": "Data de criação do registro",
                     "type": "string"
                 },
+                "disabled": {
+                    "description": "Usuários desativados não conseguem se autenticar",
+                    "type": "boolean",
+                    "example": false
+                },
                 "email": {
                     "description": "Email do usuário (único)",
                     "type": "string",
@@ -683,6 +1700,215 @@ const docTemplate = `{
                 "updated_at": {
                     "description": "Data de atualização do registro",
                     "type": "string"
+                },
+                "version": {
+                    "description": "Versão do registro, usada para controle de concorrência otimista (ETag)",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "dto.BookBatchItemResult": {
+            "type": "object",
+            "properties": {
+                "book": {
+                    "$ref": "#/definitions/domain.Book"
+                },
+                "code": {
+                    "type": "string",
+                    "example": "BOOK_NOT_FOUND"
+                },
+                "error": {
+                    "description": "Erro da operação que falhou, com o código e os campos inválidos",
+                    "type": "string",
+                    "example": "book not found"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FieldError"
+                    }
+                },
+                "id": {
+                    "type": "string",
+                    "example": "e0c7f36a-9c5e-4c7d-b0a1-596b344f3a0b"
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "op": {
+                    "type": "string",
+                    "example": "create"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 201
+                }
+            }
+        },
+        "dto.BookBatchOperation": {
+            "type": "object",
+            "properties": {
+                "book": {
+                    "$ref": "#/definitions/domain.Book"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "e0c7f36a-9c5e-4c7d-b0a1-596b344f3a0b"
+                },
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ],
+                    "example": "create"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "dto.BookBatchRequest": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "description": "atomic: tudo ou nada em uma transação; partial: cada operação é independente",
+                    "type": "string",
+                    "enum": [
+                        "atomic",
+                        "partial"
+                    ],
+                    "example": "atomic"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.BookBatchOperation"
+                    }
+                }
+            }
+        },
+        "dto.BookBatchResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "mode": {
+                    "type": "string",
+                    "example": "atomic"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.BookBatchItemResult"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "dto.BookMetadataResponse": {
+            "type": "object",
+            "properties": {
+                "book": {
+                    "$ref": "#/definitions/domain.Book"
+                },
+                "changed": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "title",
+                        "author",
+                        "cover_url"
+                    ]
+                },
+                "metadata": {
+                    "$ref": "#/definitions/domain.BookMetadata"
+                }
+            }
+        },
+        "dto.BookPatchDocument": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "J.R.R. Tolkien"
+                },
+                "cover_url": {
+                    "type": "string",
+                    "example": "https://example.com/cover.jpg"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Uma história épica de fantasia..."
+                },
+                "isbn": {
+                    "type": "string",
+                    "example": "9788533615120"
+                },
+                "publication_year": {
+                    "type": "integer",
+                    "example": 2000
+                },
+                "publisher": {
+                    "type": "string",
+                    "example": "Martins Fontes"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "available",
+                        "borrowed",
+                        "lost"
+                    ],
+                    "example": "available"
+                },
+                "subjects": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Fantasia",
+                        "Literatura inglesa"
+                    ]
+                },
+                "title": {
+                    "type": "string",
+                    "example": "O Senhor dos Anéis"
+                }
+            }
+        },
+        "dto.ISBNLookupResponse": {
+            "type": "object",
+            "properties": {
+                "books": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Book"
+                    }
+                },
+                "hyphenated": {
+                    "type": "string",
+                    "example": "978-85-336-1512-0"
+                },
+                "isbn10": {
+                    "type": "string",
+                    "example": "8533615124"
+                },
+                "isbn13": {
+                    "type": "string",
+                    "example": "9788533615120"
                 }
             }
         },
@@ -703,6 +1929,23 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.UserPatchDocument": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "joao.silva@example.com"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "João Silva"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "novaSenha123"
+                }
+            }
+        },
         "dto.UserRegistrationRequest": {
             "type": "object",
             "required": [
@@ -749,11 +1992,129 @@ const docTemplate = `{
                 }
             }
         },
-        "handler.ErrorResponse": {
+        "handler.ImportProblem": {
             "type": "object",
             "properties": {
-                "error": {
-                    "type": "string"
+                "code": {
+                    "description": "Código estável do erro",
+                    "type": "string",
+                    "example": "BOOK_NOT_FOUND"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "book not found"
+                },
+                "errors": {
+                    "description": "Problemas de cada campo, nos erros de validação",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/api/books/0b7e5a2c-3f4d-4c59-9a8e-2f1d6c0e7b11"
+                },
+                "report": {
+                    "$ref": "#/definitions/usecase.ImportReport"
+                },
+                "request_id": {
+                    "description": "ID da requisição, o mesmo do cabeçalho X-Request-ID e dos logs",
+                    "type": "string",
+                    "example": "5f0c6a8e-1d2b-4c3a-9e8f-7a6b5c4d3e2f"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "about:blank"
+                }
+            }
+        },
+        "handler.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Código estável do erro",
+                    "type": "string",
+                    "example": "BOOK_NOT_FOUND"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "book not found"
+                },
+                "errors": {
+                    "description": "Problemas de cada campo, nos erros de validação",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/api/books/0b7e5a2c-3f4d-4c59-9a8e-2f1d6c0e7b11"
+                },
+                "request_id": {
+                    "description": "ID da requisição, o mesmo do cabeçalho X-Request-ID e dos logs",
+                    "type": "string",
+                    "example": "5f0c6a8e-1d2b-4c3a-9e8f-7a6b5c4d3e2f"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "about:blank"
+                }
+            }
+        },
+        "usecase.ImportReport": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "duplicates": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.RecordError"
+                    }
+                },
+                "errors_truncated": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "imported": {
+                    "type": "integer",
+                    "example": 298
+                },
+                "invalid": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 300
+                },
+                "valid": {
+                    "type": "integer",
+                    "example": 298
                 }
             }
         }
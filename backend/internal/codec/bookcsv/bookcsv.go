@@ -0,0 +1,242 @@
+// Package bookcsv lê e escreve o catálogo de livros em CSV
+package bookcsv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+// Campos de domain.Book que podem ser mapeados a partir de uma coluna
+const (
+	FieldTitle       = "title"
+	FieldAuthor      = "author"
+	FieldISBN        = "isbn"
+	FieldDescription = "description"
+	FieldCoverURL    = "cover_url"
+	FieldStatus      = "status"
+	FieldPublisher   = "publisher"
+	FieldYear        = "publication_year"
+	FieldSubjects    = "subjects"
+)
+
+// Fields lista os campos na ordem usada pela exportação
+var Fields = []string{FieldTitle, FieldAuthor, FieldISBN, FieldDescription, FieldCoverURL, FieldStatus,
+	FieldPublisher, FieldYear, FieldSubjects}
+
+// Separador dos assuntos dentro da coluna subjects
+const subjectSeparator = ";"
+
+// Nomes de coluna reconhecidos automaticamente, em minúsculas
+var aliases = map[string]string{
+	"title":            FieldTitle,
+	"titulo":           FieldTitle,
+	"título":           FieldTitle,
+	"author":           FieldAuthor,
+	"autor":            FieldAuthor,
+	"isbn":             FieldISBN,
+	"isbn13":           FieldISBN,
+	"isbn-13":          FieldISBN,
+	"description":      FieldDescription,
+	"descricao":        FieldDescription,
+	"descrição":        FieldDescription,
+	"cover_url":        FieldCoverURL,
+	"cover":            FieldCoverURL,
+	"capa":             FieldCoverURL,
+	"status":           FieldStatus,
+	"situacao":         FieldStatus,
+	"situação":         FieldStatus,
+	"publisher":        FieldPublisher,
+	"editora":          FieldPublisher,
+	"publication_year": FieldYear,
+	"year":             FieldYear,
+	"ano":              FieldYear,
+	"subjects":         FieldSubjects,
+	"assuntos":         FieldSubjects,
+}
+
+// Reader lê livros de um CSV cuja primeira linha é o cabeçalho
+type Reader struct {
+	csv     *csv.Reader
+	columns map[string]int
+}
+
+// NewReader lê o cabeçalho e resolve as colunas. mapping associa nomes de
+// coluna do arquivo a campos (ex.: "Nome do livro" -> "title") e tem
+// prioridade sobre os nomes reconhecidos automaticamente. Colunas sem
+// correspondência são ignoradas.
+func NewReader(r io.Reader, mapping map[string]string) (*Reader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, domain.NewValidationError("file", "empty CSV file")
+		}
+		return nil, domain.NewValidationError("header", fmt.Sprintf("invalid CSV header: %v", err))
+	}
+
+	custom := make(map[string]string, len(mapping))
+	for column, field := range mapping {
+		if !isField(field) {
+			return nil, domain.NewValidationError("mapping", fmt.Sprintf("unknown field %q in mapping", field))
+		}
+		custom[normalize(column)] = field
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = normalize(strings.TrimPrefix(name, "\ufeff"))
+		field, ok := custom[name]
+		if !ok {
+			field, ok = aliases[name]
+		}
+		if !ok {
+			continue
+		}
+		if _, duplicated := columns[field]; duplicated {
+			return nil, domain.NewValidationError("header", fmt.Sprintf("more than one column mapped to %q", field))
+		}
+		columns[field] = i
+	}
+
+	for _, required := range []string{FieldTitle, FieldAuthor} {
+		if _, ok := columns[required]; !ok {
+			return nil, domain.NewValidationError("header", fmt.Sprintf("CSV header has no column for %q", required))
+		}
+	}
+
+	return &Reader{csv: reader, columns: columns}, nil
+}
+
+// Next devolve a linha e o livro do próximo registro. Linhas malformadas são
+// devolvidas como *domain.RecordError e a leitura pode continuar; io.EOF
+// indica o fim do arquivo.
+func (r *Reader) Next() (int, *domain.Book, error) {
+	for {
+		record, err := r.csv.Read()
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return parseErr.StartLine, nil, &domain.RecordError{Position: parseErr.StartLine, Message: parseErr.Err.Error()}
+			}
+			return 0, nil, err
+		}
+
+		if isBlank(record) {
+			continue
+		}
+
+		line, _ := r.csv.FieldPos(0)
+
+		book := &domain.Book{
+			Title:       r.value(record, FieldTitle),
+			Author:      r.value(record, FieldAuthor),
+			ISBN:        r.value(record, FieldISBN),
+			Description: r.value(record, FieldDescription),
+			CoverURL:    r.value(record, FieldCoverURL),
+			Status:      strings.ToLower(r.value(record, FieldStatus)),
+			Publisher:   r.value(record, FieldPublisher),
+			Subjects:    splitSubjects(r.value(record, FieldSubjects)),
+		}
+
+		if year := r.value(record, FieldYear); year != "" {
+			n, err := strconv.Atoi(year)
+			if err != nil || n < 0 {
+				return line, nil, &domain.RecordError{Position: line, Field: FieldYear, Message: fmt.Sprintf("invalid year %q", year)}
+			}
+			book.PublicationYear = n
+		}
+
+		return line, book, nil
+	}
+}
+
+func (r *Reader) value(record []string, field string) string {
+	i, ok := r.columns[field]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// Writer escreve livros em CSV com o cabeçalho de Fields
+type Writer struct {
+	csv           *csv.Writer
+	headerWritten bool
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{csv: csv.NewWriter(w)}
+}
+
+func (w *Writer) Write(book *domain.Book) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+
+	year := ""
+	if book.PublicationYear > 0 {
+		year = strconv.Itoa(book.PublicationYear)
+	}
+
+	return w.csv.Write([]string{book.Title, book.Author, book.ISBN, book.Description, book.CoverURL, book.Status,
+		book.Publisher, year, strings.Join(book.Subjects, subjectSeparator+" ")})
+}
+
+// Flush grava os dados pendentes; um catálogo vazio gera apenas o cabeçalho
+func (w *Writer) Flush() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+func (w *Writer) writeHeader() error {
+	if w.headerWritten {
+		return nil
+	}
+	w.headerWritten = true
+	return w.csv.Write(Fields)
+}
+
+func isField(field string) bool {
+	for _, f := range Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func splitSubjects(value string) domain.StringList {
+	subjects := domain.StringList{}
+	for _, subject := range strings.Split(value, subjectSeparator) {
+		if subject = strings.TrimSpace(subject); subject != "" {
+			subjects = append(subjects, subject)
+		}
+	}
+	return subjects
+}
+
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
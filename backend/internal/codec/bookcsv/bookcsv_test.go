@@ -0,0 +1,201 @@
+package bookcsv
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+// record é um registro lido: a linha e o livro ou o erro
+type record struct {
+	line int
+	book *domain.Book
+	err  *domain.RecordError
+}
+
+func readAll(t *testing.T, reader *Reader) []record {
+	t.Helper()
+
+	var records []record
+	for {
+		line, book, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return records
+		}
+		var recordErr *domain.RecordError
+		if err != nil && !errors.As(err, &recordErr) {
+			t.Fatalf("Next() error = %v", err)
+		}
+		records = append(records, record{line: line, book: book, err: recordErr})
+	}
+}
+
+func TestReaderHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		mapping map[string]string
+		want    *domain.Book
+	}{
+		{
+			name: "english",
+			csv:  "title,author,isbn,description,cover_url,status,publisher,publication_year,subjects\nLivro,Autor,9788533613379,Descrição,http://capa,AVAILABLE,Editora,2000,Fantasia\n",
+			want: &domain.Book{Title: "Livro", Author: "Autor", ISBN: "9788533613379", Description: "Descrição", CoverURL: "http://capa",
+				Status: "available", Publisher: "Editora", PublicationYear: 2000, Subjects: domain.StringList{"Fantasia"}},
+		},
+		{
+			name: "portuguese with BOM and spaces",
+			csv:  "\ufeff Título , AUTOR,Descrição,Capa,Situação,Editora,Ano,Assuntos\nLivro,Autor,Descrição,http://capa,borrowed,Editora,2000,Fantasia\n",
+			want: &domain.Book{Title: "Livro", Author: "Autor", Description: "Descrição", CoverURL: "http://capa",
+				Status: "borrowed", Publisher: "Editora", PublicationYear: 2000, Subjects: domain.StringList{"Fantasia"}},
+		},
+		{
+			name: "other aliases",
+			csv:  "titulo,autor,isbn-13,descricao,cover,situacao,year\nLivro,Autor,9788533613379,Descrição,http://capa,available,2000\n",
+			want: &domain.Book{Title: "Livro", Author: "Autor", ISBN: "9788533613379", Description: "Descrição", CoverURL: "http://capa",
+				Status: "available", PublicationYear: 2000, Subjects: domain.StringList{}},
+		},
+		{
+			name:    "mapping over aliases",
+			csv:     "Nome do livro,Escritor,title,Outra\nLivro,Autor,Ignorado,x\n",
+			mapping: map[string]string{"nome do livro": FieldTitle, " ESCRITOR ": FieldAuthor, "title": FieldDescription},
+			want:    &domain.Book{Title: "Livro", Author: "Autor", Description: "Ignorado", Subjects: domain.StringList{}},
+		},
+		{
+			name: "missing trailing columns",
+			csv:  "title,author,isbn,subjects\nLivro,Autor\n",
+			want: &domain.Book{Title: "Livro", Author: "Autor", Subjects: domain.StringList{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := NewReader(strings.NewReader(tt.csv), tt.mapping)
+			if err != nil {
+				t.Fatalf("NewReader() error = %v", err)
+			}
+
+			records := readAll(t, reader)
+			if len(records) != 1 || records[0].err != nil {
+				t.Fatalf("records = %+v, want one book", records)
+			}
+			if !reflect.DeepEqual(records[0].book, tt.want) {
+				t.Errorf("book = %+v, want %+v", records[0].book, tt.want)
+			}
+		})
+	}
+}
+
+func TestReaderInvalidHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		mapping map[string]string
+		want    string
+	}{
+		{"empty file", "", nil, "empty CSV file"},
+		{"missing author", "title,isbn\n", nil, `no column for "author"`},
+		{"unknown field", "title,author\n", map[string]string{"Nome": "name"}, `unknown field "name"`},
+		{"duplicated field", "title,titulo,author\n", nil, `more than one column mapped to "title"`},
+		{"mapping duplicates alias", "title,Nome,author\n", map[string]string{"Nome": FieldTitle}, `more than one column mapped to "title"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewReader(strings.NewReader(tt.csv), tt.mapping)
+			if !errors.Is(err, domain.ErrInvalidInput) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewReader() error = %v, want ErrInvalidInput mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReaderRecords(t *testing.T) {
+	csv := strings.Join([]string{
+		"title,author,publication_year,subjects",
+		"Primeiro,Autor,2000,Fantasia; Aventura ;; ",
+		"",
+		" , ,  ",
+		"Segundo,Autor,ano,",
+		`"Terceiro,Autor`,
+	}, "\n")
+
+	reader, err := NewReader(strings.NewReader(csv), nil)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+
+	records := readAll(t, reader)
+	if len(records) != 3 {
+		t.Fatalf("records = %+v, want 3, with the blank lines skipped", records)
+	}
+
+	first := records[0]
+	if first.line != 2 || first.err != nil || !reflect.DeepEqual(first.book.Subjects, domain.StringList{"Fantasia", "Aventura"}) {
+		t.Errorf("first record = line %d %+v %v, want line 2 with two subjects", first.line, first.book, first.err)
+	}
+
+	// As linhas em branco não alteram a numeração
+	second := records[1]
+	if second.line != 5 || second.err == nil || second.err.Position != 5 || second.err.Field != FieldYear {
+		t.Errorf("second record = line %d %v, want an invalid year at line 5", second.line, second.err)
+	}
+
+	third := records[2]
+	if third.line != 6 || third.err == nil || third.err.Position != 6 {
+		t.Errorf("third record = line %d %v, want a parse error at line 6", third.line, third.err)
+	}
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	books := []*domain.Book{
+		{Title: "Livro, com vírgula", Author: "Autor", ISBN: "9788533613379", Description: "Linha 1\nLinha \"2\"",
+			CoverURL: "http://capa", Status: "available", Publisher: "Editora", PublicationYear: 2000,
+			Subjects: domain.StringList{"Fantasia", "Aventura"}},
+		{Title: "Sem ano", Author: "Autor", Status: "borrowed", Subjects: domain.StringList{}},
+	}
+
+	var buf bytes.Buffer
+	writer := NewWriter(&buf)
+	for _, book := range books {
+		if err := writer.Write(book); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	if header, _, _ := strings.Cut(buf.String(), "\n"); header != strings.Join(Fields, ",") {
+		t.Errorf("header = %q, want %q", header, strings.Join(Fields, ","))
+	}
+
+	reader, err := NewReader(&buf, nil)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	records := readAll(t, reader)
+	if len(records) != len(books) {
+		t.Fatalf("read %d records, want %d", len(records), len(books))
+	}
+	for i, record := range records {
+		if !reflect.DeepEqual(record.book, books[i]) {
+			t.Errorf("book %d = %+v, want %+v", i, record.book, books[i])
+		}
+	}
+}
+
+func TestWriterEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewWriter(&buf).Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if got, want := buf.String(), strings.Join(Fields, ",")+"\n"; got != want {
+		t.Errorf("empty export = %q, want only the header %q", got, want)
+	}
+}
@@ -0,0 +1,233 @@
+package marc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+// Delimitadores do formato ISO 2709
+const (
+	subfieldDelimiter = 0x1F
+	fieldTerminator   = 0x1E
+	recordTerminator  = 0x1D
+)
+
+const (
+	leaderLength         = 24
+	directoryEntryLength = 12
+	maxRecordLength      = 99999
+	maxFieldLength       = 9999
+)
+
+// ErrRecordTooLong indica um registro que não cabe nos limites do ISO 2709
+var ErrRecordTooLong = errors.New("record exceeds the ISO 2709 size limits")
+
+// Reader lê registros MARC 21 no formato de intercâmbio ISO 2709 (.mrc)
+type Reader struct {
+	r        *bufio.Reader
+	position int
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// ReadRecord devolve o próximo registro. Um registro malformado é devolvido
+// como *domain.RecordError e a leitura pode continuar no registro seguinte;
+// io.EOF indica o fim do arquivo.
+func (r *Reader) ReadRecord() (*Record, error) {
+	raw, err := r.r.ReadBytes(recordTerminator)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	// Alguns arquivos separam os registros com quebras de linha
+	raw = bytes.TrimLeft(raw, " \t\r\n")
+	if len(raw) == 0 {
+		return nil, io.EOF
+	}
+
+	r.position++
+	if err != nil {
+		return nil, &domain.RecordError{Position: r.position, Message: "truncated record: missing record terminator"}
+	}
+
+	record, err := parseRecord(raw[:len(raw)-1])
+	if err != nil {
+		return nil, &domain.RecordError{Position: r.position, Message: err.Error()}
+	}
+
+	return record, nil
+}
+
+// Next implementa usecase.BookReader
+func (r *Reader) Next() (int, *domain.Book, error) {
+	record, err := r.ReadRecord()
+	if err != nil {
+		return r.position, nil, err
+	}
+	return r.position, ToBook(record), nil
+}
+
+// parseRecord interpreta um registro sem o terminador final. O tamanho
+// declarado no líder é ignorado: o limite do registro é o terminador.
+func parseRecord(raw []byte) (*Record, error) {
+	if len(raw) < leaderLength {
+		return nil, errors.New("record is shorter than the leader")
+	}
+	if !utf8.Valid(raw) {
+		return nil, errors.New("record is not UTF-8 encoded (MARC-8 is not supported)")
+	}
+
+	leader := string(raw[:leaderLength])
+	base, err := strconv.Atoi(leader[12:17])
+	if err != nil || base <= leaderLength || base > len(raw) {
+		return nil, fmt.Errorf("invalid base address of data %q", leader[12:17])
+	}
+
+	directory := raw[leaderLength : base-1]
+	if raw[base-1] != fieldTerminator || len(directory)%directoryEntryLength != 0 {
+		return nil, errors.New("invalid directory")
+	}
+
+	record := &Record{Leader: leader}
+	data := raw[base:]
+
+	for i := 0; i < len(directory); i += directoryEntryLength {
+		entry := string(directory[i : i+directoryEntryLength])
+		tag := entry[:3]
+		length, err1 := strconv.Atoi(entry[3:7])
+		start, err2 := strconv.Atoi(entry[7:12])
+		if err1 != nil || err2 != nil || start+length > len(data) || length == 0 {
+			return nil, fmt.Errorf("invalid directory entry for field %s", tag)
+		}
+
+		value := bytes.TrimSuffix(data[start:start+length], []byte{fieldTerminator})
+
+		if isControlTag(tag) {
+			record.ControlFields = append(record.ControlFields, ControlField{Tag: tag, Value: string(value)})
+			continue
+		}
+
+		if len(value) < 2 {
+			return nil, fmt.Errorf("field %s has no indicators", tag)
+		}
+
+		field := DataField{Tag: tag, Ind1: value[0], Ind2: value[1]}
+		for _, chunk := range bytes.Split(value[2:], []byte{subfieldDelimiter})[1:] {
+			if len(chunk) == 0 {
+				continue
+			}
+			field.Subfields = append(field.Subfields, Subfield{Code: chunk[0], Value: string(chunk[1:])})
+		}
+		record.DataFields = append(record.DataFields, field)
+	}
+
+	return record, nil
+}
+
+// Writer grava registros MARC 21 no formato ISO 2709, codificados em UTF-8
+type Writer struct {
+	w *bufio.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Write implementa usecase.BookWriter
+func (w *Writer) Write(book *domain.Book) error {
+	return w.WriteRecord(FromBook(book))
+}
+
+func (w *Writer) WriteRecord(record *Record) error {
+	var directory, data bytes.Buffer
+
+	addField := func(tag string, value []byte) error {
+		if len(tag) != 3 {
+			return fmt.Errorf("invalid tag %q", tag)
+		}
+		if len(value) > maxFieldLength || data.Len() > maxRecordLength {
+			return fmt.Errorf("%w: field %s", ErrRecordTooLong, tag)
+		}
+		fmt.Fprintf(&directory, "%s%04d%05d", tag, len(value), data.Len())
+		data.Write(value)
+		return nil
+	}
+
+	for _, field := range record.ControlFields {
+		value := append([]byte(clean(field.Value)), fieldTerminator)
+		if err := addField(field.Tag, value); err != nil {
+			return err
+		}
+	}
+
+	for _, field := range record.DataFields {
+		value := []byte{indicator(field.Ind1), indicator(field.Ind2)}
+		for _, subfield := range field.Subfields {
+			value = append(value, subfieldDelimiter, subfield.Code)
+			value = append(value, clean(subfield.Value)...)
+		}
+		value = append(value, fieldTerminator)
+		if err := addField(field.Tag, value); err != nil {
+			return err
+		}
+	}
+
+	base := leaderLength + directory.Len() + 1
+	length := base + data.Len() + 1
+	if length > maxRecordLength {
+		return ErrRecordTooLong
+	}
+
+	leader := []byte(record.Leader)
+	if len(leader) != leaderLength {
+		leader = []byte(defaultLeader)
+	}
+	copy(leader[0:5], fmt.Sprintf("%05d", length))
+	leader[9] = 'a'
+	copy(leader[10:12], "22")
+	copy(leader[12:17], fmt.Sprintf("%05d", base))
+	copy(leader[20:24], "4500")
+
+	w.w.Write(leader)
+	w.w.Write(directory.Bytes())
+	w.w.WriteByte(fieldTerminator)
+	w.w.Write(data.Bytes())
+	return w.w.WriteByte(recordTerminator)
+}
+
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+// Campos 001 a 009 são campos de controle, sem indicadores nem subcampos
+func isControlTag(tag string) bool {
+	return strings.HasPrefix(tag, "00")
+}
+
+func indicator(ind byte) byte {
+	if ind == 0 {
+		return ' '
+	}
+	return ind
+}
+
+// clean remove os delimitadores do ISO 2709 que corromperiam o registro
+func clean(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case subfieldDelimiter, fieldTerminator, recordTerminator:
+			return ' '
+		}
+		return r
+	}, value)
+}
@@ -0,0 +1,269 @@
+// Package marc lê e escreve registros bibliográficos MARC 21, tanto no formato
+// de intercâmbio ISO 2709 quanto em MARCXML, e converte os campos usados pelo
+// catálogo de e para domain.Book.
+package marc
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+// Campos MARC 21 mapeados para domain.Book
+const (
+	TagControlNumber = "001"
+	TagISBN          = "020"
+	TagMainEntry     = "100"
+	TagTitle         = "245"
+	TagPublication   = "260"
+	TagProduction    = "264"
+	TagSummary       = "520"
+	TagSubject       = "650"
+)
+
+// Separador das subdivisões de um assunto (650 $x, $y, $z e $v)
+const subjectSeparator = " -- "
+
+// Códigos das subdivisões de 650: forma ($v), geral ($x), cronológica ($y) e
+// geográfica ($z). Subdivisões de código desconhecido são gravadas como $x.
+const subdivisionCodes = "vxyz"
+
+// Record é um registro MARC: o líder, os campos de controle (00X) e os campos
+// de dados, na ordem em que aparecem
+type Record struct {
+	Leader        string
+	ControlFields []ControlField
+	DataFields    []DataField
+}
+
+type ControlField struct {
+	Tag   string
+	Value string
+}
+
+type DataField struct {
+	Tag       string
+	Ind1      byte
+	Ind2      byte
+	Subfields []Subfield
+}
+
+type Subfield struct {
+	Code  byte
+	Value string
+}
+
+// Fields devolve os campos de dados com a tag informada
+func (r *Record) Fields(tag string) []DataField {
+	var fields []DataField
+	for _, field := range r.DataFields {
+		if field.Tag == tag {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// Value devolve o primeiro subcampo com o código informado
+func (f DataField) Value(code byte) string {
+	for _, subfield := range f.Subfields {
+		if subfield.Code == code {
+			return subfield.Value
+		}
+	}
+	return ""
+}
+
+// Líder usado nos registros exportados: registro novo (n) de material textual
+// (a) monográfico (m), codificado em UTF-8 (a), com catalogação ISBD (i). O
+// tamanho do registro e o endereço base são preenchidos na gravação.
+const defaultLeader = "00000nam a2200000 i 4500"
+
+var (
+	isbnPattern = regexp.MustCompile(`^[0-9Xx-]+`)
+	yearPattern = regexp.MustCompile(`[0-9]{4}`)
+)
+
+// ToBook converte um registro MARC em livro. Apenas a primeira ocorrência de
+// 020, 100, 245, 260/264 e 520 é considerada; cada 650 vira um assunto, e os
+// códigos das suas subdivisões ficam em SubjectCodes.
+func ToBook(record *Record) *domain.Book {
+	book := &domain.Book{Subjects: domain.StringList{}}
+
+	for _, field := range record.Fields(TagISBN) {
+		// 020 $a pode trazer qualificadores, ex.: "9788533615120 (broch.)"
+		if isbn := isbnPattern.FindString(strings.TrimSpace(field.Value('a'))); isbn != "" {
+			book.ISBN = strings.ReplaceAll(isbn, "-", "")
+			break
+		}
+	}
+
+	if fields := record.Fields(TagMainEntry); len(fields) > 0 {
+		book.Author = trimPunctuation(fields[0].Value('a'))
+	}
+
+	if fields := record.Fields(TagTitle); len(fields) > 0 {
+		title := trimPunctuation(fields[0].Value('a'))
+		if subtitle := trimPunctuation(fields[0].Value('b')); subtitle != "" {
+			title += ": " + subtitle
+		}
+		book.Title = title
+	}
+
+	// 264 só descreve a publicação com o segundo indicador 1; registros mais
+	// antigos usam 260
+	publication := record.Fields(TagPublication)
+	for _, field := range record.Fields(TagProduction) {
+		if field.Ind2 == '1' {
+			publication = append(publication, field)
+		}
+	}
+	if len(publication) > 0 {
+		book.Publisher = trimPunctuation(publication[0].Value('b'))
+		if year := yearPattern.FindString(publication[0].Value('c')); year != "" {
+			book.PublicationYear, _ = strconv.Atoi(year)
+		}
+	}
+
+	if fields := record.Fields(TagSummary); len(fields) > 0 {
+		book.Description = strings.TrimSpace(fields[0].Value('a'))
+	}
+
+	book.SubjectCodes = domain.StringList{}
+	seen := make(map[string]bool)
+	for _, field := range record.Fields(TagSubject) {
+		var parts []string
+		var codes []byte
+		for _, subfield := range field.Subfields {
+			if subfield.Code != 'a' && !strings.ContainsRune(subdivisionCodes, rune(subfield.Code)) {
+				continue
+			}
+			if part := trimPunctuation(subfield.Value); part != "" {
+				parts = append(parts, part)
+				codes = append(codes, subfield.Code)
+			}
+		}
+		subject := strings.Join(parts, subjectSeparator)
+		if subject != "" && !seen[subject] {
+			seen[subject] = true
+			book.Subjects = append(book.Subjects, subject)
+			// O primeiro termo é gravado como $a
+			book.SubjectCodes = append(book.SubjectCodes, string(codes[1:]))
+		}
+	}
+
+	return book
+}
+
+// FromBook converte um livro em registro MARC. Os valores são gravados sem a
+// pontuação ISBD, que ToBook também descarta.
+func FromBook(book *domain.Book) *Record {
+	record := &Record{Leader: defaultLeader}
+
+	if book.ID != "" {
+		record.ControlFields = append(record.ControlFields, ControlField{Tag: TagControlNumber, Value: book.ID})
+	}
+
+	if book.ISBN != "" {
+		record.DataFields = append(record.DataFields, dataField(TagISBN, ' ', ' ', 'a', book.ISBN))
+	}
+
+	if book.Author != "" {
+		// Primeiro indicador: 1 para o nome invertido (sobrenome primeiro), como
+		// os importados de MARC; 0 para a ordem direta (prenome primeiro)
+		ind1 := byte('0')
+		if strings.Contains(book.Author, ",") {
+			ind1 = '1'
+		}
+		record.DataFields = append(record.DataFields, dataField(TagMainEntry, ind1, ' ', 'a', book.Author))
+	}
+
+	// Primeiro indicador: 1 quando há entrada principal de autor
+	ind1 := byte('0')
+	if book.Author != "" {
+		ind1 = '1'
+	}
+	record.DataFields = append(record.DataFields, dataField(TagTitle, ind1, '0', 'a', book.Title))
+
+	if book.Publisher != "" || book.PublicationYear > 0 {
+		field := DataField{Tag: TagProduction, Ind1: ' ', Ind2: '1'}
+		if book.Publisher != "" {
+			field.Subfields = append(field.Subfields, Subfield{Code: 'b', Value: book.Publisher})
+		}
+		if book.PublicationYear > 0 {
+			field.Subfields = append(field.Subfields, Subfield{Code: 'c', Value: strconv.Itoa(book.PublicationYear)})
+		}
+		record.DataFields = append(record.DataFields, field)
+	}
+
+	if book.Description != "" {
+		record.DataFields = append(record.DataFields, dataField(TagSummary, ' ', ' ', 'a', book.Description))
+	}
+
+	for i, subject := range book.Subjects {
+		// Segundo indicador 4: tesauro não especificado
+		parts := strings.Split(subject, subjectSeparator)
+		codes := subjectCodes(book, i, len(parts)-1)
+		field := dataField(TagSubject, ' ', '4', 'a', parts[0])
+		for j, part := range parts[1:] {
+			field.Subfields = append(field.Subfields, Subfield{Code: codes[j], Value: part})
+		}
+		record.DataFields = append(record.DataFields, field)
+	}
+
+	return record
+}
+
+// subjectCodes devolve os códigos das n subdivisões do assunto i: os guardados
+// na importação de MARC ou, sem eles, $x
+func subjectCodes(book *domain.Book, i, n int) []byte {
+	if len(book.SubjectCodes) == len(book.Subjects) && len(book.SubjectCodes[i]) == n {
+		codes := []byte(book.SubjectCodes[i])
+		valid := true
+		for _, code := range codes {
+			valid = valid && strings.IndexByte(subdivisionCodes, code) >= 0
+		}
+		if valid {
+			return codes
+		}
+	}
+
+	return []byte(strings.Repeat("x", n))
+}
+
+func dataField(tag string, ind1, ind2, code byte, value string) DataField {
+	return DataField{Tag: tag, Ind1: ind1, Ind2: ind2, Subfields: []Subfield{{Code: code, Value: value}}}
+}
+
+// trimPunctuation remove a pontuação ISBD ao final de um subcampo (" /",
+// " :", " ;", ",", "=" e o ponto final). O ponto é mantido quando encerra uma
+// inicial, como em "Tolkien, J. R. R.".
+func trimPunctuation(value string) string {
+	value = strings.TrimSpace(value)
+	for {
+		trimmed := strings.TrimRight(value, " /:;,=")
+		if strings.HasSuffix(trimmed, ".") && !endsWithInitial(trimmed) {
+			trimmed = strings.TrimSuffix(trimmed, ".")
+		}
+		trimmed = strings.TrimSpace(trimmed)
+		if trimmed == value {
+			return value
+		}
+		value = trimmed
+	}
+}
+
+// endsWithInitial indica se o texto, terminado em ponto, acaba em uma letra
+// isolada (ex.: "J. R. R." ou "Tolkien, J.")
+func endsWithInitial(value string) bool {
+	value = strings.TrimSuffix(value, ".")
+	r, size := utf8.DecodeLastRuneInString(value)
+	if size == 0 || r == '.' || r == ' ' {
+		return false
+	}
+	before := value[:len(value)-size]
+	return before == "" || strings.HasSuffix(before, " ") || strings.HasSuffix(before, ".")
+}
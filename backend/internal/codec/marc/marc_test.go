@@ -0,0 +1,343 @@
+package marc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+// Livros esperados a partir de testdata/books.mrc e testdata/books.xml, que
+// contêm os mesmos registros
+var sampleBooks = []*domain.Book{
+	{
+		Title:           "O senhor dos anéis: a sociedade do anel",
+		Author:          "Tolkien, J. R. R.",
+		ISBN:            "9788533613379",
+		Description:     "Primeiro volume da trilogia, em que Frodo parte do Condado para destruir o Um Anel.",
+		Publisher:       "Martins Fontes",
+		PublicationYear: 2000,
+		Subjects:        domain.StringList{"Fantasia", "Ficção inglesa -- História e crítica"},
+		SubjectCodes:    domain.StringList{"", "x"},
+	},
+	{
+		Title:           "Harry Potter e a pedra filosofal",
+		Author:          "Rowling, J. K.",
+		ISBN:            "8532511015",
+		Description:     "Harry descobre, aos onze anos, que é um bruxo & vai estudar em Hogwarts.",
+		Publisher:       "Rocco",
+		PublicationYear: 2017,
+		Subjects:        domain.StringList{"Magia -- Ficção juvenil"},
+		SubjectCodes:    domain.StringList{"v"},
+	},
+	{
+		Title:           "Dom Casmurro",
+		Author:          "Assis, Machado de",
+		ISBN:            "9788535910681",
+		Publisher:       "Penguin-Companhia das Letras",
+		PublicationYear: 2016,
+		Subjects:        domain.StringList{},
+		SubjectCodes:    domain.StringList{},
+	},
+}
+
+type bookReader interface {
+	Next() (int, *domain.Book, error)
+}
+
+type bookWriter interface {
+	Write(book *domain.Book) error
+	Flush() error
+}
+
+func readAll(t *testing.T, reader bookReader) []*domain.Book {
+	t.Helper()
+
+	var books []*domain.Book
+	for {
+		_, book, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return books
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		books = append(books, book)
+	}
+}
+
+func writeAll(t *testing.T, writer bookWriter, books []*domain.Book) {
+	t.Helper()
+
+	for _, book := range books {
+		if err := writer.Write(book); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+}
+
+func assertBooks(t *testing.T, got, want []*domain.Book) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d books, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("book %d:\n got  %+v\n want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func openSample(t *testing.T, name string) *os.File {
+	t.Helper()
+
+	file, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+	return file
+}
+
+func TestReadSampleFiles(t *testing.T) {
+	t.Run("iso2709", func(t *testing.T) {
+		assertBooks(t, readAll(t, NewReader(openSample(t, "books.mrc"))), sampleBooks)
+	})
+	t.Run("marcxml", func(t *testing.T) {
+		assertBooks(t, readAll(t, NewXMLReader(openSample(t, "books.xml"))), sampleBooks)
+	})
+}
+
+func TestRoundTrip(t *testing.T) {
+	codecs := []struct {
+		name      string
+		newWriter func(w io.Writer) bookWriter
+		newReader func(r io.Reader) bookReader
+	}{
+		{"iso2709", func(w io.Writer) bookWriter { return NewWriter(w) }, func(r io.Reader) bookReader { return NewReader(r) }},
+		{"marcxml", func(w io.Writer) bookWriter { return NewXMLWriter(w) }, func(r io.Reader) bookReader { return NewXMLReader(r) }},
+	}
+
+	catalog := []*domain.Book{
+		{
+			Title:           "O Senhor dos Anéis",
+			Author:          "J.R.R. Tolkien",
+			ISBN:            "9788533615120",
+			Description:     "Uma história épica de fantasia...\nCom <marcação> & \"aspas\".",
+			Publisher:       "Martins Fontes",
+			PublicationYear: 2001,
+			Subjects: domain.StringList{"Fantasia", "Ficção inglesa -- História e crítica",
+				"Literatura brasileira -- Rio de Janeiro (RJ) -- Século XX -- Ficção"},
+			SubjectCodes: domain.StringList{"", "x", "zyv"},
+		},
+		{
+			Title:        "Livro sem editora",
+			Author:       "Autor Desconhecido",
+			Subjects:     domain.StringList{},
+			SubjectCodes: domain.StringList{},
+		},
+	}
+
+	for _, codec := range codecs {
+		t.Run(codec.name+"/catalog", func(t *testing.T) {
+			var buf bytes.Buffer
+			writeAll(t, codec.newWriter(&buf), catalog)
+			assertBooks(t, readAll(t, codec.newReader(&buf)), catalog)
+		})
+
+		t.Run(codec.name+"/sample", func(t *testing.T) {
+			var buf bytes.Buffer
+			writeAll(t, codec.newWriter(&buf), sampleBooks)
+			assertBooks(t, readAll(t, codec.newReader(&buf)), sampleBooks)
+		})
+
+		t.Run(codec.name+"/empty", func(t *testing.T) {
+			var buf bytes.Buffer
+			writeAll(t, codec.newWriter(&buf), nil)
+			if books := readAll(t, codec.newReader(&buf)); len(books) != 0 {
+				t.Errorf("got %d books from an empty export", len(books))
+			}
+		})
+	}
+}
+
+func TestFromBook(t *testing.T) {
+	tests := []struct {
+		name      string
+		book      *domain.Book
+		wantInd1  byte
+		wantCodes string
+	}{
+		{
+			name:      "inverted name and stored codes",
+			book:      &domain.Book{Author: "Assis, Machado de", Subjects: domain.StringList{"Brasil -- História -- 1889-1930"}, SubjectCodes: domain.StringList{"xy"}},
+			wantInd1:  '1',
+			wantCodes: "axy",
+		},
+		{
+			name:      "direct order without codes",
+			book:      &domain.Book{Author: "Machado de Assis", Subjects: domain.StringList{"Brasil -- História -- 1889-1930"}},
+			wantInd1:  '0',
+			wantCodes: "axx",
+		},
+		{
+			name:      "codes of other subjects",
+			book:      &domain.Book{Author: "Machado de Assis", Subjects: domain.StringList{"Brasil -- História -- 1889-1930"}, SubjectCodes: domain.StringList{"v"}},
+			wantInd1:  '0',
+			wantCodes: "axx",
+		},
+		{
+			name:      "invalid codes",
+			book:      &domain.Book{Author: "Machado de Assis", Subjects: domain.StringList{"Brasil -- História -- 1889-1930"}, SubjectCodes: domain.StringList{"ab"}},
+			wantInd1:  '0',
+			wantCodes: "axx",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := FromBook(tt.book)
+
+			if author := record.Fields(TagMainEntry); len(author) != 1 || author[0].Ind1 != tt.wantInd1 {
+				t.Errorf("100 = %+v, want ind1 %q", author, tt.wantInd1)
+			}
+
+			subjects := record.Fields(TagSubject)
+			if len(subjects) != 1 {
+				t.Fatalf("got %d 650 fields, want 1", len(subjects))
+			}
+			var codes []byte
+			for _, subfield := range subjects[0].Subfields {
+				codes = append(codes, subfield.Code)
+			}
+			if string(codes) != tt.wantCodes {
+				t.Errorf("650 subfield codes = %q, want %q", codes, tt.wantCodes)
+			}
+		})
+	}
+}
+
+func TestISO2709PreservesRecord(t *testing.T) {
+	data, err := os.ReadFile("testdata/books.mrc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var records []*Record
+	reader := NewReader(bytes.NewReader(data))
+	for {
+		record, err := reader.ReadRecord()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("ReadRecord() error = %v", err)
+		}
+		records = append(records, record)
+	}
+
+	var buf bytes.Buffer
+	writer := NewWriter(&buf)
+	for _, record := range records {
+		if err := writer.WriteRecord(record); err != nil {
+			t.Fatalf("WriteRecord() error = %v", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("rewritten file differs from testdata/books.mrc")
+	}
+}
+
+func TestISO2709MalformedRecord(t *testing.T) {
+	data, err := os.ReadFile("testdata/books.mrc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Corrompe o endereço base do segundo registro
+	records := bytes.SplitAfter(data, []byte{recordTerminator})
+	corrupted := append([]byte{}, records[1]...)
+	copy(corrupted[12:17], "99999")
+	stream := bytes.Join([][]byte{records[0], corrupted, records[2]}, []byte("\n"))
+
+	reader := NewReader(bytes.NewReader(stream))
+	var positions []int
+	var failed []int
+	for {
+		position, book, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var recordErr *domain.RecordError
+		if errors.As(err, &recordErr) {
+			failed = append(failed, recordErr.Position)
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		if book.Title == "" {
+			t.Errorf("record %d has no title", position)
+		}
+		positions = append(positions, position)
+	}
+
+	if !reflect.DeepEqual(positions, []int{1, 3}) || !reflect.DeepEqual(failed, []int{2}) {
+		t.Errorf("read records %v and failed %v, want [1 3] and [2]", positions, failed)
+	}
+}
+
+func TestMARCXMLInvalidDocument(t *testing.T) {
+	document := `<collection>
+  <record><datafield tag="245" ind1="0" ind2="0"><subfield code="a">Primeiro</subfield></datafield><datafield tag="100" ind1="0" ind2=" "><subfield code="a">Autor</subfield></datafield></record>
+  <record><datafield tag="245"><subfield code="a">Segundo</datafield></record>
+  <record><datafield tag="245" ind1="0" ind2="0"><subfield code="a">Terceiro</subfield></datafield></record>
+</collection>`
+
+	reader := NewXMLReader(strings.NewReader(document))
+
+	position, book, err := reader.Next()
+	if err != nil || position != 1 || book.Title != "Primeiro" {
+		t.Fatalf("Next() = %d, %+v, %v", position, book, err)
+	}
+
+	var recordErr *domain.RecordError
+	_, _, err = reader.Next()
+	if !errors.As(err, &recordErr) || recordErr.Position != 2 {
+		t.Fatalf("Next() error = %v, want a RecordError for record 2", err)
+	}
+
+	// O restante do documento não é interpretado
+	if _, _, err := reader.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("Next() after a syntax error = %v, want io.EOF", err)
+	}
+}
+
+func TestTrimPunctuation(t *testing.T) {
+	tests := map[string]string{
+		"O senhor dos anéis :":  "O senhor dos anéis",
+		"Tolkien, J. R. R.,":    "Tolkien, J. R. R.",
+		"Martins Fontes,":       "Martins Fontes",
+		"Fantasia.":             "Fantasia",
+		"a sociedade do anel /": "a sociedade do anel",
+		"Rowling, J.K.":         "Rowling, J.K.",
+	}
+	for in, want := range tests {
+		if got := trimPunctuation(in); got != want {
+			t.Errorf("trimPunctuation(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package marc
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+// Namespace do esquema MARCXML (MARC 21 slim)
+const Namespace = "http://www.loc.gov/MARC21/slim"
+
+type xmlRecord struct {
+	XMLName       xml.Name          `xml:"record"`
+	Leader        string            `xml:"leader"`
+	ControlFields []xmlControlField `xml:"controlfield"`
+	DataFields    []xmlDataField    `xml:"datafield"`
+}
+
+type xmlControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlDataField struct {
+	Tag       string        `xml:"tag,attr"`
+	Ind1      string        `xml:"ind1,attr"`
+	Ind2      string        `xml:"ind2,attr"`
+	Subfields []xmlSubfield `xml:"subfield"`
+}
+
+type xmlSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// XMLReader lê os elementos record de um documento MARCXML, seja uma
+// collection ou um único record
+type XMLReader struct {
+	decoder  *xml.Decoder
+	position int
+	done     bool
+}
+
+func NewXMLReader(r io.Reader) *XMLReader {
+	return &XMLReader{decoder: xml.NewDecoder(r)}
+}
+
+// ReadRecord devolve o próximo registro. Um registro com conteúdo inválido é
+// devolvido como *domain.RecordError e a leitura continua. Um erro de sintaxe
+// XML também é devolvido como *domain.RecordError, mas encerra a leitura, já
+// que o restante do documento não pode ser interpretado.
+func (r *XMLReader) ReadRecord() (*Record, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+
+	for {
+		token, err := r.decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, io.EOF
+			}
+			return nil, r.fail(r.position+1, err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "record" {
+			continue
+		}
+
+		r.position++
+		var raw xmlRecord
+		if err := r.decoder.DecodeElement(&raw, &start); err != nil {
+			return nil, r.fail(r.position, err)
+		}
+
+		record, err := raw.record()
+		if err != nil {
+			return nil, &domain.RecordError{Position: r.position, Message: err.Error()}
+		}
+		return record, nil
+	}
+}
+
+func (r *XMLReader) fail(position int, err error) error {
+	var syntaxErr *xml.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return err
+	}
+
+	r.done = true
+	r.position = position
+	return &domain.RecordError{Position: position, Message: fmt.Sprintf("invalid MARCXML: %v", syntaxErr)}
+}
+
+// Next implementa usecase.BookReader
+func (r *XMLReader) Next() (int, *domain.Book, error) {
+	record, err := r.ReadRecord()
+	if err != nil {
+		return r.position, nil, err
+	}
+	return r.position, ToBook(record), nil
+}
+
+func (x *xmlRecord) record() (*Record, error) {
+	record := &Record{Leader: x.Leader}
+
+	for _, field := range x.ControlFields {
+		if len(field.Tag) != 3 {
+			return nil, fmt.Errorf("invalid tag %q", field.Tag)
+		}
+		record.ControlFields = append(record.ControlFields, ControlField{Tag: field.Tag, Value: field.Value})
+	}
+
+	for _, field := range x.DataFields {
+		if len(field.Tag) != 3 {
+			return nil, fmt.Errorf("invalid tag %q", field.Tag)
+		}
+		if len(field.Ind1) > 1 || len(field.Ind2) > 1 {
+			return nil, fmt.Errorf("invalid indicators in field %s", field.Tag)
+		}
+
+		data := DataField{Tag: field.Tag, Ind1: ' ', Ind2: ' '}
+		if field.Ind1 != "" {
+			data.Ind1 = field.Ind1[0]
+		}
+		if field.Ind2 != "" {
+			data.Ind2 = field.Ind2[0]
+		}
+		for _, subfield := range field.Subfields {
+			if len(subfield.Code) != 1 {
+				return nil, fmt.Errorf("invalid subfield code %q in field %s", subfield.Code, field.Tag)
+			}
+			data.Subfields = append(data.Subfields, Subfield{Code: subfield.Code[0], Value: subfield.Value})
+		}
+		record.DataFields = append(record.DataFields, data)
+	}
+
+	return record, nil
+}
+
+// XMLWriter grava os registros em um documento MARCXML com um elemento
+// collection na raiz
+type XMLWriter struct {
+	w       *bufio.Writer
+	encoder *xml.Encoder
+	started bool
+}
+
+func NewXMLWriter(w io.Writer) *XMLWriter {
+	buffered := bufio.NewWriter(w)
+	encoder := xml.NewEncoder(buffered)
+	encoder.Indent("  ", "  ")
+	return &XMLWriter{w: buffered, encoder: encoder}
+}
+
+// Write implementa usecase.BookWriter
+func (w *XMLWriter) Write(book *domain.Book) error {
+	return w.WriteRecord(FromBook(book))
+}
+
+func (w *XMLWriter) WriteRecord(record *Record) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	raw := xmlRecord{Leader: record.Leader}
+	for _, field := range record.ControlFields {
+		raw.ControlFields = append(raw.ControlFields, xmlControlField{Tag: field.Tag, Value: field.Value})
+	}
+	for _, field := range record.DataFields {
+		data := xmlDataField{
+			Tag:  field.Tag,
+			Ind1: string(indicator(field.Ind1)),
+			Ind2: string(indicator(field.Ind2)),
+		}
+		for _, subfield := range field.Subfields {
+			data.Subfields = append(data.Subfields, xmlSubfield{Code: string(subfield.Code), Value: subfield.Value})
+		}
+		raw.DataFields = append(raw.DataFields, data)
+	}
+
+	return w.encoder.Encode(raw)
+}
+
+// Flush fecha a collection; um catálogo vazio gera uma collection vazia
+func (w *XMLWriter) Flush() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if err := w.encoder.Flush(); err != nil {
+		return err
+	}
+	if _, err := w.w.WriteString("\n</collection>\n"); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+func (w *XMLWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	_, err := fmt.Fprintf(w.w, "%s<collection xmlns=\"%s\">", xml.Header, Namespace)
+	return err
+}
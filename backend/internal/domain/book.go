@@ -1,30 +1,48 @@
 package domain
 
 import (
+    "database/sql/driver"
+    "encoding/json"
+    "fmt"
     "time"
+    "unicode/utf8"
 )
 
 // Book representa a entidade livro no sistema
 // @Description Book entity representing a book in the system
 type Book struct {
     // ID único do livro
-    ID          string    `json:"id" db:"id" example:"e0c7f36a-9c5e-4c7d-b0a1-596b344f3a0b"`
+    ID              string        `json:"id" db:"id" example:"e0c7f36a-9c5e-4c7d-b0a1-596b344f3a0b"`
     // Título do livro
-    Title       string    `json:"title" db:"title" example:"O Senhor dos Anéis" binding:"required"`
+    Title           string        `json:"title" db:"title" example:"O Senhor dos Anéis" binding:"required"`
     // Autor do livro
-    Author      string    `json:"author" db:"author" example:"J.R.R. Tolkien" binding:"required"`
+    Author          string        `json:"author" db:"author" example:"J.R.R. Tolkien" binding:"required"`
     // ISBN do livro
-    ISBN        string    `json:"isbn" db:"isbn" example:"9788533615120"`
+    ISBN            string        `json:"isbn" db:"isbn" example:"9788533615120"`
     // Descrição do livro
-    Description string    `json:"description" db:"description" example:"Uma história épica de fantasia..."`
+    Description     string        `json:"description" db:"description" example:"Uma história épica de fantasia..."`
     // URL da capa do livro
-    CoverURL    string    `json:"cover_url" db:"cover_url" example:"https://example.com/cover.jpg"`
+    CoverURL        string        `json:"cover_url" db:"cover_url" example:"https://example.com/cover.jpg"`
+    // Versões redimensionadas da capa; null enquanto não forem geradas
+    Covers          CoverVariants `json:"covers" db:"cover_variants"`
+    // Editora
+    Publisher       string        `json:"publisher" db:"publisher" example:"Martins Fontes"`
+    // Ano de publicação (0 quando desconhecido)
+    PublicationYear int           `json:"publication_year" db:"publication_year" example:"2000"`
+    // Assuntos do livro
+    Subjects        StringList    `json:"subjects" db:"subjects" swaggertype:"array,string" example:"Fantasia,Literatura inglesa"`
+    // Códigos MARC das subdivisões de cada assunto, na ordem de Subjects (ex.:
+    // "zv" para "Brasil -- Ficção -- Século XX"); vazio para os assuntos que
+    // não vieram de um registro MARC
+    SubjectCodes    StringList    `json:"-" db:"subject_codes"`
     // Status do livro (available, borrowed, lost)
-    Status      string    `json:"status" db:"status" example:"available" enums:"available,borrowed,lost"`
+    Status          string        `json:"status" db:"status" example:"available" enums:"available,borrowed,lost"`
+    // Versão do registro, usada para controle de concorrência otimista (ETag)
+    Version         int64         `json:"version" db:"version" example:"1"`
     // Data de criação do registro
-    CreatedAt   time.Time `json:"created_at" db:"created_at"`
+    CreatedAt       time.Time     `json:"created_at" db:"created_at"`
     // Data de atualização do registro
-    UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+    UpdatedAt       time.Time     `json:"updated_at" db:"updated_at"`
 }
 
 // BookStatus define os possíveis estados de um livro
@@ -32,4 +50,121 @@ const (
     StatusAvailable = "available"
     StatusBorrowed  = "borrowed"
     StatusLost      = "lost"
-)
\ No newline at end of file
+)
+
+// Tamanho máximo, em caracteres, de título, autor e editora
+const MaxBookTextLength = 255
+
+// Validate verifica se o livro está em um estado válido para ser persistido.
+// O erro é um *ValidationError com todos os campos inválidos.
+func (b *Book) Validate() error {
+    validation := &ValidationError{}
+
+    if b.Title == "" {
+        validation.Add("title", "title is required")
+    }
+    if b.Author == "" {
+        validation.Add("author", "author is required")
+    }
+
+    for _, field := range []struct{ name, text string }{
+        {"title", b.Title}, {"author", b.Author}, {"publisher", b.Publisher},
+    } {
+        if utf8.RuneCountInString(field.text) > MaxBookTextLength {
+            validation.Add(field.name, fmt.Sprintf("%s must have at most %d characters", field.name, MaxBookTextLength))
+        }
+    }
+
+    if b.PublicationYear < 0 {
+        validation.Add("publication_year", "publication year must not be negative")
+    }
+
+    switch b.Status {
+    case StatusAvailable, StatusBorrowed, StatusLost:
+    default:
+        validation.Add("status", fmt.Sprintf("invalid status %q", b.Status))
+    }
+
+    return validation.Err()
+}
+
+// MarshalJSON omite as versões da capa enquanto elas não corresponderem à
+// capa atual, ou seja, enquanto ainda estiverem sendo geradas ou tiverem falhado
+func (b Book) MarshalJSON() ([]byte, error) {
+    type book Book
+
+    var covers *CoverVariants
+    if b.Covers.Ready(b.CoverURL) {
+        covers = &b.Covers
+    }
+
+    return json.Marshal(struct {
+        book
+        Covers *CoverVariants `json:"covers"`
+    }{book(b), covers})
+}
+
+// SetSubjects substitui os assuntos, mantendo os códigos MARC dos que continuam
+// na lista
+func (b *Book) SetSubjects(subjects StringList) {
+    codes := make(map[string]string, len(b.Subjects))
+    for i, subject := range b.Subjects {
+        if i < len(b.SubjectCodes) {
+            codes[subject] = b.SubjectCodes[i]
+        }
+    }
+
+    b.Subjects = subjects
+    b.SubjectCodes = nil
+    if len(codes) == 0 {
+        return
+    }
+    b.SubjectCodes = make(StringList, len(subjects))
+    for i, subject := range subjects {
+        b.SubjectCodes[i] = codes[subject]
+    }
+}
+
+// StringList é uma lista de textos persistida como um array JSON
+type StringList []string
+
+// MarshalJSON serializa a lista vazia como [] em vez de null
+func (l StringList) MarshalJSON() ([]byte, error) {
+    if l == nil {
+        return []byte("[]"), nil
+    }
+    return json.Marshal([]string(l))
+}
+
+func (l StringList) Value() (driver.Value, error) {
+    if l == nil {
+        return "[]", nil
+    }
+    data, err := json.Marshal([]string(l))
+    if err != nil {
+        return nil, err
+    }
+    return string(data), nil
+}
+
+func (l *StringList) Scan(src interface{}) error {
+    var data []byte
+    switch v := src.(type) {
+    case nil:
+        *l = StringList{}
+        return nil
+    case string:
+        data = []byte(v)
+    case []byte:
+        data = v
+    default:
+        return fmt.Errorf("cannot scan %T into StringList", src)
+    }
+
+    var list []string
+    if err := json.Unmarshal(data, &list); err != nil {
+        return err
+    }
+    *l = StringList(list)
+    return nil
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+    "database/sql/driver"
+    "encoding/json"
+    "fmt"
+)
+
+// Erros do envio de capas
+var (
+    ErrCoverTooLarge    = &Error{Code: CodeCoverTooLarge, Message: "cover image is too large"}
+    ErrUnsupportedCover = &Error{Code: CodeUnsupportedCover, Message: "cover must be a JPEG, PNG, GIF or WebP image", Err: ErrInvalidInput}
+)
+
+// CoverExtensions associa os tipos de imagem aceitos como capa à extensão
+// usada no nome do arquivo armazenado
+var CoverExtensions = map[string]string{
+    "image/jpeg": ".jpg",
+    "image/png":  ".png",
+    "image/gif":  ".gif",
+    "image/webp": ".webp",
+}
+
+// CoverVariants são as versões redimensionadas da capa, geradas em segundo
+// plano sempre que a capa do livro muda
+type CoverVariants struct {
+    // URL da capa a partir da qual as versões foram geradas
+    Source    string `json:"-"`
+    // Motivo pelo qual as versões não puderam ser geradas
+    Error     string `json:"-"`
+    // Miniatura para listagens (até 200x300)
+    Thumbnail string `json:"thumbnail" example:"http://localhost:8080/api/covers/e0c7f36a-9c5e-4c7d-b0a1-596b344f3a0b-1f2e3d4c5b6a7980-thumbnail.jpg"`
+    // Versão média (até 400x600)
+    Medium    string `json:"medium" example:"http://localhost:8080/api/covers/e0c7f36a-9c5e-4c7d-b0a1-596b344f3a0b-1f2e3d4c5b6a7980-medium.jpg"`
+    // Versão grande (até 800x1200)
+    Large     string `json:"large" example:"http://localhost:8080/api/covers/e0c7f36a-9c5e-4c7d-b0a1-596b344f3a0b-1f2e3d4c5b6a7980-large.jpg"`
+}
+
+// Ready informa se as versões foram geradas a partir de coverURL
+func (v CoverVariants) Ready(coverURL string) bool {
+    return v.Source != "" && v.Source == coverURL && v.Error == "" && v.Thumbnail != ""
+}
+
+// URLs devolve as URLs das versões existentes
+func (v CoverVariants) URLs() []string {
+    var urls []string
+    for _, url := range []string{v.Thumbnail, v.Medium, v.Large} {
+        if url != "" {
+            urls = append(urls, url)
+        }
+    }
+    return urls
+}
+
+// coverVariantsColumn é o formato persistido, que inclui a origem e o erro
+type coverVariantsColumn struct {
+    Source    string `json:"source,omitempty"`
+    Error     string `json:"error,omitempty"`
+    Thumbnail string `json:"thumbnail,omitempty"`
+    Medium    string `json:"medium,omitempty"`
+    Large     string `json:"large,omitempty"`
+}
+
+func (v CoverVariants) Value() (driver.Value, error) {
+    data, err := json.Marshal(coverVariantsColumn(v))
+    if err != nil {
+        return nil, err
+    }
+    return string(data), nil
+}
+
+func (v *CoverVariants) Scan(src interface{}) error {
+    var data []byte
+    switch value := src.(type) {
+    case nil:
+        *v = CoverVariants{}
+        return nil
+    case string:
+        data = []byte(value)
+    case []byte:
+        data = value
+    default:
+        return fmt.Errorf("cannot scan %T into CoverVariants", src)
+    }
+
+    var column coverVariantsColumn
+    if err := json.Unmarshal(data, &column); err != nil {
+        return err
+    }
+    *v = CoverVariants(column)
+    return nil
+}
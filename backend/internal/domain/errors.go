@@ -1,10 +1,116 @@
 package domain
 
-import "errors"
+import (
+    "fmt"
+    "strings"
+)
+
+// Code identifica o tipo de um erro de forma estável para os clientes da API,
+// que não devem depender do texto da mensagem
+type Code string
+
+// Códigos dos erros do domínio
+const (
+    CodeValidationFailed         Code = "VALIDATION_FAILED"
+    CodeInvalidISBN              Code = "INVALID_ISBN"
+    CodeBookNotFound             Code = "BOOK_NOT_FOUND"
+    CodeUserNotFound             Code = "USER_NOT_FOUND"
+    CodeUserDisabled             Code = "USER_DISABLED"
+    CodeEmailTaken               Code = "EMAIL_TAKEN"
+    CodeVersionConflict          Code = "VERSION_CONFLICT"
+    CodeBlobNotFound             Code = "BLOB_NOT_FOUND"
+    CodeCoverTooLarge            Code = "COVER_TOO_LARGE"
+    CodeUnsupportedCover         Code = "UNSUPPORTED_COVER"
+    CodeIdempotencyKeyInProgress Code = "IDEMPOTENCY_KEY_IN_PROGRESS"
+    CodeIdempotencyKeyReused     Code = "IDEMPOTENCY_KEY_REUSED"
+    CodeMetadataNotFound         Code = "METADATA_NOT_FOUND"
+    CodeMetadataUnavailable      Code = "METADATA_UNAVAILABLE"
+    CodeBatchAborted             Code = "BATCH_ABORTED"
+)
+
+// Error é um erro do domínio com código. Os erros são comparados com
+// errors.Is contra as variáveis Err* e o código é obtido com errors.As. Err,
+// quando preenchido, é um erro mais geral que este especializa, como
+// ErrInvalidInput para ErrEmailTaken.
+type Error struct {
+    Code    Code
+    Message string
+    Err     error
+}
+
+func (e *Error) Error() string {
+    return e.Message
+}
+
+func (e *Error) Unwrap() error {
+    return e.Err
+}
 
 // Erros do domínio
 var (
-    ErrBookNotFound = errors.New("book not found")
-    ErrUserNotFound = errors.New("user not found")
-    ErrInvalidInput = errors.New("invalid input")
+    ErrBookNotFound    = &Error{Code: CodeBookNotFound, Message: "book not found"}
+    ErrUserNotFound    = &Error{Code: CodeUserNotFound, Message: "user not found"}
+    ErrUserDisabled    = &Error{Code: CodeUserDisabled, Message: "user is disabled"}
+    ErrInvalidInput    = &Error{Code: CodeValidationFailed, Message: "invalid input"}
+    ErrVersionConflict = &Error{Code: CodeVersionConflict, Message: "version conflict: the record was modified by another request"}
+    ErrBlobNotFound    = &Error{Code: CodeBlobNotFound, Message: "blob not found"}
+    ErrEmailTaken      = &Error{Code: CodeEmailTaken, Message: "email already exists", Err: ErrInvalidInput}
 )
+
+// FieldError descreve o problema de um campo da entrada
+type FieldError struct {
+    Field   string `json:"field" example:"title"`
+    Message string `json:"message" example:"title is required"`
+}
+
+// ValidationError reúne os problemas de cada campo encontrados na validação
+// de uma entrada. É também ErrInvalidInput.
+type ValidationError struct {
+    Fields []FieldError
+}
+
+// NewValidationError cria o erro de validação de um único campo
+func NewValidationError(field, message string) *ValidationError {
+    return &ValidationError{Fields: []FieldError{{Field: field, Message: message}}}
+}
+
+// Add registra um problema no campo
+func (e *ValidationError) Add(field, message string) {
+    e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// Err devolve o erro, ou nil se nenhum problema foi registrado
+func (e *ValidationError) Err() error {
+    if len(e.Fields) == 0 {
+        return nil
+    }
+    return e
+}
+
+func (e *ValidationError) Error() string {
+    messages := make([]string, len(e.Fields))
+    for i, field := range e.Fields {
+        messages[i] = field.Message
+    }
+    return fmt.Sprintf("%s: %s", ErrInvalidInput, strings.Join(messages, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+    return ErrInvalidInput
+}
+
+// RecordError descreve um problema em um registro de um arquivo importado
+type RecordError struct {
+    // Posição do registro na origem (linha do CSV, índice do registro MARC)
+    Position int    `json:"position" example:"3"`
+    // Campo com problema, quando conhecido
+    Field    string `json:"field,omitempty" example:"isbn"`
+    Message  string `json:"message" example:"duplicate ISBN"`
+}
+
+func (e *RecordError) Error() string {
+    if e.Field != "" {
+        return fmt.Sprintf("record %d: %s: %s", e.Position, e.Field, e.Message)
+    }
+    return fmt.Sprintf("record %d: %s", e.Position, e.Message)
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+    "time"
+)
+
+// IdempotencyRecord guarda a resposta de uma requisição identificada por uma
+// Idempotency-Key, para que novas tentativas recebam a mesma resposta
+type IdempotencyRecord struct {
+    // Chave informada pelo cliente, combinada com o método e a rota
+    Key         string
+    // Hash do método, rota e corpo da requisição original
+    Fingerprint string
+    // Indica se a requisição original já terminou
+    Completed   bool
+    // Até quando a requisição original reserva a chave. Sem resposta
+    // armazenada depois disso, uma nova tentativa pode assumir a chave.
+    LockedUntil time.Time
+    // Resposta armazenada
+    StatusCode  int
+    Headers     map[string]string
+    Body        []byte
+    // Datas de criação e expiração da chave
+    CreatedAt   time.Time
+    ExpiresAt   time.Time
+}
+
+// Erros de idempotência
+var (
+    ErrIdempotencyKeyInProgress = &Error{Code: CodeIdempotencyKeyInProgress, Message: "a request with this idempotency key is still being processed"}
+    ErrIdempotencyKeyReused     = &Error{Code: CodeIdempotencyKeyReused, Message: "idempotency key was already used with a different request"}
+)
\ No newline at end of file
@@ -0,0 +1,152 @@
+package domain
+
+import (
+    _ "embed"
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// ErrInvalidISBN indica um ISBN com tamanho, caracteres ou dígito verificador inválidos
+var ErrInvalidISBN = &Error{Code: CodeInvalidISBN, Message: "invalid ISBN", Err: ErrInvalidInput}
+
+// ISBN é um ISBN-13 normalizado: apenas os 13 dígitos, sem hífens
+type ISBN string
+
+// ParseISBN aceita um ISBN-10 ou ISBN-13, com ou sem hífens e espaços, confere
+// o dígito verificador e devolve o ISBN-13 equivalente
+func ParseISBN(value string) (ISBN, error) {
+    digits := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(value)))
+
+    switch len(digits) {
+    case 10:
+        for i, r := range digits {
+            if (r < '0' || r > '9') && !(r == 'X' && i == 9) {
+                return "", fmt.Errorf("%w: %q has invalid characters", ErrInvalidISBN, value)
+            }
+        }
+        if isbn10CheckDigit(digits[:9]) != digits[9] {
+            return "", fmt.Errorf("%w: %q has an invalid check digit", ErrInvalidISBN, value)
+        }
+        body := "978" + digits[:9]
+        return ISBN(body + string(isbn13CheckDigit(body))), nil
+
+    case 13:
+        for _, r := range digits {
+            if r < '0' || r > '9' {
+                return "", fmt.Errorf("%w: %q has invalid characters", ErrInvalidISBN, value)
+            }
+        }
+        if !strings.HasPrefix(digits, "978") && !strings.HasPrefix(digits, "979") {
+            return "", fmt.Errorf("%w: %q does not start with 978 or 979", ErrInvalidISBN, value)
+        }
+        if isbn13CheckDigit(digits[:12]) != digits[12] {
+            return "", fmt.Errorf("%w: %q has an invalid check digit", ErrInvalidISBN, value)
+        }
+        return ISBN(digits), nil
+
+    default:
+        return "", fmt.Errorf("%w: %q must have 10 or 13 digits", ErrInvalidISBN, value)
+    }
+}
+
+func (i ISBN) String() string {
+    return string(i)
+}
+
+// ISBN10 devolve a forma ISBN-10, que só existe para o prefixo 978
+func (i ISBN) ISBN10() (string, bool) {
+    if len(i) != 13 || !strings.HasPrefix(string(i), "978") {
+        return "", false
+    }
+    body := string(i[3:12])
+    return body + string(isbn10CheckDigit(body)), true
+}
+
+// Hyphenated formata o ISBN como prefixo-grupo-registrante-publicação-dígito
+// (ex.: 978-85-336-1512-0). Sem faixa conhecida para o grupo, o ISBN é
+// devolvido sem hífens.
+func (i ISBN) Hyphenated() string {
+    if len(i) != 13 {
+        return string(i)
+    }
+
+    prefix, rest := string(i[:3]), string(i[3:12])
+    for groupLength := 1; groupLength <= 5 && groupLength < len(rest); groupLength++ {
+        group := rest[:groupLength]
+        rules, ok := isbnRanges[prefix+"-"+group]
+        if !ok {
+            continue
+        }
+
+        remainder := rest[groupLength:]
+        key, _ := strconv.Atoi((remainder + "0000000")[:7])
+        for _, rule := range rules {
+            if key < rule.start || key > rule.end {
+                continue
+            }
+            if rule.length == 0 || rule.length >= len(remainder) {
+                return string(i)
+            }
+            return strings.Join([]string{prefix, group, remainder[:rule.length], remainder[rule.length:], string(i[12])}, "-")
+        }
+        return string(i)
+    }
+
+    return string(i)
+}
+
+func isbn10CheckDigit(body string) byte {
+    sum := 0
+    for i := 0; i < 9; i++ {
+        sum += int(body[i]-'0') * (10 - i)
+    }
+    check := (11 - sum%11) % 11
+    if check == 10 {
+        return 'X'
+    }
+    return byte('0' + check)
+}
+
+func isbn13CheckDigit(body string) byte {
+    sum := 0
+    for i := 0; i < 12; i++ {
+        weight := 1
+        if i%2 == 1 {
+            weight = 3
+        }
+        sum += int(body[i]-'0') * weight
+    }
+    return byte('0' + (10-sum%10)%10)
+}
+
+// Faixas de registrantes por prefixo e grupo, carregadas de isbn_ranges.txt,
+// gerado a partir do RangeMessage.xml da International ISBN Agency
+//go:generate go run ./isbnranges -out isbn_ranges.txt
+//go:embed isbn_ranges.txt
+var isbnRangesFile string
+
+type isbnRange struct {
+    start, end int
+    length     int
+}
+
+var isbnRanges = parseISBNRanges(isbnRangesFile)
+
+func parseISBNRanges(file string) map[string][]isbnRange {
+    ranges := make(map[string][]isbnRange)
+    for _, line := range strings.Split(file, "\n") {
+        line = strings.TrimSpace(line)
+        if line == "" || strings.HasPrefix(line, "#") {
+            continue
+        }
+
+        var group string
+        var rule isbnRange
+        if _, err := fmt.Sscanf(line, "%s %d-%d %d", &group, &rule.start, &rule.end, &rule.length); err != nil {
+            panic(fmt.Sprintf("invalid line in isbn_ranges.txt: %q", line))
+        }
+        ranges[group] = append(ranges[group], rule)
+    }
+    return ranges
+}
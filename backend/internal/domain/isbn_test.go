@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseISBN(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    ISBN
+		wantErr bool
+	}{
+		{value: "9788533615120", want: "9788533615120"},
+		{value: "978-85-336-1512-0", want: "9788533615120"},
+		{value: " 978 85 336 1512 0 ", want: "9788533615120"},
+		{value: "8533615124", want: "9788533615120"},
+		{value: "0-306-40615-2", want: "9780306406157"},
+		{value: "0-8044-2957-X", want: "9780804429573"},
+		{value: "0-8044-2957-x", want: "9780804429573"},
+		{value: "979-10-90636-07-1", want: "9791090636071"},
+		{value: "9788533615121", wantErr: true},
+		{value: "8533615125", wantErr: true},
+		{value: "X804429570", wantErr: true},
+		{value: "97885336151A0", wantErr: true},
+		{value: "9778533615120", wantErr: true},
+		{value: "853361512", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseISBN(tt.value)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidISBN) || !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("ParseISBN(%q) error = %v, want ErrInvalidISBN", tt.value, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseISBN(%q) = %q, %v; want %q", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestISBN10(t *testing.T) {
+	tests := []struct {
+		isbn   ISBN
+		want   string
+		wantOK bool
+	}{
+		{isbn: "9788533615120", want: "8533615124", wantOK: true},
+		{isbn: "9780804429573", want: "080442957X", wantOK: true},
+		{isbn: "9791090636071", wantOK: false},
+		{isbn: "978853361512", wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := tt.isbn.ISBN10()
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ISBN(%q).ISBN10() = %q, %v; want %q, %v", tt.isbn, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestISBNHyphenated(t *testing.T) {
+	tests := []struct {
+		isbn ISBN
+		want string
+	}{
+		{isbn: "9788533615120", want: "978-85-336-1512-0"},
+		{isbn: "9780306406157", want: "978-0-306-40615-7"},
+		{isbn: "9781402894626", want: "978-1-4028-9462-6"},
+		{isbn: "9782070368228", want: "978-2-07-036822-8"},
+		{isbn: "9783161484100", want: "978-3-16-148410-0"},
+		{isbn: "9784062112345", want: "978-4-06-211234-5"},
+		{isbn: "9788437604947", want: "978-84-376-0494-7"},
+		{isbn: "9788804667780", want: "978-88-04-66778-0"},
+		{isbn: "9789720045676", want: "978-972-0-04567-6"},
+		{isbn: "9789898112231", want: "978-989-8112-23-1"},
+		{isbn: "9791090636071", want: "979-10-90636-07-1"},
+		// Grupos sem faixas na tabela não são hifenizados
+		{isbn: "9789992158104", want: "9789992158104"},
+		{isbn: "9798123456781", want: "9798123456781"},
+		{isbn: "978853361512", want: "978853361512"},
+	}
+	for _, tt := range tests {
+		if got := tt.isbn.Hyphenated(); got != tt.want {
+			t.Errorf("ISBN(%q).Hyphenated() = %q, want %q", tt.isbn, got, tt.want)
+		}
+	}
+}
+
+func TestParseISBNRanges(t *testing.T) {
+	ranges := parseISBNRanges(`
+# Comentário
+978-85 0000000-1999999 2
+978-85 2000000-5999999 0
+`)
+	want := []isbnRange{{start: 0, end: 1999999, length: 2}, {start: 2000000, end: 5999999, length: 0}}
+	got := ranges["978-85"]
+	if len(ranges) != 1 || len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("parseISBNRanges() = %v, want 978-85: %v", ranges, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("parseISBNRanges() of an invalid line did not panic")
+		}
+	}()
+	parseISBNRanges("978-85 invalid")
+}
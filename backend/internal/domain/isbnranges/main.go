@@ -0,0 +1,128 @@
+// Command isbnranges gera o isbn_ranges.txt do pacote domain a partir do
+// RangeMessage.xml da International ISBN Agency. Sem -in, o arquivo é baixado
+// do site da agência:
+//
+//	go generate ./internal/domain
+//	go run ./internal/domain/isbnranges -in RangeMessage.xml -out internal/domain/isbn_ranges.txt
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"time"
+)
+
+const rangeMessageURL = "https://www.isbn-international.org/export_rangemessage.xml"
+
+// rangeMessage é a parte do RangeMessage.xml usada na hifenização: as faixas
+// de registrantes de cada grupo de registro
+type rangeMessage struct {
+	Serial string  `xml:"MessageSerialNumber"`
+	Date   string  `xml:"MessageDate"`
+	Groups []group `xml:"RegistrationGroups>Group"`
+}
+
+type group struct {
+	Prefix string `xml:"Prefix"`
+	Agency string `xml:"Agency"`
+	Rules  []rule `xml:"Rules>Rule"`
+}
+
+type rule struct {
+	Range  string `xml:"Range"`
+	Length int    `xml:"Length"`
+}
+
+var (
+	prefixPattern = regexp.MustCompile(`^97[89]-\d{1,5}$`)
+	rangePattern  = regexp.MustCompile(`^\d{7}-\d{7}$`)
+)
+
+func main() {
+	in := flag.String("in", "", "RangeMessage.xml to read instead of downloading it")
+	out := flag.String("out", "isbn_ranges.txt", "file to write")
+	flag.Parse()
+
+	if err := run(*in, *out); err != nil {
+		fmt.Fprintf(os.Stderr, "isbnranges: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(in, out string) error {
+	source, err := open(in)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	var message rangeMessage
+	if err := xml.NewDecoder(source).Decode(&message); err != nil {
+		return fmt.Errorf("parse range message: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := write(&buf, &message); err != nil {
+		return err
+	}
+
+	return os.WriteFile(out, buf.Bytes(), 0o644)
+}
+
+// open abre o arquivo in ou, sem ele, baixa o RangeMessage.xml atual
+func open(in string) (io.ReadCloser, error) {
+	if in != "" {
+		return os.Open(in)
+	}
+
+	client := &http.Client{Timeout: time.Minute}
+	resp, err := client.Get(rangeMessageURL)
+	if err != nil {
+		return nil, fmt.Errorf("download range message: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("download range message: %s", resp.Status)
+	}
+
+	return resp.Body, nil
+}
+
+// write escreve as faixas no formato lido por domain.parseISBNRanges
+func write(w io.Writer, message *rangeMessage) error {
+	if len(message.Groups) == 0 {
+		return fmt.Errorf("range message has no registration groups")
+	}
+
+	fmt.Fprintf(w, `# Faixas de registrantes por grupo de registro, geradas por isbnranges a
+# partir do RangeMessage.xml da International ISBN Agency
+# (https://www.isbn-international.org/range_file_generation), mensagem %s
+# de %s. Não edite à mão: rode go generate ./internal/domain.
+#
+# Cada linha: <prefixo>-<grupo> <início>-<fim> <dígitos do registrante>
+# O início e o fim são os sete primeiros dígitos após o grupo; tamanho 0
+# indica uma faixa ainda não atribuída.
+`, message.Serial, message.Date)
+
+	for _, group := range message.Groups {
+		if !prefixPattern.MatchString(group.Prefix) {
+			return fmt.Errorf("invalid registration group prefix %q", group.Prefix)
+		}
+
+		fmt.Fprintf(w, "\n# %s\n", group.Agency)
+		for _, rule := range group.Rules {
+			if !rangePattern.MatchString(rule.Range) || rule.Length < 0 || rule.Length > 7 {
+				return fmt.Errorf("invalid rule %+v in group %s", rule, group.Prefix)
+			}
+			fmt.Fprintf(w, "%s %s %d\n", group.Prefix, rule.Range, rule.Length)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "isbn_ranges.txt")
+	if err := run(filepath.Join("testdata", "RangeMessage.xml"), out); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rules []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" && !strings.HasPrefix(line, "#") {
+			rules = append(rules, line)
+		}
+	}
+	want := []string{
+		"978-85 0000000-1999999 2",
+		"978-85 2000000-5999999 3",
+		"979-10 0000000-1999999 2",
+		"979-10 9760000-9999999 0",
+	}
+	if strings.Join(rules, "\n") != strings.Join(want, "\n") {
+		t.Errorf("rules = %q, want %q", rules, want)
+	}
+	if !strings.Contains(string(data), "8d1f0c3a-5b7e-4b8f-9a4c-1e2d3f4a5b6c") || !strings.Contains(string(data), "# Brazil\n") {
+		t.Errorf("output lacks the message serial or the group agency:\n%s", data)
+	}
+}
+
+func TestWriteInvalid(t *testing.T) {
+	tests := map[string]*rangeMessage{
+		"no groups":    {},
+		"prefix":       {Groups: []group{{Prefix: "977-85", Rules: []rule{{Range: "0000000-1999999", Length: 2}}}}},
+		"range":        {Groups: []group{{Prefix: "978-85", Rules: []rule{{Range: "000-199", Length: 2}}}}},
+		"long segment": {Groups: []group{{Prefix: "978-85", Rules: []rule{{Range: "0000000-1999999", Length: 8}}}}},
+	}
+	for name, message := range tests {
+		if err := write(&strings.Builder{}, message); err == nil {
+			t.Errorf("write() with an invalid %s succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package domain
+
+// BookMetadata são os dados bibliográficos de um livro obtidos de um catálogo
+// externo (Open Library, Google Books...)
+type BookMetadata struct {
+    // Catálogo de origem dos dados
+    Source          string   `json:"source" example:"openlibrary"`
+    ISBN            string   `json:"isbn" example:"9788533615120"`
+    Title           string   `json:"title" example:"O Senhor dos Anéis"`
+    Author          string   `json:"author" example:"J.R.R. Tolkien"`
+    Description     string   `json:"description" example:"Uma história épica de fantasia..."`
+    CoverURL        string   `json:"cover_url" example:"https://covers.openlibrary.org/b/id/123-L.jpg"`
+    Publisher       string   `json:"publisher" example:"Martins Fontes"`
+    PublicationYear int      `json:"publication_year" example:"2000"`
+    Subjects        []string `json:"subjects" example:"Fantasia"`
+}
+
+// Erros dos catálogos externos
+var (
+    ErrMetadataNotFound    = &Error{Code: CodeMetadataNotFound, Message: "no metadata found for this ISBN"}
+    ErrMetadataUnavailable = &Error{Code: CodeMetadataUnavailable, Message: "metadata provider unavailable"}
+)
+
+// ApplyTo copia os metadados para o livro e devolve os campos alterados. Sem
+// overwrite apenas os campos vazios do livro são preenchidos.
+func (m *BookMetadata) ApplyTo(book *Book, overwrite bool) []string {
+    changed := []string{}
+
+    setText := func(field string, target *string, value string) {
+        if value == "" || *target == value || (*target != "" && !overwrite) {
+            return
+        }
+        *target = value
+        changed = append(changed, field)
+    }
+
+    setText("title", &book.Title, m.Title)
+    setText("author", &book.Author, m.Author)
+    setText("description", &book.Description, m.Description)
+    setText("cover_url", &book.CoverURL, m.CoverURL)
+    setText("publisher", &book.Publisher, m.Publisher)
+
+    if m.PublicationYear > 0 && book.PublicationYear != m.PublicationYear && (book.PublicationYear == 0 || overwrite) {
+        book.PublicationYear = m.PublicationYear
+        changed = append(changed, "publication_year")
+    }
+
+    if len(m.Subjects) > 0 && (len(book.Subjects) == 0 || overwrite) {
+        book.SetSubjects(append(StringList{}, m.Subjects...))
+        changed = append(changed, "subjects")
+    }
+
+    return changed
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+    "net/mail"
     "time"
 )
 
@@ -15,8 +16,30 @@ type User struct {
     Email     string    `json:"email" db:"email" example:"joao.silva@example.com" binding:"required,email"`
     // Senha do usuário (não retornada nas respostas)
     Password  string    `json:"-" db:"password" binding:"required,min=6"`
+    // Versão do registro, usada para controle de concorrência otimista (ETag)
+    Version   int64     `json:"version" db:"version" example:"1"`
+    // Usuários desativados não conseguem se autenticar
+    Disabled  bool      `json:"disabled" db:"disabled" example:"false"`
     // Data de criação do registro
     CreatedAt time.Time `json:"created_at" db:"created_at"`
     // Data de atualização do registro
     UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
-}
\ No newline at end of file
+}
+
+// Validate verifica se o usuário está em um estado válido para ser persistido.
+// O erro é um *ValidationError com todos os campos inválidos.
+func (u *User) Validate() error {
+    validation := &ValidationError{}
+
+    if u.Name == "" {
+        validation.Add("name", "name is required")
+    }
+
+    if u.Email == "" {
+        validation.Add("email", "email is required")
+    } else if addr, err := mail.ParseAddress(u.Email); err != nil || addr.Address != u.Email {
+        validation.Add("email", "email is not a valid address")
+    }
+
+    return validation.Err()
+}
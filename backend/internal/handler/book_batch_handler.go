@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/handler/dto"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/metrics"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/usecase"
+)
+
+const (
+	batchModeAtomic  = "atomic"
+	batchModePartial = "partial"
+
+	maxBatchBodySize = 32 << 20
+)
+
+// BatchBooks godoc
+// @Summary      Create, update and delete books in bulk
+// @Description  Apply up to 1000 operations. In atomic mode (default) all operations run in one transaction and any failure rolls back the whole batch. In partial mode each operation is applied on its own and the response is 207 Multi-Status with one result per operation.
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Param        Idempotency-Key  header  string                false  "Key that makes retries of this request safe"
+// @Param        batch            body    dto.BookBatchRequest  true   "Batch operations"
+// @Success      200  {object}  dto.BookBatchResponse  "Atomic batch applied"
+// @Success      207  {object}  dto.BookBatchResponse  "Partial batch applied, see each result"
+// @Failure      400  {object}  dto.BookBatchResponse
+// @Failure      404  {object}  dto.BookBatchResponse
+// @Failure      412  {object}  dto.BookBatchResponse
+// @Failure      413  {object}  handler.Problem
+// @Failure      500  {object}  handler.Problem
+// @Router       /books/batch [post]
+func (h *BookHandler) BatchBooks(c *gin.Context) {
+	var request dto.BookBatchRequest
+
+	// O corpo é decodificado sem a validação do binding para que livros
+	// inválidos sejam reportados por operação
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBatchBodySize)
+	if err := json.NewDecoder(c.Request.Body).Decode(&request); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondProblem(c, http.StatusRequestEntityTooLarge, codePayloadTooLarge, "request body too large")
+			return
+		}
+		respondProblem(c, http.StatusBadRequest, codeMalformedRequest, "invalid request body")
+		return
+	}
+
+	if request.Mode == "" {
+		request.Mode = batchModeAtomic
+	}
+	if request.Mode != batchModeAtomic && request.Mode != batchModePartial {
+		respondError(c, domain.NewValidationError("mode", "mode must be atomic or partial"))
+		return
+	}
+
+	ops := make([]usecase.BookBatchOperation, len(request.Operations))
+	for i, op := range request.Operations {
+		ops[i] = usecase.BookBatchOperation{
+			Op:      op.Op,
+			ID:      op.ID,
+			Version: op.Version,
+			Book:    op.Book,
+		}
+	}
+
+	atomic := request.Mode == batchModeAtomic
+	results, err := h.bookService.ExecuteBatch(c.Request.Context(), ops, atomic)
+	if results == nil {
+		respondError(c, err)
+		return
+	}
+
+	response := dto.BookBatchResponse{
+		Mode:    request.Mode,
+		Results: make([]dto.BookBatchItemResult, len(results)),
+	}
+	// Um lote atômico com erro foi desfeito por inteiro
+	committed := !atomic || err == nil
+	created := 0
+	for i, result := range results {
+		item := dto.BookBatchItemResult{
+			Index:  i,
+			Op:     result.Op,
+			ID:     result.ID,
+			Status: batchItemStatus(result),
+			Book:   result.Book,
+		}
+		if result.Err != nil {
+			problem := errorProblem(result.Err)
+			if problem.Status >= http.StatusInternalServerError {
+				c.Error(result.Err)
+			}
+			item.Error, item.Code, item.Errors = problem.Detail, problem.Code, problem.Errors
+			response.Failed++
+		} else {
+			response.Succeeded++
+			if result.Op == usecase.BatchCreate && committed {
+				created++
+			}
+		}
+		response.Results[i] = item
+	}
+
+	metrics.BooksCreatedTotal.WithLabelValues(metrics.SourceBatch).Add(float64(created))
+
+	switch {
+	case !atomic:
+		c.JSON(http.StatusMultiStatus, response)
+	case err != nil:
+		c.JSON(errorProblem(err).Status, response)
+	default:
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+func batchItemStatus(result usecase.BookBatchResult) int {
+	if result.Err != nil {
+		return errorProblem(result.Err).Status
+	}
+
+	switch result.Op {
+	case usecase.BatchCreate:
+		return http.StatusCreated
+	case usecase.BatchDelete:
+		return http.StatusNoContent
+	default:
+		return http.StatusOK
+	}
+}
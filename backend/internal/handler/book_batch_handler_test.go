@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/handler/dto"
+)
+
+func TestBatchBooks(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		operations func(book *domain.Book) string
+		wantStatus int
+		wantItems  []int
+		// Livros na listagem após o lote e título do livro existente
+		wantBooks int
+		wantTitle string
+	}{
+		{
+			name: "atomic",
+			mode: batchModeAtomic,
+			operations: func(book *domain.Book) string {
+				return fmt.Sprintf(`{"op":"create","book":{"title":"Novo","author":"Autor"}},
+					{"op":"update","id":%q,"version":%d,"book":{"title":"Alterado","author":"Autor"}}`, book.ID, book.Version)
+			},
+			wantStatus: http.StatusOK,
+			wantItems:  []int{http.StatusCreated, http.StatusOK},
+			wantBooks:  2,
+			wantTitle:  "Alterado",
+		},
+		{
+			name: "atomic rolls back on a failed operation",
+			mode: batchModeAtomic,
+			operations: func(book *domain.Book) string {
+				return fmt.Sprintf(`{"op":"create","book":{"title":"Novo","author":"Autor"}},
+					{"op":"update","id":%q,"version":%d,"book":{"title":"Alterado","author":"Autor"}},
+					{"op":"delete","id":"missing","version":1}`, book.ID, book.Version)
+			},
+			wantStatus: http.StatusNotFound,
+			wantItems:  []int{http.StatusFailedDependency, http.StatusFailedDependency, http.StatusNotFound},
+			wantBooks:  1,
+			wantTitle:  "O Hobbit",
+		},
+		{
+			name: "atomic rejects an invalid operation before writing",
+			mode: batchModeAtomic,
+			operations: func(book *domain.Book) string {
+				return `{"op":"create","book":{"title":"Novo","author":"Autor"}},
+					{"op":"create","book":{"title":"Sem autor"}}`
+			},
+			wantStatus: http.StatusBadRequest,
+			wantItems:  []int{http.StatusFailedDependency, http.StatusBadRequest},
+			wantBooks:  1,
+			wantTitle:  "O Hobbit",
+		},
+		{
+			name: "partial keeps the successful operations",
+			mode: batchModePartial,
+			operations: func(book *domain.Book) string {
+				return fmt.Sprintf(`{"op":"create","book":{"title":"Novo","author":"Autor"}},
+					{"op":"update","id":%q,"version":%d,"book":{"title":"Alterado","author":"Autor"}},
+					{"op":"delete","id":"missing","version":1}`, book.ID, book.Version+1)
+			},
+			wantStatus: http.StatusMultiStatus,
+			wantItems:  []int{http.StatusCreated, http.StatusPreconditionFailed, http.StatusNotFound},
+			wantBooks:  2,
+			wantTitle:  "O Hobbit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, service := newBookRouter(t)
+			book := createBook(t, service)
+
+			body := fmt.Sprintf(`{"mode":%q,"operations":[%s]}`, tt.mode, tt.operations(book))
+			w := serve(router, http.MethodPost, "/api/books/batch", body, map[string]string{"Content-Type": "application/json"})
+
+			var response dto.BookBatchResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("decode %s: %v", w.Body, err)
+			}
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d %s, want %d", w.Code, w.Body, tt.wantStatus)
+			}
+			var items []int
+			for _, result := range response.Results {
+				items = append(items, result.Status)
+			}
+			if fmt.Sprint(items) != fmt.Sprint(tt.wantItems) {
+				t.Errorf("item statuses = %v, want %v", items, tt.wantItems)
+			}
+
+			books, err := service.ListBooks(context.Background(), 1, 10)
+			if err != nil {
+				t.Fatalf("ListBooks() error = %v", err)
+			}
+			if len(books) != tt.wantBooks {
+				t.Errorf("got %d books, want %d", len(books), tt.wantBooks)
+			}
+			if got, _ := service.GetBook(context.Background(), book.ID); got.Title != tt.wantTitle {
+				t.Errorf("title = %q, want %q", got.Title, tt.wantTitle)
+			}
+		})
+	}
+}
+
+func TestBatchBooksInvalidRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"malformed body", `{"operations":`},
+		{"unknown mode", `{"mode":"eventual","operations":[{"op":"delete","id":"x","version":1}]}`},
+		{"no operations", `{"operations":[]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _ := newBookRouter(t)
+
+			w := serve(router, http.MethodPost, "/api/books/batch", tt.body, map[string]string{"Content-Type": "application/json"})
+			if w.Code != http.StatusBadRequest || w.Header().Get("Content-Type") != problemContentType {
+				t.Errorf("response = %d %s, want a 400 problem", w.Code, w.Body)
+			}
+		})
+	}
+}
+
+func TestBatchBooksTooLarge(t *testing.T) {
+	router, service := newBookRouter(t)
+
+	// Um livro válido com uma descrição que passa do limite do corpo
+	description := strings.Repeat("a", maxBatchBodySize)
+	body := `{"operations":[{"op":"create","book":{"title":"Novo","author":"Autor","description":"` + description + `"}}]}`
+	w := serve(router, http.MethodPost, "/api/books/batch", body, map[string]string{"Content-Type": "application/json"})
+
+	var problem Problem
+	json.Unmarshal(w.Body.Bytes(), &problem)
+	if w.Code != http.StatusRequestEntityTooLarge || problem.Code != codePayloadTooLarge {
+		t.Errorf("response = %d %+v, want a 413 problem", w.Code, problem)
+	}
+	if books, _ := service.ListBooks(context.Background(), 1, 10); len(books) != 0 {
+		t.Errorf("got %d books, want none", len(books))
+	}
+}
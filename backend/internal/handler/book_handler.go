@@ -1,12 +1,16 @@
 package handler
 
 import (
+    "errors"
     "net/http"
     "strconv"
+    "time"
 
     "github.com/gin-gonic/gin"
 
     "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+    "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/handler/dto"
+    "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/metrics"
     "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/usecase"
 )
 
@@ -26,37 +30,85 @@ func NewBookHandler(bookService *usecase.BookService) *BookHandler {
 // @Tags         books
 // @Accept       json
 // @Produce      json
-// @Param        id   path      string  true  "Book ID"
+// @Param        id                 path      string  true   "Book ID"
+// @Param        If-None-Match      header    string  false  "ETag from a previous response"
+// @Param        If-Modified-Since  header    string  false  "Last-Modified from a previous response"
 // @Success      200  {object}  domain.Book
-// @Failure      404  {object}  handler.ErrorResponse
-// @Failure      500  {object}  handler.ErrorResponse
+// @Header       200  {string}  ETag           "Current version of the book"
+// @Header       200  {string}  Last-Modified  "Time of the last update to the book"
+// @Header       200  {string}  Cache-Control  "Caching policy: the book must be revalidated"
+// @Success      304  "Not modified"
+// @Failure      404  {object}  handler.Problem
+// @Failure      500  {object}  handler.Problem
 // @Router       /books/{id} [get]
 func (h *BookHandler) GetBook(c *gin.Context) {
     id := c.Param("id")
     
     book, err := h.bookService.GetBook(c.Request.Context(), id)
     if err != nil {
-        if err == domain.ErrBookNotFound {
-            c.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
-            return
-        }
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        respondError(c, err)
+        return
+    }
+
+    tag := etag(book.Version)
+    c.Header("ETag", tag)
+    c.Header("Cache-Control", bookCacheControl)
+    c.Header("Last-Modified", lastModified(book.UpdatedAt))
+    if notModified(c, tag) || notModifiedSince(c, book.UpdatedAt) {
+        c.Status(http.StatusNotModified)
         return
     }
     
     c.JSON(http.StatusOK, book)
 }
 
+// GetBooksByISBN godoc
+// @Summary      Find books by ISBN
+// @Description  Find the books with an ISBN, given as ISBN-10 or ISBN-13, with or without hyphens. Books are found regardless of the format their ISBN was entered in.
+// @Tags         books
+// @Produce      json
+// @Param        isbn  path      string  true  "ISBN-10 or ISBN-13"
+// @Success      200   {object}  dto.ISBNLookupResponse
+// @Failure      400   {object}  handler.Problem
+// @Failure      404   {object}  handler.Problem
+// @Failure      500   {object}  handler.Problem
+// @Router       /books/isbn/{isbn} [get]
+func (h *BookHandler) GetBooksByISBN(c *gin.Context) {
+    isbn, books, err := h.bookService.FindBooksByISBN(c.Request.Context(), c.Param("isbn"))
+    if err != nil {
+        respondError(c, err)
+        return
+    }
+
+    if len(books) == 0 {
+        respondError(c, domain.ErrBookNotFound)
+        return
+    }
+
+    isbn10, _ := isbn.ISBN10()
+    c.JSON(http.StatusOK, dto.ISBNLookupResponse{
+        ISBN13:     isbn.String(),
+        ISBN10:     isbn10,
+        Hyphenated: isbn.Hyphenated(),
+        Books:      books,
+    })
+}
+
 // ListBooks godoc
 // @Summary      List books
 // @Description  Get a paginated list of all books
 // @Tags         books
 // @Accept       json
 // @Produce      json
-// @Param        page       query     int  false  "Page number"       default(1)
-// @Param        page_size  query     int  false  "Items per page"    default(10)
+// @Param        page           query     int     false  "Page number"       default(1)
+// @Param        page_size      query     int     false  "Items per page"    default(10)
+// @Param        If-None-Match  header    string  false  "ETag from a previous response"
 // @Success      200        {array}   domain.Book
-// @Failure      500        {object}  handler.ErrorResponse
+// @Header       200        {string}  ETag           "Version of the page"
+// @Header       200        {string}  Last-Modified  "Time of the latest update among the books in the page"
+// @Header       200        {string}  Cache-Control  "Caching policy: the page must be revalidated"
+// @Success      304        "Not modified"
+// @Failure      500        {object}  handler.Problem
 // @Router       /books [get]
 func (h *BookHandler) ListBooks(c *gin.Context) {
     pageStr := c.DefaultQuery("page", "1")
@@ -74,7 +126,26 @@ func (h *BookHandler) ListBooks(c *gin.Context) {
     
     books, err := h.bookService.ListBooks(c.Request.Context(), page, pageSize)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        respondError(c, err)
+        return
+    }
+
+    tag := listETag(books)
+    c.Header("ETag", tag)
+    c.Header("Cache-Control", bookCacheControl)
+    var modified time.Time
+    for _, book := range books {
+        if book.UpdatedAt.After(modified) {
+            modified = book.UpdatedAt
+        }
+    }
+    if !modified.IsZero() {
+        c.Header("Last-Modified", lastModified(modified))
+    }
+    // Só a ETag valida a página: a remoção de um livro não muda o
+    // Last-Modified dos que continuam nela
+    if notModified(c, tag) {
+        c.Status(http.StatusNotModified)
         return
     }
     
@@ -87,109 +158,195 @@ func (h *BookHandler) ListBooks(c *gin.Context) {
 // @Tags         books
 // @Accept       json
 // @Produce      json
-// @Param        book  body      domain.Book  true  "Book information"
+// @Param        Idempotency-Key  header  string       false  "Key that makes retries of this request safe"
+// @Param        book             body    domain.Book  true   "Book information"
 // @Success      201   {object}  domain.Book
-// @Failure      400   {object}  handler.ErrorResponse
-// @Failure      500   {object}  handler.ErrorResponse
+// @Header       201   {string}  ETag  "Current version of the book"
+// @Failure      400   {object}  handler.Problem
+// @Failure      409   {object}  handler.Problem
+// @Failure      422   {object}  handler.Problem
+// @Failure      500   {object}  handler.Problem
 // @Router       /books [post]
 func (h *BookHandler) CreateBook(c *gin.Context) {
     var book domain.Book
     
-    if err := c.ShouldBindJSON(&book); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+    if !bindJSON(c, &book) {
         return
     }
     
     if err := h.bookService.CreateBook(c.Request.Context(), &book); err != nil {
-        if err == domain.ErrInvalidInput {
-            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-            return
-        }
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        respondError(c, err)
         return
     }
+    metrics.BooksCreatedTotal.WithLabelValues(metrics.SourceAPI).Inc()
     
+    c.Header("ETag", etag(book.Version))
     c.JSON(http.StatusCreated, book)
 }
 
 // UpdateBook godoc
 // @Summary      Update a book
-// @Description  Update an existing book by ID
+// @Description  Update an existing book by ID. Empty cover_url, status, publisher and publication_year and absent subjects keep the current values; use PATCH to clear them.
 // @Tags         books
 // @Accept       json
 // @Produce      json
-// @Param        id    path      string       true  "Book ID"
-// @Param        book  body      domain.Book  true  "Book information"
+// @Param        id        path      string       true  "Book ID"
+// @Param        If-Match  header    string       true  "ETag of the version being replaced"
+// @Param        book      body      domain.Book  true  "Book information"
 // @Success      200   {object}  domain.Book
-// @Failure      400   {object}  handler.ErrorResponse
-// @Failure      404   {object}  handler.ErrorResponse
-// @Failure      500   {object}  handler.ErrorResponse
+// @Header       200   {string}  ETag  "Current version of the book"
+// @Failure      400   {object}  handler.Problem
+// @Failure      404   {object}  handler.Problem
+// @Failure      412   {object}  handler.Problem
+// @Failure      428   {object}  handler.Problem
+// @Failure      500   {object}  handler.Problem
 // @Router       /books/{id} [put]
 func (h *BookHandler) UpdateBook(c *gin.Context) {
     id := c.Param("id")
+
+    version, ok := requireIfMatch(c)
+    if !ok {
+        return
+    }
     
     var book domain.Book
-    if err := c.ShouldBindJSON(&book); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+    if !bindJSON(c, &book) {
         return
     }
+    book.Version = version
     
     if err := h.bookService.UpdateBook(c.Request.Context(), id, &book); err != nil {
-        if err == domain.ErrBookNotFound {
-            c.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
-            return
-        }
-        if err == domain.ErrInvalidInput {
-            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-            return
-        }
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        respondError(c, err)
         return
     }
     
     // Busca o livro atualizado
     updatedBook, err := h.bookService.GetBook(c.Request.Context(), id)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        respondError(c, err)
         return
     }
     
+    c.Header("ETag", etag(updatedBook.Version))
     c.JSON(http.StatusOK, updatedBook)
 }
 
+// PatchBook godoc
+// @Summary      Patch a book
+// @Description  Partially update a book using JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902). Absent fields are kept and null clears a field.
+// @Tags         books
+// @Accept       application/merge-patch+json,application/json-patch+json
+// @Produce      json
+// @Param        id        path      string                 true  "Book ID"
+// @Param        If-Match  header    string                 true  "ETag of the version being patched"
+// @Param        patch     body      dto.BookPatchDocument  true  "Patch document"
+// @Success      200    {object}  domain.Book
+// @Header       200    {string}  ETag  "Current version of the book"
+// @Failure      400    {object}  handler.Problem
+// @Failure      404    {object}  handler.Problem
+// @Failure      412    {object}  handler.Problem
+// @Failure      415    {object}  handler.Problem
+// @Failure      428    {object}  handler.Problem
+// @Failure      500    {object}  handler.Problem
+// @Router       /books/{id} [patch]
+func (h *BookHandler) PatchBook(c *gin.Context) {
+    id := c.Param("id")
+
+    version, ok := requireIfMatch(c)
+    if !ok {
+        return
+    }
+
+    patch, err := readPatch(c)
+    if err != nil {
+        if errors.Is(err, errUnsupportedPatch) {
+            respondProblem(c, http.StatusUnsupportedMediaType, codeUnsupportedMediaType, err.Error())
+            return
+        }
+        respondError(c, err)
+        return
+    }
+
+    book, err := h.bookService.PatchBook(c.Request.Context(), id, version, func(book *domain.Book) error {
+        doc := dto.BookPatchDocument{
+            Title:           book.Title,
+            Author:          book.Author,
+            ISBN:            book.ISBN,
+            Description:     book.Description,
+            CoverURL:        book.CoverURL,
+            Publisher:       book.Publisher,
+            PublicationYear: book.PublicationYear,
+            Subjects:        book.Subjects,
+            Status:          book.Status,
+        }
+        if err := patchDocument(patch, &doc); err != nil {
+            return err
+        }
+
+        book.Title = doc.Title
+        book.Author = doc.Author
+        book.ISBN = doc.ISBN
+        book.Description = doc.Description
+        book.CoverURL = doc.CoverURL
+        book.Publisher = doc.Publisher
+        book.PublicationYear = doc.PublicationYear
+        book.SetSubjects(doc.Subjects)
+        book.Status = doc.Status
+        return nil
+    })
+    if err != nil {
+        respondError(c, err)
+        return
+    }
+
+    c.Header("ETag", etag(book.Version))
+    c.JSON(http.StatusOK, book)
+}
+
 // DeleteBook godoc
 // @Summary      Delete a book
 // @Description  Remove a book by ID
 // @Tags         books
 // @Accept       json
 // @Produce      json
-// @Param        id   path      string  true  "Book ID"
+// @Param        id        path      string  true  "Book ID"
+// @Param        If-Match  header    string  true  "ETag of the version being deleted"
 // @Success      204  {object}  nil
-// @Failure      404  {object}  handler.ErrorResponse
-// @Failure      500  {object}  handler.ErrorResponse
+// @Failure      404  {object}  handler.Problem
+// @Failure      412  {object}  handler.Problem
+// @Failure      428  {object}  handler.Problem
+// @Failure      500  {object}  handler.Problem
 // @Router       /books/{id} [delete]
 func (h *BookHandler) DeleteBook(c *gin.Context) {
     id := c.Param("id")
+
+    version, ok := requireIfMatch(c)
+    if !ok {
+        return
+    }
     
-    if err := h.bookService.DeleteBook(c.Request.Context(), id); err != nil {
-        if err == domain.ErrBookNotFound {
-            c.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
-            return
-        }
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    if err := h.bookService.DeleteBook(c.Request.Context(), id, version); err != nil {
+        respondError(c, err)
         return
     }
     
     c.Status(http.StatusNoContent)
 }
 
-func (h *BookHandler) RegisterRoutes(router *gin.RouterGroup) {
+// RegisterRoutes registra as rotas de livros. createMiddleware é aplicado
+// somente à criação (ex.: idempotência).
+func (h *BookHandler) RegisterRoutes(router *gin.RouterGroup, createMiddleware ...gin.HandlerFunc) {
     books := router.Group("/books")
     {
         books.GET("/:id", h.GetBook)
         books.GET("", h.ListBooks)
-        books.POST("", h.CreateBook)
+        books.POST("", append(createMiddleware, h.CreateBook)...)
+        books.POST("/batch", append(createMiddleware, h.BatchBooks)...)
+        books.POST("/import", h.ImportBooks)
+        books.GET("/export", h.ExportBooks)
+        books.GET("/isbn/:isbn", h.GetBooksByISBN)
         books.PUT("/:id", h.UpdateBook)
+        books.PATCH("/:id", h.PatchBook)
         books.DELETE("/:id", h.DeleteBook)
     }
 }
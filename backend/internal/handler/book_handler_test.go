@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository/memory"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/usecase"
+)
+
+// newBookRouter cria as rotas de livros sobre repositórios em memória
+func newBookRouter(t *testing.T) (*gin.Engine, *usecase.BookService) {
+	t.Helper()
+	repo := memory.NewBookRepository()
+	service := usecase.NewBookService(repo, memory.NewTxManager(repo), nil)
+
+	router := gin.New()
+	NewBookHandler(service).RegisterRoutes(router.Group("/api"))
+	return router, service
+}
+
+// createBook grava um livro pelo serviço, sem passar pelo handler
+func createBook(t *testing.T, service *usecase.BookService) *domain.Book {
+	t.Helper()
+	book := &domain.Book{
+		Title:           "O Hobbit",
+		Author:          "J.R.R. Tolkien",
+		Publisher:       "HarperCollins",
+		PublicationYear: 2019,
+		Subjects:        domain.StringList{"Fantasia"},
+	}
+	if err := service.CreateBook(context.Background(), book); err != nil {
+		t.Fatalf("CreateBook() error = %v", err)
+	}
+	return book
+}
+
+func serve(router *gin.Engine, method, target, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func decodeBook(t *testing.T, w *httptest.ResponseRecorder) domain.Book {
+	t.Helper()
+	var book domain.Book
+	if err := json.Unmarshal(w.Body.Bytes(), &book); err != nil {
+		t.Fatalf("decode %s: %v", w.Body, err)
+	}
+	return book
+}
+
+func TestPatchBook(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		check       func(t *testing.T, book domain.Book)
+	}{
+		{
+			name:        "merge patch keeps absent fields",
+			contentType: mergePatchContentType,
+			body:        `{"title":"The Hobbit"}`,
+			check: func(t *testing.T, book domain.Book) {
+				if book.Title != "The Hobbit" || book.Author != "J.R.R. Tolkien" || book.Publisher != "HarperCollins" {
+					t.Errorf("book = %+v, want only the title changed", book)
+				}
+			},
+		},
+		{
+			name:        "merge patch null clears",
+			contentType: mergePatchContentType,
+			body:        `{"publisher":null,"publication_year":null,"subjects":null}`,
+			check: func(t *testing.T, book domain.Book) {
+				if book.Publisher != "" || book.PublicationYear != 0 || len(book.Subjects) != 0 || book.Title != "O Hobbit" {
+					t.Errorf("book = %+v, want publisher, year and subjects cleared", book)
+				}
+			},
+		},
+		{
+			name:        "application/json is a merge patch",
+			contentType: "application/json",
+			body:        `{"status":"borrowed"}`,
+			check: func(t *testing.T, book domain.Book) {
+				if book.Status != domain.StatusBorrowed {
+					t.Errorf("status = %q, want %q", book.Status, domain.StatusBorrowed)
+				}
+			},
+		},
+		{
+			name:        "json patch",
+			contentType: jsonPatchContentType,
+			body:        `[{"op":"add","path":"/subjects/-","value":"Aventura"},{"op":"remove","path":"/publisher"}]`,
+			check: func(t *testing.T, book domain.Book) {
+				if len(book.Subjects) != 2 || book.Subjects[1] != "Aventura" || book.Publisher != "" {
+					t.Errorf("book = %+v, want the subject added and the publisher removed", book)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, service := newBookRouter(t)
+			book := createBook(t, service)
+
+			w := serve(router, http.MethodPatch, "/api/books/"+book.ID, tt.body,
+				map[string]string{"Content-Type": tt.contentType, "If-Match": etag(book.Version)})
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d %s, want 200", w.Code, w.Body)
+			}
+			if got := w.Header().Get("ETag"); got != etag(book.Version+1) {
+				t.Errorf("ETag = %s, want %s", got, etag(book.Version+1))
+			}
+			tt.check(t, decodeBook(t, w))
+		})
+	}
+}
+
+func TestPatchBookErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantCode    domain.Code
+	}{
+		{"unsupported media type", "text/plain", `{"title":"x"}`, http.StatusUnsupportedMediaType, codeUnsupportedMediaType},
+		{"merge patch not an object", mergePatchContentType, `["title"]`, http.StatusBadRequest, domain.CodeValidationFailed},
+		{"unknown field", mergePatchContentType, `{"pages":300}`, http.StatusBadRequest, domain.CodeValidationFailed},
+		{"wrong type", mergePatchContentType, `{"publication_year":"2019"}`, http.StatusBadRequest, domain.CodeValidationFailed},
+		{"invalid result", mergePatchContentType, `{"title":null}`, http.StatusBadRequest, domain.CodeValidationFailed},
+		{"failed json patch test", jsonPatchContentType, `[{"op":"test","path":"/title","value":"x"}]`, http.StatusBadRequest, domain.CodeValidationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, service := newBookRouter(t)
+			book := createBook(t, service)
+
+			w := serve(router, http.MethodPatch, "/api/books/"+book.ID, tt.body,
+				map[string]string{"Content-Type": tt.contentType, "If-Match": etag(book.Version)})
+			var problem Problem
+			json.Unmarshal(w.Body.Bytes(), &problem)
+			if w.Code != tt.wantStatus || problem.Code != tt.wantCode {
+				t.Errorf("response = %d %s, want %d %s", w.Code, w.Body, tt.wantStatus, tt.wantCode)
+			}
+
+			if got, _ := service.GetBook(context.Background(), book.ID); got.Version != book.Version {
+				t.Errorf("version = %d, want the book unchanged", got.Version)
+			}
+		})
+	}
+}
+
+func TestBookPreconditions(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		ifMatch    func(book *domain.Book) string
+		wantStatus int
+		wantCode   domain.Code
+	}{
+		{"put without If-Match", http.MethodPut, `{"title":"x","author":"y"}`, func(*domain.Book) string { return "" }, http.StatusPreconditionRequired, codePreconditionRequired},
+		{"patch without If-Match", http.MethodPatch, `{"title":"x"}`, func(*domain.Book) string { return "" }, http.StatusPreconditionRequired, codePreconditionRequired},
+		{"delete without If-Match", http.MethodDelete, "", func(*domain.Book) string { return "" }, http.StatusPreconditionRequired, codePreconditionRequired},
+		{"malformed If-Match", http.MethodPut, `{"title":"x","author":"y"}`, func(*domain.Book) string { return "1" }, http.StatusBadRequest, codeInvalidPrecondition},
+		{"weak If-Match", http.MethodDelete, "", func(b *domain.Book) string { return "W/" + etag(b.Version) }, http.StatusBadRequest, codeInvalidPrecondition},
+		{"stale put", http.MethodPut, `{"title":"x","author":"y"}`, func(b *domain.Book) string { return etag(b.Version + 1) }, http.StatusPreconditionFailed, domain.CodeVersionConflict},
+		{"stale patch", http.MethodPatch, `{"title":"x"}`, func(b *domain.Book) string { return etag(b.Version + 1) }, http.StatusPreconditionFailed, domain.CodeVersionConflict},
+		{"stale delete", http.MethodDelete, "", func(b *domain.Book) string { return etag(b.Version + 1) }, http.StatusPreconditionFailed, domain.CodeVersionConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, service := newBookRouter(t)
+			book := createBook(t, service)
+
+			headers := map[string]string{"Content-Type": "application/json"}
+			if tag := tt.ifMatch(book); tag != "" {
+				headers["If-Match"] = tag
+			}
+			w := serve(router, tt.method, "/api/books/"+book.ID, tt.body, headers)
+			var problem Problem
+			json.Unmarshal(w.Body.Bytes(), &problem)
+			if w.Code != tt.wantStatus || problem.Code != tt.wantCode {
+				t.Errorf("response = %d %s, want %d %s", w.Code, w.Body, tt.wantStatus, tt.wantCode)
+			}
+
+			if got, err := service.GetBook(context.Background(), book.ID); err != nil || got.Version != book.Version {
+				t.Errorf("GetBook() = %+v, %v; want the book unchanged", got, err)
+			}
+		})
+	}
+}
+
+func TestBookConditionalWrites(t *testing.T) {
+	router, service := newBookRouter(t)
+	book := createBook(t, service)
+
+	w := serve(router, http.MethodPut, "/api/books/"+book.ID, `{"title":"The Hobbit","author":"J.R.R. Tolkien"}`,
+		map[string]string{"Content-Type": "application/json", "If-Match": etag(book.Version)})
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != etag(book.Version+1) {
+		t.Fatalf("PUT = %d %s, ETag %s; want 200 with the next version", w.Code, w.Body, w.Header().Get("ETag"))
+	}
+
+	// A ETag anterior está desatualizada; * aceita qualquer versão
+	w = serve(router, http.MethodPatch, "/api/books/"+book.ID, `{"title":"x"}`,
+		map[string]string{"Content-Type": mergePatchContentType, "If-Match": etag(book.Version)})
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("PATCH with the old ETag = %d, want 412", w.Code)
+	}
+	w = serve(router, http.MethodPatch, "/api/books/"+book.ID, `{"title":"Lá e de volta outra vez"}`,
+		map[string]string{"Content-Type": mergePatchContentType, "If-Match": "*"})
+	if w.Code != http.StatusOK || decodeBook(t, w).Title != "Lá e de volta outra vez" {
+		t.Errorf("PATCH with * = %d %s, want 200", w.Code, w.Body)
+	}
+
+	w = serve(router, http.MethodDelete, "/api/books/"+book.ID, "", map[string]string{"If-Match": etag(book.Version + 2)})
+	if w.Code != http.StatusNoContent {
+		t.Errorf("DELETE = %d %s, want 204", w.Code, w.Body)
+	}
+	w = serve(router, http.MethodDelete, "/api/books/"+book.ID, "", map[string]string{"If-Match": "*"})
+	if w.Code != http.StatusNotFound {
+		t.Errorf("DELETE of a removed book = %d, want 404", w.Code)
+	}
+}
+
+func TestGetBookIfNoneMatch(t *testing.T) {
+	router, service := newBookRouter(t)
+	book := createBook(t, service)
+
+	tests := []struct {
+		header     string
+		wantStatus int
+	}{
+		{"", http.StatusOK},
+		{etag(book.Version), http.StatusNotModified},
+		{"W/" + etag(book.Version), http.StatusNotModified},
+		{`"7", ` + etag(book.Version), http.StatusNotModified},
+		{encodedETag(etag(book.Version), "gzip"), http.StatusNotModified},
+		{"*", http.StatusNotModified},
+		{etag(book.Version + 1), http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		w := serve(router, http.MethodGet, "/api/books/"+book.ID, "", map[string]string{"If-None-Match": tt.header})
+		if w.Code != tt.wantStatus || w.Header().Get("ETag") != etag(book.Version) {
+			t.Errorf("GET with If-None-Match %q = %d, ETag %s; want %d, %s",
+				tt.header, w.Code, w.Header().Get("ETag"), tt.wantStatus, etag(book.Version))
+		}
+		if tt.wantStatus == http.StatusNotModified && w.Body.Len() != 0 {
+			t.Errorf("GET with If-None-Match %q body = %s, want empty", tt.header, w.Body)
+		}
+	}
+}
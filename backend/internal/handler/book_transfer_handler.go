@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/codec/bookcsv"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/codec/marc"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/logging"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/metrics"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/usecase"
+)
+
+const (
+	formatCSV     = "csv"
+	formatMARC21  = "marc21"
+	formatMARCXML = "marcxml"
+
+	maxImportBodySize = 512 << 20
+)
+
+var errUnsupportedFormat = domain.NewValidationError("format", "format must be csv, marc21 or marcxml")
+
+// ImportProblem é o problema de uma importação interrompida. Os lotes gravados
+// antes do erro permanecem no catálogo e aparecem no relatório.
+type ImportProblem struct {
+	Problem
+	Report *usecase.ImportReport `json:"report"`
+}
+
+// ImportBooks godoc
+// @Summary      Import books
+// @Description  Import books from a file sent as the request body or as the "file" field of a multipart form. Supported formats are CSV, MARC 21 (ISO 2709, UTF-8) and MARCXML. For CSV the header row is matched against known column names (title/titulo, author/autor, isbn, description/descricao, cover_url/capa, status, publisher/editora, publication_year/ano, subjects/assuntos); use map[<column>]=<field> to map other columns. MARC records are read from fields 020, 100, 245, 260/264, 520 and 650. Invalid records and ISBNs already in the file or in the catalog are skipped and reported. Books are saved in batches of 500, so if the import fails midway, the batches already saved are kept and the error response carries the partial report. With dry_run=true nothing is saved.
+// @Tags         books
+// @Accept       text/csv,application/marc,application/marcxml+xml,mpfd
+// @Produce      json
+// @Param        format   query     string  false  "File format"  Enums(csv, marc21, marcxml)  default(csv)
+// @Param        dry_run  query     bool    false  "Only validate the file"
+// @Param        map      query     object  false  "Column to field mapping, e.g. map[Nome]=title"
+// @Param        file     formData  file    false  "File to import (multipart)"
+// @Success      200  {object}  usecase.ImportReport
+// @Failure      400  {object}  handler.Problem
+// @Failure      413  {object}  handler.ImportProblem
+// @Failure      500  {object}  handler.ImportProblem
+// @Router       /books/import [post]
+func (h *BookHandler) ImportBooks(c *gin.Context) {
+	format := c.DefaultQuery("format", formatCSV)
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodySize)
+
+	source, err := fileSource(c)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, codeMalformedRequest, err.Error())
+		return
+	}
+
+	var reader usecase.BookReader
+	switch format {
+	case formatCSV:
+		reader, err = bookcsv.NewReader(source, c.QueryMap("map"))
+	case formatMARC21:
+		reader = marc.NewReader(source)
+	case formatMARCXML:
+		reader = marc.NewXMLReader(source)
+	default:
+		respondError(c, errUnsupportedFormat)
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	report, err := h.bookService.ImportBooks(c.Request.Context(), reader, usecase.ImportOptions{DryRun: dryRun})
+	metrics.BooksCreatedTotal.WithLabelValues(metrics.SourceImport).Add(float64(report.Imported))
+	if err != nil {
+		respondImportError(c, err, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// respondImportError responde com o problema de err e o relatório do que a
+// importação já tinha gravado
+func respondImportError(c *gin.Context, err error, report *usecase.ImportReport) {
+	problem := errorProblem(err)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		problem = Problem{Status: http.StatusRequestEntityTooLarge, Code: codePayloadTooLarge, Detail: "file too large"}
+	}
+	if problem.Status >= http.StatusInternalServerError {
+		c.Error(err)
+	}
+
+	fillProblem(c, &problem)
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(problem.Status, ImportProblem{Problem: problem, Report: report})
+}
+
+// ExportBooks godoc
+// @Summary      Export books
+// @Description  Stream the catalog as a file. Accepts the same pagination as the book listing; without page the whole catalog is exported.
+// @Tags         books
+// @Produce      text/csv,application/marc,application/marcxml+xml
+// @Param        format     query     string  false  "File format"  Enums(csv, marc21, marcxml)  default(csv)
+// @Param        page       query     int     false  "Page number"
+// @Param        page_size  query     int     false  "Items per page"  default(10)
+// @Success      200  {file}    file
+// @Failure      400  {object}  handler.Problem
+// @Router       /books/export [get]
+func (h *BookHandler) ExportBooks(c *gin.Context) {
+	format := c.DefaultQuery("format", formatCSV)
+
+	page, _ := strconv.Atoi(c.Query("page"))
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if err != nil {
+		pageSize = 10
+	}
+
+	var writer usecase.BookWriter
+	switch format {
+	case formatCSV:
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="books.csv"`)
+		writer = bookcsv.NewWriter(c.Writer)
+	case formatMARC21:
+		c.Header("Content-Type", "application/marc")
+		c.Header("Content-Disposition", `attachment; filename="books.mrc"`)
+		writer = marc.NewWriter(c.Writer)
+	case formatMARCXML:
+		c.Header("Content-Type", "application/marcxml+xml; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="books.xml"`)
+		writer = marc.NewXMLWriter(c.Writer)
+	default:
+		respondError(c, errUnsupportedFormat)
+		return
+	}
+
+	c.Status(http.StatusOK)
+	if err := h.bookService.ExportBooks(c.Request.Context(), writer, page, pageSize); err != nil {
+		// O cabeçalho já foi enviado; só resta interromper a resposta
+		logging.FromContext(c.Request.Context()).Error("Failed to export books", "error", err)
+		c.Abort()
+	}
+}
+
+// fileSource devolve o arquivo enviado: o campo "file" de um formulário
+// multipart, lido em fluxo, ou o próprio corpo da requisição
+func fileSource(c *gin.Context) (io.Reader, error) {
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return c.Request.Body, nil
+	}
+
+	parts, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		part, err := parts.NextPart()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New(`multipart form has no "file" field`)
+			}
+			return nil, err
+		}
+		if part.FormName() == "file" {
+			return part, nil
+		}
+	}
+}
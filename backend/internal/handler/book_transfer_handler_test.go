@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository/memory"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/usecase"
+)
+
+// failingRepository falha a partir da chamada failAt de CreateMany
+type failingRepository struct {
+	repository.BookRepository
+	calls  int
+	failAt int
+}
+
+func (r *failingRepository) CreateMany(ctx context.Context, books []*domain.Book) error {
+	r.calls++
+	if r.calls >= r.failAt {
+		return errors.New("connection reset")
+	}
+	return r.BookRepository.CreateMany(ctx, books)
+}
+
+func TestImportBooksPartialReport(t *testing.T) {
+	books := memory.NewBookRepository()
+	repo := &failingRepository{BookRepository: books, failAt: 2}
+	service := usecase.NewBookService(repo, memory.NewTxManager(books), nil)
+
+	router := gin.New()
+	NewBookHandler(service).RegisterRoutes(router.Group("/api"))
+
+	// Um lote completo e o início do segundo, que falha
+	var csv strings.Builder
+	csv.WriteString("title,author\n")
+	for i := range 600 {
+		fmt.Fprintf(&csv, "Livro %d,Autor\n", i)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/books/import", strings.NewReader(csv.String()))
+	req.Header.Set("Content-Type", "text/csv")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var problem ImportProblem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decode %s: %v", w.Body, err)
+	}
+	if w.Code != http.StatusInternalServerError || problem.Code != codeInternal || strings.Contains(w.Body.String(), "connection reset") {
+		t.Errorf("response = %d %s, want an internal error without the cause", w.Code, w.Body)
+	}
+	if problem.Report == nil || problem.Report.Total != 600 || problem.Report.Imported != 500 {
+		t.Fatalf("report = %+v, want the 500 books of the first batch", problem.Report)
+	}
+	if stored, _ := books.FindAll(context.Background(), -1, 0); len(stored) != 500 {
+		t.Errorf("%d books stored, want the first batch", len(stored))
+	}
+}
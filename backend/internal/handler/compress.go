@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// Codificações oferecidas, da preferida para a menos preferida quando o
+// cliente aceita mais de uma com o mesmo peso
+var encodings = []string{"br", "gzip"}
+
+// compressor é a parte comum de *gzip.Writer e *brotli.Writer
+type compressor interface {
+	io.Writer
+	Flush() error
+	Close() error
+	Reset(w io.Writer)
+}
+
+// Compressores reaproveitados entre as respostas. O brotli usa um nível
+// baixo, que comprime quase tanto quanto o gzip padrão e bem mais rápido que
+// os níveis altos, feitos para arquivos estáticos.
+var compressorPools = map[string]*sync.Pool{
+	"br": {New: func() any {
+		return brotli.NewWriterLevel(io.Discard, 4)
+	}},
+	"gzip": {New: func() any {
+		return gzip.NewWriter(io.Discard)
+	}},
+}
+
+// CompressionMiddleware comprime com brotli ou gzip, conforme o
+// Accept-Encoding, as respostas de texto (JSON, CSV, HTML...) a partir de
+// minSize bytes. Respostas menores saem sem compressão, já que o ganho não
+// compensa o custo; imagens e respostas já codificadas, como as do /metrics,
+// também. A ETag forte de uma resposta comprimida recebe o sufixo da
+// codificação ("3-gzip").
+func CompressionMiddleware(minSize int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// A resposta depende do Accept-Encoding mesmo quando não é comprimida
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" || c.Request.Method == http.MethodHead || c.GetHeader("Range") != "" {
+			c.Next()
+			return
+		}
+
+		writer := &compressWriter{ResponseWriter: c.Writer, encoding: encoding, minSize: minSize, ifNoneMatch: c.GetHeader("If-None-Match")}
+		c.Writer = writer
+		defer func() {
+			writer.finish()
+			c.Writer = writer.ResponseWriter
+		}()
+
+		c.Next()
+	}
+}
+
+// negotiateEncoding escolhe a codificação da resposta pelos pesos (q) do
+// Accept-Encoding; vazio quando o cliente não aceita nenhuma das oferecidas
+func negotiateEncoding(header string) string {
+	if header == "" {
+		return ""
+	}
+
+	weights := make(map[string]float64)
+	wildcard := 0.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		weight := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				parsed, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					parsed = 0
+				}
+				weight = parsed
+			}
+		}
+
+		if name == "*" {
+			wildcard = weight
+		} else {
+			weights[name] = weight
+		}
+	}
+
+	best, bestWeight := "", 0.0
+	for _, encoding := range encodings {
+		weight, ok := weights[encoding]
+		if !ok {
+			weight = wildcard
+		}
+		if weight > bestWeight {
+			best, bestWeight = encoding, weight
+		}
+	}
+
+	return best
+}
+
+// compressible informa se vale comprimir o tipo de conteúdo: texto, JSON,
+// XML e JavaScript; imagens e arquivos compactados não diminuem
+func compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return strings.HasPrefix(mediaType, "text/") ||
+		mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") ||
+		mediaType == "application/xml" || strings.HasSuffix(mediaType, "+xml") ||
+		mediaType == "application/javascript"
+}
+
+// compressWriter guarda o início da resposta até saber se ela passa de
+// minSize bytes e, então, passa a comprimi-la
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding    string
+	minSize     int
+	ifNoneMatch string
+
+	// decided fica true quando a resposta começa a sair, comprimida (com
+	// compressor) ou não
+	decided    bool
+	buf        []byte
+	compressor compressor
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		if !w.eligible() {
+			w.decided = true
+			return w.ResponseWriter.Write(data)
+		}
+
+		w.buf = append(w.buf, data...)
+		if len(w.buf) < w.minSize {
+			return len(data), nil
+		}
+		if err := w.start(); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+
+	if w.compressor != nil {
+		return w.compressor.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeader devolve, em um 304, a ETag da representação comprimida que o
+// cliente guardou, para que os caches a atualizem
+func (w *compressWriter) WriteHeader(code int) {
+	if code == http.StatusNotModified {
+		if tag := w.Header().Get("ETag"); tag != "" && strings.Contains(w.ifNoneMatch, encodedETag(tag, w.encoding)) {
+			w.Header().Set("ETag", encodedETag(tag, w.encoding))
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Written considera escrita a resposta guardada, para que os middlewares não
+// tentem escrever outra por cima
+func (w *compressWriter) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+// Flush envia o que já foi escrito. Uma resposta enviada aos poucos, como a
+// exportação, é comprimida mesmo que o primeiro trecho seja pequeno.
+func (w *compressWriter) Flush() {
+	if !w.decided && len(w.buf) > 0 {
+		if err := w.start(); err != nil {
+			return
+		}
+	}
+	if w.compressor != nil {
+		w.compressor.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// eligible decide, pelos cabeçalhos já definidos, se a resposta pode ser
+// comprimida
+func (w *compressWriter) eligible() bool {
+	// Cabeçalhos já enviados não podem mais anunciar a compressão
+	if w.ResponseWriter.Written() {
+		return false
+	}
+
+	status := w.Status()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+
+	header := w.Header()
+	return header.Get("Content-Encoding") == "" && compressible(header.Get("Content-Type"))
+}
+
+// start envia os cabeçalhos da resposta comprimida e o que estava guardado
+func (w *compressWriter) start() error {
+	w.decided = true
+
+	header := w.Header()
+	header.Set("Content-Encoding", w.encoding)
+	if tag := header.Get("ETag"); tag != "" {
+		header.Set("ETag", encodedETag(tag, w.encoding))
+	}
+	// O tamanho informado pelo handler é o do conteúdo sem compressão
+	header.Del("Content-Length")
+
+	w.compressor = compressorPools[w.encoding].Get().(compressor)
+	w.compressor.Reset(w.ResponseWriter)
+
+	buf := w.buf
+	w.buf = nil
+	_, err := w.compressor.Write(buf)
+	return err
+}
+
+// finish termina a resposta: envia sem compressão a que não chegou a
+// minSize ou fecha o compressor
+func (w *compressWriter) finish() {
+	if !w.decided && len(w.buf) > 0 {
+		w.decided = true
+		buf := w.buf
+		w.buf = nil
+		w.ResponseWriter.Write(buf)
+		return
+	}
+
+	if w.compressor != nil {
+		w.compressor.Close()
+		w.compressor.Reset(io.Discard)
+		compressorPools[w.encoding].Put(w.compressor)
+		w.compressor = nil
+	}
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", "gzip"},
+		{"GZIP", "gzip"},
+		{"br", "br"},
+		{"gzip, deflate, br", "br"},
+		{"br;q=0.5, gzip", "gzip"},
+		{"br; q=0.8, gzip;q=0.9", "gzip"},
+		{"gzip;q=0", ""},
+		{"gzip;q=invalid", ""},
+		{"*", "br"},
+		{"*;q=0", ""},
+		{"br;q=0, *", "gzip"},
+		{"gzip;q=0.2, *;q=0.5", "br"},
+		{"br;q=0, gzip;q=0, *", ""},
+	}
+
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.header); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+// newCompressedRouter cria as rotas usadas pelos testes de compressão, com
+// minSize de 100 bytes
+func newCompressedRouter() *gin.Engine {
+	router := gin.New()
+	router.Use(CompressionMiddleware(100))
+
+	large := strings.Repeat("livro ", 50)
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		router.Handle(method, "/large", func(c *gin.Context) {
+			c.Header("ETag", etag(3))
+			c.String(http.StatusOK, large)
+		})
+	}
+	router.GET("/small", func(c *gin.Context) {
+		c.Header("ETag", etag(3))
+		c.String(http.StatusOK, "livro")
+	})
+	router.GET("/image", func(c *gin.Context) {
+		c.Data(http.StatusOK, "image/png", []byte(large))
+	})
+	router.GET("/stream", func(c *gin.Context) {
+		c.Header("Content-Type", "text/csv")
+		c.Status(http.StatusOK)
+		c.Writer.WriteString("title\n")
+		c.Writer.Flush()
+		c.Writer.WriteString("Livro\n")
+	})
+	router.GET("/book", func(c *gin.Context) {
+		tag := etag(3)
+		c.Header("ETag", tag)
+		if notModified(c, tag) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+		c.String(http.StatusOK, large)
+	})
+	return router
+}
+
+func decompress(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	var reader io.Reader = w.Body
+	switch w.Header().Get("Content-Encoding") {
+	case "gzip":
+		gz, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatalf("gzip.NewReader() error = %v", err)
+		}
+		reader = gz
+	case "br":
+		reader = brotli.NewReader(w.Body)
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return string(body)
+}
+
+func TestCompressionMiddleware(t *testing.T) {
+	router := newCompressedRouter()
+	large := strings.Repeat("livro ", 50)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		acceptEncoding string
+		wantEncoding   string
+		wantETag       string
+		wantBody       string
+	}{
+		{"gzip", http.MethodGet, "/large", "gzip", "gzip", `"3-gzip"`, large},
+		{"brotli", http.MethodGet, "/large", "gzip, br", "br", `"3-br"`, large},
+		{"not accepted", http.MethodGet, "/large", "", "", `"3"`, large},
+		{"below minSize", http.MethodGet, "/small", "gzip", "", `"3"`, "livro"},
+		{"image", http.MethodGet, "/image", "gzip", "", "", large},
+		{"flushed stream", http.MethodGet, "/stream", "gzip", "gzip", "", "title\nLivro\n"},
+		{"head", http.MethodHead, "/large", "gzip", "", `"3"`, large},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			if got := w.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+			if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+				t.Errorf("Vary = %q, want Accept-Encoding", got)
+			}
+			if tt.wantEncoding != "" && w.Header().Get("Content-Length") != "" {
+				t.Errorf("Content-Length = %q on a compressed response", w.Header().Get("Content-Length"))
+			}
+			if got := decompress(t, w); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestCompressionMiddlewareNotModified(t *testing.T) {
+	router := newCompressedRouter()
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		wantETag    string
+	}{
+		{"compressed tag", `"3-gzip"`, `"3-gzip"`},
+		{"weak compressed tag", `W/"3-gzip"`, `"3-gzip"`},
+		{"identity tag", `"3"`, `"3"`},
+		{"tag of another encoding", `"3-br"`, `"3"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/book", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+				t.Fatalf("response = %d %q, want an empty 304", w.Code, w.Body)
+			}
+			if got := w.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+			if got := w.Header().Get("Content-Encoding"); got != "" {
+				t.Errorf("Content-Encoding = %q on a 304", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/usecase"
+)
+
+// As capas são imutáveis: uma imagem nova recebe outro nome
+const coverCacheControl = "public, max-age=31536000, immutable"
+
+type CoverHandler struct {
+	coverService *usecase.CoverService
+}
+
+func NewCoverHandler(coverService *usecase.CoverService) *CoverHandler {
+	return &CoverHandler{
+		coverService: coverService,
+	}
+}
+
+// UploadCover godoc
+// @Summary      Upload a book cover
+// @Description  Store a cover image for the book and point its cover_url to it. The image is sent as the request body or as the "file" field of a multipart form; its type is detected from the content and must be JPEG, PNG, GIF or WebP. A previously uploaded cover is removed.
+// @Tags         books
+// @Accept       image/jpeg,image/png,image/gif,image/webp,mpfd
+// @Produce      json
+// @Param        id        path      string  true   "Book ID"
+// @Param        If-Match  header    string  true   "ETag of the version being updated"
+// @Param        file      formData  file    false  "Cover image (multipart)"
+// @Success      200  {object}  domain.Book
+// @Failure      400  {object}  handler.Problem
+// @Failure      404  {object}  handler.Problem
+// @Failure      412  {object}  handler.Problem
+// @Failure      413  {object}  handler.Problem
+// @Failure      415  {object}  handler.Problem
+// @Failure      428  {object}  handler.Problem
+// @Failure      500  {object}  handler.Problem
+// @Router       /books/{id}/cover [put]
+func (h *CoverHandler) UploadCover(c *gin.Context) {
+	id := c.Param("id")
+
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	source, err := fileSource(c)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, codeMalformedRequest, err.Error())
+		return
+	}
+
+	book, err := h.coverService.UploadCover(c.Request.Context(), id, version, source)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("ETag", etag(book.Version))
+	c.JSON(http.StatusOK, book)
+}
+
+// GetCover godoc
+// @Summary      Get an uploaded cover
+// @Description  Serve a cover image stored by the upload endpoint. Covers never change once stored, so responses can be cached indefinitely.
+// @Tags         books
+// @Produce      image/jpeg,image/png,image/gif,image/webp
+// @Param        name  path      string  true  "Cover file name, as in the book's cover_url"
+// @Success      200  {file}    file
+// @Success      304  {object}  nil
+// @Failure      404  {object}  handler.Problem
+// @Failure      500  {object}  handler.Problem
+// @Router       /covers/{name} [get]
+func (h *CoverHandler) GetCover(c *gin.Context) {
+	name := c.Param("name")
+
+	// O nome já identifica o conteúdo, então serve como ETag
+	current := `"` + name + `"`
+	if notModified(c, current) {
+		c.Header("ETag", current)
+		c.Header("Cache-Control", coverCacheControl)
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	blob, err := h.coverService.GetCover(c.Request.Context(), name)
+	if err != nil {
+		// Um nome inválido também não corresponde a nenhuma capa
+		if errors.Is(err, domain.ErrBlobNotFound) || errors.Is(err, domain.ErrInvalidInput) {
+			respondProblem(c, http.StatusNotFound, codeCoverNotFound, "cover not found")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+	defer blob.Body.Close()
+
+	c.DataFromReader(http.StatusOK, blob.Size, blob.ContentType, blob.Body, map[string]string{
+		"ETag":                   current,
+		"Cache-Control":          coverCacheControl,
+		"Last-Modified":          blob.ModTime.UTC().Format(http.TimeFormat),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+func (h *CoverHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.PUT("/books/:id/cover", h.UploadCover)
+	router.GET("/covers/:name", h.GetCover)
+}
@@ -0,0 +1,72 @@
+package dto
+
+import "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+
+// BookPatchDocument é a representação do livro sobre a qual os patches
+// (RFC 7396 e RFC 6902) são aplicados
+type BookPatchDocument struct {
+	Title           string   `json:"title" example:"O Senhor dos Anéis"`
+	Author          string   `json:"author" example:"J.R.R. Tolkien"`
+	ISBN            string   `json:"isbn" example:"9788533615120"`
+	Description     string   `json:"description" example:"Uma história épica de fantasia..."`
+	CoverURL        string   `json:"cover_url" example:"https://example.com/cover.jpg"`
+	Publisher       string   `json:"publisher" example:"Martins Fontes"`
+	PublicationYear int      `json:"publication_year" example:"2000"`
+	Subjects        []string `json:"subjects" example:"Fantasia,Literatura inglesa"`
+	Status          string   `json:"status" example:"available" enums:"available,borrowed,lost"`
+}
+
+// BookBatchRequest é o corpo de POST /books/batch
+type BookBatchRequest struct {
+	// atomic: tudo ou nada em uma transação; partial: cada operação é independente
+	Mode       string               `json:"mode" example:"atomic" enums:"atomic,partial"`
+	Operations []BookBatchOperation `json:"operations"`
+}
+
+// BookBatchOperation é uma operação do lote. update e delete exigem a versão
+// atual do livro.
+type BookBatchOperation struct {
+	Op      string       `json:"op" example:"create" enums:"create,update,delete"`
+	ID      string       `json:"id,omitempty" example:"e0c7f36a-9c5e-4c7d-b0a1-596b344f3a0b"`
+	Version int64        `json:"version,omitempty" example:"1"`
+	Book    *domain.Book `json:"book,omitempty"`
+}
+
+// BookBatchItemResult é o resultado de uma operação, na mesma posição do pedido
+type BookBatchItemResult struct {
+	Index  int          `json:"index" example:"0"`
+	Op     string       `json:"op" example:"create"`
+	ID     string       `json:"id,omitempty" example:"e0c7f36a-9c5e-4c7d-b0a1-596b344f3a0b"`
+	Status int          `json:"status" example:"201"`
+	Book   *domain.Book `json:"book,omitempty"`
+	// Erro da operação que falhou, com o código e os campos inválidos
+	Error  string              `json:"error,omitempty" example:"book not found"`
+	Code   domain.Code         `json:"code,omitempty" swaggertype:"string" example:"BOOK_NOT_FOUND"`
+	Errors []domain.FieldError `json:"errors,omitempty"`
+}
+
+// BookBatchResponse é a resposta de POST /books/batch
+type BookBatchResponse struct {
+	Mode      string                `json:"mode" example:"atomic"`
+	Succeeded int                   `json:"succeeded" example:"1"`
+	Failed    int                   `json:"failed" example:"0"`
+	Results   []BookBatchItemResult `json:"results"`
+}
+
+// ISBNLookupResponse é a resposta de GET /books/isbn/{isbn}: o ISBN informado
+// nas suas formas normalizadas e os livros encontrados
+type ISBNLookupResponse struct {
+	ISBN13     string         `json:"isbn13" example:"9788533615120"`
+	ISBN10     string         `json:"isbn10,omitempty" example:"8533615124"`
+	Hyphenated string         `json:"hyphenated" example:"978-85-336-1512-0"`
+	Books      []*domain.Book `json:"books"`
+}
+
+// BookMetadataResponse é a resposta de POST /books/metadata: o livro com os
+// metadados aplicados (ainda não gravado), os dados do catálogo e os campos
+// alterados
+type BookMetadataResponse struct {
+	Book     *domain.Book         `json:"book"`
+	Metadata *domain.BookMetadata `json:"metadata"`
+	Changed  []string             `json:"changed" example:"title,author,cover_url"`
+}
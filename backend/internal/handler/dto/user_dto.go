@@ -20,3 +20,12 @@ type UserResponse struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// UserPatchDocument é a representação do usuário sobre a qual os patches
+// (RFC 7396 e RFC 6902) são aplicados. A senha nunca é exposta, mas pode ser
+// definida pelo patch.
+type UserPatchDocument struct {
+	Name     string `json:"name" example:"João Silva"`
+	Email    string `json:"email" example:"joao.silva@example.com"`
+	Password string `json:"password,omitempty" example:"novaSenha123"`
+}
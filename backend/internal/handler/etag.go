@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+// Livros podem ser guardados por navegadores e proxies, mas precisam ser
+// revalidados (com If-None-Match ou If-Modified-Since) a cada uso
+const bookCacheControl = "public, no-cache"
+
+var (
+	errPreconditionRequired = errors.New("If-Match header is required")
+	errInvalidPrecondition  = errors.New("invalid If-Match header")
+)
+
+// etag gera a ETag forte de um recurso a partir da sua versão
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// listETag gera a ETag forte de uma página de livros a partir dos IDs e
+// versões, que mudam com qualquer inclusão, alteração ou remoção na página
+func listETag(books []*domain.Book) string {
+	hash := sha256.New()
+	for _, book := range books {
+		fmt.Fprintf(hash, "%s:%d\n", book.ID, book.Version)
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:8]) + `"`
+}
+
+// encodedETag acrescenta a codificação a uma ETag forte. A RFC 9110 não
+// permite a mesma ETag forte em representações com bytes diferentes, como a
+// comprimida e a original.
+func encodedETag(tag, encoding string) string {
+	tag = identityETag(tag)
+	if !strings.HasPrefix(tag, `"`) || len(tag) < 2 {
+		return tag
+	}
+	return strings.TrimSuffix(tag, `"`) + "-" + encoding + `"`
+}
+
+// identityETag remove de uma ETag o sufixo de encodedETag. As condições das
+// requisições valem para todas as codificações da mesma versão.
+func identityETag(tag string) string {
+	for _, encoding := range encodings {
+		if trimmed, ok := strings.CutSuffix(tag, "-"+encoding+`"`); ok {
+			return trimmed + `"`
+		}
+	}
+	return tag
+}
+
+// lastModified formata o instante para o cabeçalho Last-Modified
+func lastModified(t time.Time) string {
+	return t.UTC().Format(http.TimeFormat)
+}
+
+// ifMatchVersion lê o cabeçalho If-Match e devolve a versão esperada pelo
+// cliente. "*" aceita qualquer versão e é representado por zero.
+func ifMatchVersion(c *gin.Context) (int64, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		return 0, errPreconditionRequired
+	}
+
+	if header == "*" {
+		return 0, nil
+	}
+
+	if !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) || len(header) < 2 {
+		return 0, errInvalidPrecondition
+	}
+
+	version, err := strconv.ParseInt(strings.Trim(identityETag(header), `"`), 10, 64)
+	if err != nil || version < 1 {
+		return 0, errInvalidPrecondition
+	}
+
+	return version, nil
+}
+
+// requireIfMatch lê a versão do If-Match e responde 428/400 quando ausente ou
+// malformada. Retorna false se a requisição já foi respondida.
+func requireIfMatch(c *gin.Context) (int64, bool) {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		if errors.Is(err, errPreconditionRequired) {
+			respondProblem(c, http.StatusPreconditionRequired, codePreconditionRequired, err.Error())
+		} else {
+			respondProblem(c, http.StatusBadRequest, codeInvalidPrecondition, err.Error())
+		}
+		return 0, false
+	}
+
+	return version, true
+}
+
+// notModified verifica o If-None-Match contra a ETag atual, em qualquer
+// codificação. Comparação fraca, conforme a RFC 9110 para requisições de
+// leitura.
+func notModified(c *gin.Context, current string) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || identityETag(strings.TrimPrefix(candidate, "W/")) == current {
+			return true
+		}
+	}
+
+	return false
+}
+
+// notModifiedSince verifica o If-Modified-Since contra o instante da última
+// alteração, com a precisão de segundos do cabeçalho. Conforme a RFC 9110, é
+// ignorado quando a requisição traz If-None-Match.
+func notModifiedSince(c *gin.Context, modified time.Time) bool {
+	header := c.GetHeader("If-Modified-Since")
+	if header == "" || c.GetHeader("If-None-Match") != "" {
+		return false
+	}
+
+	since, err := http.ParseTime(header)
+	if err != nil {
+		return false
+	}
+
+	return !modified.Truncate(time.Second).After(since)
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+// newContext cria um contexto de uma requisição GET com os cabeçalhos headers
+func newContext(headers map[string]string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	for name, value := range headers {
+		c.Request.Header.Set(name, value)
+	}
+	return c
+}
+
+func TestIfMatchVersion(t *testing.T) {
+	tests := []struct {
+		header  string
+		want    int64
+		wantErr error
+	}{
+		{"", 0, errPreconditionRequired},
+		{" ", 0, errPreconditionRequired},
+		{"*", 0, nil},
+		{`"3"`, 3, nil},
+		{` "3" `, 3, nil},
+		{`"3-gzip"`, 3, nil},
+		{`"3-br"`, 3, nil},
+		{`3`, 0, errInvalidPrecondition},
+		{`"`, 0, errInvalidPrecondition},
+		{`W/"3"`, 0, errInvalidPrecondition},
+		{`"0"`, 0, errInvalidPrecondition},
+		{`"-1"`, 0, errInvalidPrecondition},
+		{`"abc"`, 0, errInvalidPrecondition},
+		{`"3", "4"`, 0, errInvalidPrecondition},
+	}
+
+	for _, tt := range tests {
+		got, err := ifMatchVersion(newContext(map[string]string{"If-Match": tt.header}))
+		if got != tt.want || !errors.Is(err, tt.wantErr) {
+			t.Errorf("ifMatchVersion(%q) = %d, %v; want %d, %v", tt.header, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{`"3"`, true},
+		{`W/"3"`, true},
+		{`"3-gzip"`, true},
+		{`"2", "3-br"`, true},
+		{"*", true},
+		{`"2"`, false},
+		{`"3-deflate"`, false},
+		{`"33"`, false},
+	}
+
+	for _, tt := range tests {
+		if got := notModified(newContext(map[string]string{"If-None-Match": tt.header}), `"3"`); got != tt.want {
+			t.Errorf("notModified(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestNotModifiedSince(t *testing.T) {
+	modified := time.Date(2024, 3, 1, 12, 0, 0, 500_000_000, time.UTC)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    bool
+	}{
+		{"absent", nil, false},
+		{"same second", map[string]string{"If-Modified-Since": lastModified(modified)}, true},
+		{"later", map[string]string{"If-Modified-Since": lastModified(modified.Add(time.Hour))}, true},
+		{"earlier", map[string]string{"If-Modified-Since": lastModified(modified.Add(-time.Second))}, false},
+		{"invalid", map[string]string{"If-Modified-Since": "yesterday"}, false},
+		{"with If-None-Match", map[string]string{"If-Modified-Since": lastModified(modified), "If-None-Match": `"2"`}, false},
+	}
+
+	for _, tt := range tests {
+		if got := notModifiedSince(newContext(tt.headers), modified); got != tt.want {
+			t.Errorf("%s: notModifiedSince() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestListETag(t *testing.T) {
+	books := []*domain.Book{{ID: "a", Version: 1}, {ID: "b", Version: 1}}
+	tag := listETag(books)
+
+	if again := listETag([]*domain.Book{{ID: "a", Version: 1}, {ID: "b", Version: 1}}); again != tag {
+		t.Errorf("listETag() of the same page = %s, want %s", again, tag)
+	}
+	for name, page := range map[string][]*domain.Book{
+		"updated":   {{ID: "a", Version: 2}, {ID: "b", Version: 1}},
+		"reordered": {{ID: "b", Version: 1}, {ID: "a", Version: 1}},
+		"removed":   {{ID: "a", Version: 1}},
+		"empty":     nil,
+	} {
+		if got := listETag(page); got == tag {
+			t.Errorf("listETag() of the %s page = %s, want a different tag", name, got)
+		}
+	}
+}
+
+func TestEncodedETag(t *testing.T) {
+	tests := []struct {
+		tag, encoding, want string
+	}{
+		{`"3"`, "gzip", `"3-gzip"`},
+		{`"3-br"`, "gzip", `"3-gzip"`},
+		{`"3-gzip"`, "gzip", `"3-gzip"`},
+		{`W/"3"`, "gzip", `W/"3"`},
+	}
+
+	for _, tt := range tests {
+		if got := encodedETag(tt.tag, tt.encoding); got != tt.want {
+			t.Errorf("encodedETag(%s, %s) = %s, want %s", tt.tag, tt.encoding, got, tt.want)
+		}
+	}
+
+	for tag, want := range map[string]string{`"3-gzip"`: `"3"`, `"3-br"`: `"3"`, `"3"`: `"3"`, `"3-deflate"`: `"3-deflate"`} {
+		if got := identityETag(tag); got != want {
+			t.Errorf("identityETag(%s) = %s, want %s", tag, got, want)
+		}
+	}
+}
@@ -1,51 +1,62 @@
 package handler
 
 import (
+    "crypto/subtle"
     "net/http"
+    "strings"
 
     "github.com/gin-gonic/gin"
-    "github.com/jmoiron/sqlx"
+
+    "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/health"
 )
 
 type HealthHandler struct {
-    db *sqlx.DB
+    readiness *health.Registry
+    // Token que libera o relatório detalhado das verificações; vazio nunca o mostra
+    token string
 }
 
-func NewHealthHandler(db *sqlx.DB) *HealthHandler {
+func NewHealthHandler(readiness *health.Registry, token string) *HealthHandler {
     return &HealthHandler{
-        db: db,
+        readiness: readiness,
+        token:     token,
     }
 }
 
-func (h *HealthHandler) HealthCheck(c *gin.Context) {
-    err := h.db.Ping()
-    if err != nil {
-        c.JSON(http.StatusServiceUnavailable, gin.H{
-            "status":  "error",
-            "message": "Database connection failed",
-            "error":   err.Error(),
-        })
-        return
+// Liveness responde enquanto o processo consegue atender requisições, sem
+// consultar as dependências, para que uma falha do banco não reinicie o serviço
+func (h *HealthHandler) Liveness(c *gin.Context) {
+    c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// Readiness executa as verificações registradas e responde 503 se alguma
+// falhar. O resultado de cada verificação só aparece para quem envia o token.
+func (h *HealthHandler) Readiness(c *gin.Context) {
+    report := h.readiness.Run(c.Request.Context())
+
+    status := http.StatusOK
+    if !report.OK() {
+        status = http.StatusServiceUnavailable
     }
 
-    var tableExists bool
-    err = h.db.QueryRow("SELECT EXISTS (SELECT FROM information_schema.tables WHERE table_schema = 'public' AND table_name = 'books')").Scan(&tableExists)
-    
-    if err != nil || !tableExists {
-        c.JSON(http.StatusServiceUnavailable, gin.H{
-            "status":  "error",
-            "message": "Required tables don't exist",
-            "error":   err.Error(),
-        })
+    if h.authorized(c) {
+        c.JSON(status, report)
         return
     }
+    c.JSON(status, gin.H{"status": report.Status})
+}
+
+func (h *HealthHandler) authorized(c *gin.Context) bool {
+    if h.token == "" {
+        return false
+    }
 
-    c.JSON(http.StatusOK, gin.H{
-        "status":  "ok",
-        "message": "Service is healthy",
-    })
+    token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+    return ok && subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) == 1
 }
 
-func (h *HealthHandler) RegisterRoutes(router *gin.RouterGroup) {
-    router.GET("/health", h.HealthCheck)
-}
\ No newline at end of file
+// RegisterRoutes registra as sondas fora do prefixo /api
+func (h *HealthHandler) RegisterRoutes(router gin.IRoutes) {
+    router.GET("/healthz", h.Liveness)
+    router.GET("/readyz", h.Readiness)
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/logging"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/usecase"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	maxIdempotentRequestBytes = 32 << 20
+)
+
+// Cabeçalhos da resposta original que são repetidos junto com o corpo
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// bodyRecorder copia o corpo da resposta enquanto ele é enviado ao cliente
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware torna idempotentes as requisições que enviam o
+// cabeçalho Idempotency-Key: a primeira resposta é armazenada e repetida nas
+// novas tentativas na mesma rota e com o mesmo corpo. Requisições sem o
+// cabeçalho seguem normalmente.
+func IdempotencyMiddleware(idempotencyService *usecase.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			respondProblem(c, http.StatusBadRequest, codeMalformedRequest, "Idempotency-Key must have at most 255 characters")
+			return
+		}
+
+		// Um byte além do limite mostra que o corpo foi cortado
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIdempotentRequestBytes+1))
+		if err != nil {
+			respondProblem(c, http.StatusBadRequest, codeMalformedRequest, "invalid request body")
+			return
+		}
+		if len(body) > maxIdempotentRequestBytes {
+			respondProblem(c, http.StatusRequestEntityTooLarge, codePayloadTooLarge, "request body too large")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// A mesma chave em rotas diferentes identifica requisições diferentes
+		route := c.Request.Method + " " + c.FullPath() + "\n"
+		key = hashHex([]byte(route), []byte(key))
+		fingerprint := hashHex([]byte(route), body)
+
+		record, err := idempotencyService.Begin(c.Request.Context(), key, fingerprint)
+		if err != nil {
+			if errors.Is(err, domain.ErrIdempotencyKeyInProgress) {
+				c.Header("Retry-After", "1")
+			}
+			respondError(c, err)
+			return
+		}
+
+		if record.Completed {
+			for name, value := range record.Headers {
+				c.Header(name, value)
+			}
+			c.Header(idempotentReplayedHeader, "true")
+			c.Data(record.StatusCode, record.Headers["Content-Type"], record.Body)
+			c.Abort()
+			return
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		completed := false
+		defer func() {
+			if !completed {
+				// Falha ou panic: libera a chave para que o cliente possa tentar de novo
+				if err := idempotencyService.Release(context.WithoutCancel(c.Request.Context()), record); err != nil {
+					logging.FromContext(c.Request.Context()).Error("Failed to release idempotency key", "error", err)
+				}
+			}
+		}()
+
+		c.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+
+		headers := make(map[string]string, len(replayedHeaders))
+		for _, name := range replayedHeaders {
+			if value := recorder.Header().Get(name); value != "" {
+				headers[name] = value
+			}
+		}
+		// A repetição pode ir com outra codificação
+		if tag, ok := headers["ETag"]; ok {
+			headers["ETag"] = identityETag(tag)
+		}
+
+		if err := idempotencyService.Complete(context.WithoutCancel(c.Request.Context()), record, status, headers, recorder.body.Bytes()); err != nil {
+			logging.FromContext(c.Request.Context()).Error("Failed to store idempotent response", "error", err)
+			return
+		}
+		completed = true
+	}
+}
+
+// hashHex devolve o SHA-256 de parts, concatenadas, em hexadecimal
+func hashHex(parts ...[]byte) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write(part)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
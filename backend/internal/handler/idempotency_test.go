@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository/memory"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/usecase"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newIdempotentRouter cria duas rotas com o middleware de idempotência que
+// contam quantas vezes foram executadas
+func newIdempotentRouter() (*gin.Engine, map[string]int) {
+	calls := make(map[string]int)
+	service := usecase.NewIdempotencyService(memory.NewIdempotencyRepository(), time.Hour, time.Minute)
+
+	router := gin.New()
+	for _, path := range []string{"/books", "/users"} {
+		router.POST(path, IdempotencyMiddleware(service), func(c *gin.Context) {
+			calls[path]++
+			c.Header("Location", path+"/1")
+			c.JSON(http.StatusCreated, gin.H{"path": path, "call": calls[path]})
+		})
+	}
+	return router, calls
+}
+
+func postIdempotent(router http.Handler, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(idempotencyKeyHeader, key)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyMiddlewareReplay(t *testing.T) {
+	router, calls := newIdempotentRouter()
+
+	first := postIdempotent(router, "/books", "key", `{"title":"Livro"}`)
+	second := postIdempotent(router, "/books", "key", `{"title":"Livro"}`)
+
+	if calls["/books"] != 1 {
+		t.Errorf("handler called %d times, want 1", calls["/books"])
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get(idempotentReplayedHeader) != "true" || second.Header().Get("Location") != "/books/1" {
+		t.Errorf("replay headers = %v, want Idempotent-Replayed and the original Location", second.Header())
+	}
+
+	mismatch := postIdempotent(router, "/books", "key", `{"title":"Outro"}`)
+	if mismatch.Code != http.StatusUnprocessableEntity || !strings.Contains(mismatch.Body.String(), "IDEMPOTENCY_KEY_REUSED") {
+		t.Errorf("another body = %d %s, want 422 IDEMPOTENCY_KEY_REUSED", mismatch.Code, mismatch.Body)
+	}
+}
+
+func TestIdempotencyMiddlewareScopesKeysByRoute(t *testing.T) {
+	router, calls := newIdempotentRouter()
+
+	books := postIdempotent(router, "/books", "key", `{}`)
+	users := postIdempotent(router, "/users", "key", `{}`)
+
+	if books.Code != http.StatusCreated || users.Code != http.StatusCreated || users.Header().Get(idempotentReplayedHeader) != "" {
+		t.Errorf("responses = %d and %d %v, want both routes executed", books.Code, users.Code, users.Header())
+	}
+	if calls["/books"] != 1 || calls["/users"] != 1 {
+		t.Errorf("calls = %v, want one per route", calls)
+	}
+}
+
+func TestIdempotencyMiddlewareBodyTooLarge(t *testing.T) {
+	router, calls := newIdempotentRouter()
+
+	w := postIdempotent(router, "/books", "key", strings.Repeat("a", maxIdempotentRequestBytes+1))
+	if w.Code != http.StatusRequestEntityTooLarge || !strings.Contains(w.Body.String(), string(codePayloadTooLarge)) {
+		t.Errorf("oversized body = %d %s, want 413 %s", w.Code, w.Body, codePayloadTooLarge)
+	}
+	if calls["/books"] != 0 {
+		t.Errorf("handler called %d times for a rejected body, want 0", calls["/books"])
+	}
+
+	// O corpo no limite ainda é aceito inteiro
+	if w := postIdempotent(router, "/books", "key", strings.Repeat("a", maxIdempotentRequestBytes)); w.Code != http.StatusCreated {
+		t.Errorf("body at the limit = %d %s, want 201", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/handler/dto"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/usecase"
+)
+
+const maxMetadataBodySize = 1 << 20
+
+type MetadataHandler struct {
+	metadataService *usecase.MetadataService
+}
+
+func NewMetadataHandler(metadataService *usecase.MetadataService) *MetadataHandler {
+	return &MetadataHandler{
+		metadataService: metadataService,
+	}
+}
+
+// EnrichBook godoc
+// @Summary      Fill in book metadata from external catalogs
+// @Description  Look up the book's ISBN in the configured catalogs (Open Library, Google Books) and merge the result into the book sent in the body. Only the ISBN is required. By default only empty fields are filled; with overwrite=true the catalog data replaces the sent values. Nothing is saved.
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Param        overwrite  query     bool         false  "Replace fields that already have a value"
+// @Param        book       body      domain.Book  true   "Book with at least the ISBN"
+// @Success      200  {object}  dto.BookMetadataResponse
+// @Failure      400  {object}  handler.Problem
+// @Failure      404  {object}  handler.Problem
+// @Failure      503  {object}  handler.Problem
+// @Failure      500  {object}  handler.Problem
+// @Router       /books/metadata [post]
+func (h *MetadataHandler) EnrichBook(c *gin.Context) {
+	overwrite, _ := strconv.ParseBool(c.Query("overwrite"))
+
+	// Sem a validação do binding: título e autor ainda podem estar vazios
+	var book domain.Book
+	if err := json.NewDecoder(io.LimitReader(c.Request.Body, maxMetadataBodySize)).Decode(&book); err != nil {
+		respondProblem(c, http.StatusBadRequest, codeMalformedRequest, "invalid request body")
+		return
+	}
+
+	result, changed, err := h.metadataService.EnrichBook(c.Request.Context(), &book, overwrite)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.BookMetadataResponse{
+		Book:     &book,
+		Metadata: result,
+		Changed:  changed,
+	})
+}
+
+func (h *MetadataHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("/books/metadata", h.EnrichBook)
+}
@@ -8,8 +8,9 @@ func CORSMiddleware() gin.HandlerFunc {
     return func(c *gin.Context) {
         c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
         c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-        c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-        c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+        c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, If-Match, If-None-Match, If-Modified-Since, Idempotency-Key, X-Request-ID, traceparent, tracestate, baggage")
+        c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag, Last-Modified, Idempotent-Replayed, X-Request-ID")
+        c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 
         if c.Request.Method == "OPTIONS" {
             c.AbortWithStatus(204)
@@ -19,7 +20,3 @@ func CORSMiddleware() gin.HandlerFunc {
         c.Next()
     }
 }
-
-type ErrorResponse struct {
-    Error string `json:"error"`
-}
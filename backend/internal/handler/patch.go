@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+
+	maxPatchBodySize = 1 << 20
+)
+
+var errUnsupportedPatch = errors.New("unsupported patch media type")
+
+// readPatch lê o corpo de uma requisição PATCH e devolve uma função que aplica
+// o patch sobre um documento JSON. application/json é tratado como merge patch.
+func readPatch(c *gin.Context) (func(doc []byte) ([]byte, error), error) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPatchBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+
+	switch c.ContentType() {
+	case jsonPatchContentType:
+		patch, err := jsonpatch.DecodePatch(body)
+		if err != nil {
+			return nil, domain.NewValidationError("patch", fmt.Sprintf("invalid JSON patch: %v", err))
+		}
+		return func(doc []byte) ([]byte, error) {
+			return patch.Apply(doc)
+		}, nil
+	case mergePatchContentType, "application/json", "":
+		if !json.Valid(body) || !bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) {
+			return nil, domain.NewValidationError("patch", "merge patch must be a JSON object")
+		}
+		return func(doc []byte) ([]byte, error) {
+			return jsonpatch.MergePatch(doc, body)
+		}, nil
+	default:
+		return nil, errUnsupportedPatch
+	}
+}
+
+// patchDocument aplica o patch sobre doc e substitui doc pelo resultado. Campos
+// removidos voltam ao valor zero; campos desconhecidos ou com tipo inválido são
+// rejeitados.
+func patchDocument[T any](patch func(doc []byte) ([]byte, error), doc *T) error {
+	original, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	patched, err := patch(original)
+	if err != nil {
+		return domain.NewValidationError("patch", err.Error())
+	}
+
+	var result T
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&result); err != nil {
+		return domain.NewValidationError("patch", err.Error())
+	}
+
+	*doc = result
+	return nil
+}
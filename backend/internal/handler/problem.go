@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+const problemContentType = "application/problem+json"
+
+// Códigos dos problemas detectados pelos próprios handlers, fora do domínio
+const (
+	codeMalformedRequest     domain.Code = "MALFORMED_REQUEST"
+	codePreconditionRequired domain.Code = "PRECONDITION_REQUIRED"
+	codeInvalidPrecondition  domain.Code = "INVALID_PRECONDITION"
+	codeUnsupportedMediaType domain.Code = "UNSUPPORTED_MEDIA_TYPE"
+	codePayloadTooLarge      domain.Code = "PAYLOAD_TOO_LARGE"
+	codeInvalidCredentials   domain.Code = "INVALID_CREDENTIALS"
+	codeCoverNotFound        domain.Code = "COVER_NOT_FOUND"
+	codeInternal             domain.Code = "INTERNAL_ERROR"
+)
+
+// Status HTTP de cada código do domínio
+var problemStatus = map[domain.Code]int{
+	domain.CodeValidationFailed:         http.StatusBadRequest,
+	domain.CodeInvalidISBN:              http.StatusBadRequest,
+	domain.CodeBookNotFound:             http.StatusNotFound,
+	domain.CodeUserNotFound:             http.StatusNotFound,
+	domain.CodeUserDisabled:             http.StatusForbidden,
+	domain.CodeEmailTaken:               http.StatusConflict,
+	domain.CodeVersionConflict:          http.StatusPreconditionFailed,
+	domain.CodeBlobNotFound:             http.StatusNotFound,
+	domain.CodeCoverTooLarge:            http.StatusRequestEntityTooLarge,
+	domain.CodeUnsupportedCover:         http.StatusUnsupportedMediaType,
+	domain.CodeIdempotencyKeyInProgress: http.StatusConflict,
+	domain.CodeIdempotencyKeyReused:     http.StatusUnprocessableEntity,
+	domain.CodeMetadataNotFound:         http.StatusNotFound,
+	domain.CodeMetadataUnavailable:      http.StatusServiceUnavailable,
+	domain.CodeBatchAborted:             http.StatusFailedDependency,
+}
+
+// Problem é o corpo das respostas de erro, no formato da RFC 7807
+// (application/problem+json). Os clientes devem tratar os erros pelo code e
+// podem exibir o detail.
+type Problem struct {
+	Type     string `json:"type" example:"about:blank"`
+	Title    string `json:"title" example:"Not Found"`
+	Status   int    `json:"status" example:"404"`
+	Detail   string `json:"detail" example:"book not found"`
+	Instance string `json:"instance" example:"/api/books/0b7e5a2c-3f4d-4c59-9a8e-2f1d6c0e7b11"`
+	// Código estável do erro
+	Code domain.Code `json:"code" swaggertype:"string" example:"BOOK_NOT_FOUND"`
+	// ID da requisição, o mesmo do cabeçalho X-Request-ID e dos logs
+	RequestID string `json:"request_id,omitempty" example:"5f0c6a8e-1d2b-4c3a-9e8f-7a6b5c4d3e2f"`
+	// Problemas de cada campo, nos erros de validação
+	Errors []domain.FieldError `json:"errors,omitempty"`
+}
+
+// errorProblem descreve err como um problema. Erros sem código do domínio
+// são internos. A mensagem completa de err pode conter as causas encapsuladas,
+// com detalhes da infraestrutura, então o detail traz apenas a mensagem do
+// domínio e, nos erros de validação, a de cada campo.
+func errorProblem(err error) Problem {
+	var domainErr *domain.Error
+	if !errors.As(err, &domainErr) {
+		return Problem{Status: http.StatusInternalServerError, Code: codeInternal, Detail: "internal server error"}
+	}
+
+	status, ok := problemStatus[domainErr.Code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	problem := Problem{Status: status, Code: domainErr.Code, Detail: domainErr.Message}
+	var validation *domain.ValidationError
+	if status < http.StatusInternalServerError && errors.As(err, &validation) {
+		problem.Detail = validation.Error()
+		problem.Errors = validation.Fields
+	}
+	return problem
+}
+
+// respondError responde com o problema correspondente a err. Os erros de
+// servidor são registrados no log de acesso, e a resposta traz o ID da
+// requisição para encontrá-los.
+func respondError(c *gin.Context, err error) {
+	problem := errorProblem(err)
+	if problem.Status >= http.StatusInternalServerError {
+		c.Error(err)
+	}
+
+	writeProblem(c, problem)
+}
+
+// bindJSON lê o corpo JSON em obj. As falhas das regras binding das structs
+// viram erros de validação por campo, com os nomes do JSON; um corpo ilegível
+// é MALFORMED_REQUEST. Devolve false se a resposta de erro já foi enviada.
+func bindJSON(c *gin.Context, obj any) bool {
+	err := c.ShouldBindJSON(obj)
+	if err == nil {
+		return true
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		respondProblem(c, http.StatusBadRequest, codeMalformedRequest, "invalid request body")
+		return false
+	}
+
+	validation := &domain.ValidationError{}
+	for _, fieldErr := range fieldErrs {
+		validation.Add(fieldErr.Field(), bindingMessage(fieldErr))
+	}
+	respondError(c, validation)
+	return false
+}
+
+// bindingMessage descreve a regra violada com as mesmas mensagens do domínio
+func bindingMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", fieldErr.Field())
+	case "email":
+		return fmt.Sprintf("%s is not a valid address", fieldErr.Field())
+	case "min":
+		return fmt.Sprintf("%s must have at least %s characters", fieldErr.Field(), fieldErr.Param())
+	default:
+		return fmt.Sprintf("%s is invalid", fieldErr.Field())
+	}
+}
+
+// Os erros de validação usam o nome do campo no JSON
+func init() {
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				return strings.ToLower(field.Name)
+			}
+			return name
+		})
+	}
+}
+
+// respondProblem responde com um problema detectado pelo próprio handler
+func respondProblem(c *gin.Context, status int, code domain.Code, detail string) {
+	writeProblem(c, Problem{Status: status, Code: code, Detail: detail})
+}
+
+func writeProblem(c *gin.Context, problem Problem) {
+	fillProblem(c, &problem)
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// fillProblem preenche os membros do problema que vêm do status e da requisição
+func fillProblem(c *gin.Context, problem *Problem) {
+	problem.Type = "about:blank"
+	problem.Title = http.StatusText(problem.Status)
+	problem.Instance = c.Request.URL.Path
+	problem.RequestID = c.GetString(requestIDKey)
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+func TestErrorProblemStatus(t *testing.T) {
+	for code, want := range problemStatus {
+		problem := errorProblem(&domain.Error{Code: code, Message: "message"})
+		if problem.Status != want || problem.Code != code {
+			t.Errorf("errorProblem(%s) = %d %s, want %d", code, problem.Status, problem.Code, want)
+		}
+	}
+
+	if problem := errorProblem(&domain.Error{Code: "UNKNOWN", Message: "message"}); problem.Status != http.StatusInternalServerError {
+		t.Errorf("errorProblem() of an unknown code = %d, want 500", problem.Status)
+	}
+}
+
+func TestErrorProblemDetail(t *testing.T) {
+	validation := &domain.ValidationError{}
+	validation.Add("title", "title is required")
+	validation.Add("author", "author is required")
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   domain.Code
+		wantDetail string
+		wantFields int
+	}{
+		{"domain error", domain.ErrBookNotFound, http.StatusNotFound, domain.CodeBookNotFound, "book not found", 0},
+		{"wrapped cause", fmt.Errorf("%w: dial tcp 10.0.0.5:5432: connection refused", domain.ErrInvalidInput), http.StatusBadRequest, domain.CodeValidationFailed, "invalid input", 0},
+		{"specialized error", fmt.Errorf("insert user: %w", domain.ErrEmailTaken), http.StatusConflict, domain.CodeEmailTaken, "email already exists", 0},
+		{"validation", fmt.Errorf("create book: %w", validation), http.StatusBadRequest, domain.CodeValidationFailed, "invalid input: title is required; author is required", 2},
+		{"server error", fmt.Errorf("%w: lookup openlibrary.org: no such host", &domain.Error{Code: domain.CodeMetadataUnavailable, Message: "metadata provider unavailable"}), http.StatusServiceUnavailable, domain.CodeMetadataUnavailable, "metadata provider unavailable", 0},
+		{"internal error", errors.New("pq: password authentication failed"), http.StatusInternalServerError, codeInternal, "internal server error", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem := errorProblem(tt.err)
+			if problem.Status != tt.wantStatus || problem.Code != tt.wantCode || problem.Detail != tt.wantDetail || len(problem.Errors) != tt.wantFields {
+				t.Errorf("errorProblem() = %+v, want %d %s %q with %d fields", problem, tt.wantStatus, tt.wantCode, tt.wantDetail, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestRespondErrorServerError(t *testing.T) {
+	router := gin.New()
+	router.Use(RequestIDMiddleware(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	var logged []*gin.Error
+	router.Use(func(c *gin.Context) {
+		c.Next()
+		logged = c.Errors
+	})
+	router.GET("/fail", func(c *gin.Context) {
+		respondError(c, errors.New("pq: relation \"books\" does not exist"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+	req.Header.Set(requestIDHeader, "req-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var problem Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decode %s: %v", w.Body, err)
+	}
+	if w.Code != http.StatusInternalServerError || problem.RequestID != "req-123" || problem.Detail != "internal server error" {
+		t.Errorf("response = %d %s, want a 500 with the request ID", w.Code, w.Body)
+	}
+	if strings.Contains(w.Body.String(), "relation") {
+		t.Errorf("response %s exposes the cause", w.Body)
+	}
+	if len(logged) != 1 {
+		t.Errorf("got %d errors for the access log, want 1", len(logged))
+	}
+}
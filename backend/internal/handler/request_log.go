@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/logging"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// Chave do contexto do gin com o ID do usuário da requisição, incluído no
+// log de acesso
+const userIDKey = "user_id"
+
+// Chave do contexto do gin com o ID da requisição, incluído nos problemas
+const requestIDKey = "request_id"
+
+// IDs recebidos de proxies e clientes são aceitos se forem curtos e seguros
+// para aparecer em logs
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:/+=-]{1,128}$`)
+
+// RequestIDMiddleware reaproveita o X-Request-ID recebido, ou cria um, devolve-o
+// na resposta e coloca no contexto da requisição um logger que o inclui, junto
+// com o trace_id quando a requisição faz parte de uma trace
+func RequestIDMiddleware(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = uuid.NewString()
+		}
+
+		c.Header(requestIDHeader, id)
+		c.Set(requestIDKey, id)
+		requestLogger := logger.With("request_id", id)
+		if span := trace.SpanContextFromContext(c.Request.Context()); span.IsValid() {
+			requestLogger = requestLogger.With("trace_id", span.TraceID().String())
+		}
+		ctx := logging.WithContext(c.Request.Context(), requestLogger)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// AccessLogMiddleware registra cada requisição: método, rota, status, duração,
+// bytes enviados e, quando conhecido, o usuário. Nenhum cabeçalho, parâmetro
+// de query ou corpo é registrado.
+func AccessLogMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if userID := c.GetString(userIDKey); userID != "" {
+			attrs = append(attrs, slog.String("user_id", userID))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.String()))
+		}
+
+		ctx := c.Request.Context()
+		logging.FromContext(ctx).LogAttrs(ctx, level, "request", attrs...)
+	}
+}
+
+// RecoveryMiddleware responde 500 a um panic e o registra com o request ID
+func RecoveryMiddleware() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err any) {
+		logging.FromContext(c.Request.Context()).Error("Panic while handling request",
+			"error", err, "stack", string(debug.Stack()))
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "internal server error")
+	})
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -8,6 +9,7 @@ import (
 
 	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
 	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/handler/dto"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/metrics"
 	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/usecase"
 )
 
@@ -27,21 +29,27 @@ func NewUserHandler(userService *usecase.UserService) *UserHandler {
 // @Tags         users
 // @Accept       json
 // @Produce      json
-// @Param        id   path      string  true  "User ID"
+// @Param        id             path      string  true   "User ID"
+// @Param        If-None-Match  header    string  false  "ETag from a previous response"
 // @Success      200  {object}  domain.User
-// @Failure      404  {object}  handler.ErrorResponse
-// @Failure      500  {object}  handler.ErrorResponse
+// @Header       200  {string}  ETag  "Current version of the user"
+// @Success      304  "Not modified"
+// @Failure      404  {object}  handler.Problem
+// @Failure      500  {object}  handler.Problem
 // @Router       /users/{id} [get]
 func (h *UserHandler) GetUser(c *gin.Context) {
 	id := c.Param("id")
 
 	user, err := h.userService.GetUser(c.Request.Context(), id)
 	if err != nil {
-		if err == domain.ErrUserNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
+		return
+	}
+
+	tag := etag(user.Version)
+	c.Header("ETag", tag)
+	if notModified(c, tag) {
+		c.Status(http.StatusNotModified)
 		return
 	}
 
@@ -57,7 +65,7 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 // @Param        page       query     int  false  "Page number"       default(1)
 // @Param        page_size  query     int  false  "Items per page"    default(10)
 // @Success      200        {array}   domain.User
-// @Failure      500        {object}  handler.ErrorResponse
+// @Failure      500        {object}  handler.Problem
 // @Router       /users [get]
 func (h *UserHandler) ListUsers(c *gin.Context) {
 	pageStr := c.DefaultQuery("page", "1")
@@ -75,7 +83,7 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 
 	users, err := h.userService.ListUsers(c.Request.Context(), page, pageSize)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -88,30 +96,30 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 // @Tags         users
 // @Accept       json
 // @Produce      json
-// @Param        user  body      domain.User  true  "User information"
+// @Param        Idempotency-Key  header  string       false  "Key that makes retries of this request safe"
+// @Param        user             body    domain.User  true   "User information"
 // @Success      201   {object}  domain.User
-// @Failure      400   {object}  handler.ErrorResponse
-// @Failure      500   {object}  handler.ErrorResponse
+// @Header       201   {string}  ETag  "Current version of the user"
+// @Failure      400   {object}  handler.Problem
+// @Failure      409   {object}  handler.Problem
+// @Failure      422   {object}  handler.Problem
+// @Failure      500   {object}  handler.Problem
 // @Router       /users [post]
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var user domain.User
 
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if !bindJSON(c, &user) {
 		return
 	}
 
 	if err := h.userService.CreateUser(c.Request.Context(), &user); err != nil {
-		if err == domain.ErrInvalidInput {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input or email already exists"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	user.Password = ""
 
+	c.Header("ETag", etag(user.Version))
 	c.JSON(http.StatusCreated, user)
 }
 
@@ -121,64 +129,131 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 // @Tags         users
 // @Accept       json
 // @Produce      json
-// @Param        id    path      string       true  "User ID"
-// @Param        user  body      domain.User  true  "User information"
+// @Param        id        path      string       true  "User ID"
+// @Param        If-Match  header    string       true  "ETag of the version being replaced"
+// @Param        user      body      domain.User  true  "User information"
 // @Success      200   {object}  domain.User
-// @Failure      400   {object}  handler.ErrorResponse
-// @Failure      404   {object}  handler.ErrorResponse
-// @Failure      500   {object}  handler.ErrorResponse
+// @Header       200   {string}  ETag  "Current version of the user"
+// @Failure      400   {object}  handler.Problem
+// @Failure      404   {object}  handler.Problem
+// @Failure      409   {object}  handler.Problem
+// @Failure      412   {object}  handler.Problem
+// @Failure      428   {object}  handler.Problem
+// @Failure      500   {object}  handler.Problem
 // @Router       /users/{id} [put]
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	id := c.Param("id")
 
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
 	var user domain.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if !bindJSON(c, &user) {
 		return
 	}
+	user.Version = version
 
 	if err := h.userService.UpdateUser(c.Request.Context(), id, &user); err != nil {
-		if err == domain.ErrUserNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
-			return
-		}
-		if err == domain.ErrInvalidInput {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input or email already exists"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	updatedUser, err := h.userService.GetUser(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
+	c.Header("ETag", etag(updatedUser.Version))
 	c.JSON(http.StatusOK, updatedUser)
 }
 
+// PatchUser godoc
+// @Summary      Patch a user
+// @Description  Partially update a user using JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902). Absent fields are kept and null clears a field.
+// @Tags         users
+// @Accept       application/merge-patch+json,application/json-patch+json
+// @Produce      json
+// @Param        id        path      string                 true  "User ID"
+// @Param        If-Match  header    string                 true  "ETag of the version being patched"
+// @Param        patch     body      dto.UserPatchDocument  true  "Patch document"
+// @Success      200    {object}  domain.User
+// @Header       200    {string}  ETag  "Current version of the user"
+// @Failure      400    {object}  handler.Problem
+// @Failure      404    {object}  handler.Problem
+// @Failure      409    {object}  handler.Problem
+// @Failure      412    {object}  handler.Problem
+// @Failure      415    {object}  handler.Problem
+// @Failure      428    {object}  handler.Problem
+// @Failure      500    {object}  handler.Problem
+// @Router       /users/{id} [patch]
+func (h *UserHandler) PatchUser(c *gin.Context) {
+	id := c.Param("id")
+
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	patch, err := readPatch(c)
+	if err != nil {
+		if errors.Is(err, errUnsupportedPatch) {
+			respondProblem(c, http.StatusUnsupportedMediaType, codeUnsupportedMediaType, err.Error())
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	user, err := h.userService.PatchUser(c.Request.Context(), id, version, func(user *domain.User) error {
+		doc := dto.UserPatchDocument{
+			Name:  user.Name,
+			Email: user.Email,
+		}
+		if err := patchDocument(patch, &doc); err != nil {
+			return err
+		}
+
+		user.Name = doc.Name
+		user.Email = doc.Email
+		user.Password = doc.Password
+		return nil
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("ETag", etag(user.Version))
+	c.JSON(http.StatusOK, user)
+}
+
 // DeleteUser godoc
 // @Summary      Delete a user
 // @Description  Remove a user by ID
 // @Tags         users
 // @Accept       json
 // @Produce      json
-// @Param        id   path      string  true  "User ID"
+// @Param        id        path      string  true  "User ID"
+// @Param        If-Match  header    string  true  "ETag of the version being deleted"
 // @Success      204  {object}  nil
-// @Failure      404  {object}  handler.ErrorResponse
-// @Failure      500  {object}  handler.ErrorResponse
+// @Failure      404  {object}  handler.Problem
+// @Failure      412  {object}  handler.Problem
+// @Failure      428  {object}  handler.Problem
+// @Failure      500  {object}  handler.Problem
 // @Router       /users/{id} [delete]
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	id := c.Param("id")
 
-	if err := h.userService.DeleteUser(c.Request.Context(), id); err != nil {
-		if err == domain.ErrUserNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.DeleteUser(c.Request.Context(), id, version); err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -193,22 +268,36 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 // @Produce      json
 // @Param        credentials  body  dto.UserLoginRequest  true  "Login credentials"
 // @Success      200  {object}  object{token=string,user=dto.UserResponse}
-// @Failure      400  {object}  handler.ErrorResponse
-// @Failure      401  {object}  handler.ErrorResponse
+// @Failure      400  {object}  handler.Problem
+// @Failure      401  {object}  handler.Problem
+// @Failure      403  {object}  handler.Problem
+// @Failure      500  {object}  handler.Problem
 // @Router       /login [post]
 func (h *UserHandler) Login(c *gin.Context) {
 	var login dto.UserLoginRequest
 
-	if err := c.ShouldBindJSON(&login); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if !bindJSON(c, &login) {
 		return
 	}
 
 	user, err := h.userService.Authenticate(c.Request.Context(), login.Email, login.Password)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+	switch {
+	case errors.Is(err, domain.ErrUserDisabled):
+		metrics.LoginsTotal.WithLabelValues(metrics.LoginDisabled).Inc()
+		respondError(c, err)
+		return
+	case errors.Is(err, domain.ErrUserNotFound), errors.Is(err, domain.ErrInvalidInput):
+		// Email desconhecido e senha errada têm a mesma resposta, para não
+		// revelar quais emails estão cadastrados
+		metrics.LoginsTotal.WithLabelValues(metrics.LoginFailed).Inc()
+		respondProblem(c, http.StatusUnauthorized, codeInvalidCredentials, "invalid credentials")
+		return
+	case err != nil:
+		respondError(c, err)
 		return
 	}
+	metrics.LoginsTotal.WithLabelValues(metrics.LoginSucceeded).Inc()
+	c.Set(userIDKey, user.ID)
 
 	userResponse := dto.UserResponse{
 		ID:        user.ID,
@@ -235,16 +324,18 @@ func (h *UserHandler) Login(c *gin.Context) {
 // @Tags         auth
 // @Accept       json
 // @Produce      json
-// @Param        registration  body  dto.UserRegistrationRequest  true  "User registration data"
+// @Param        Idempotency-Key  header  string                       false  "Key that makes retries of this request safe"
+// @Param        registration     body    dto.UserRegistrationRequest  true   "User registration data"
 // @Success      201  {object}  object{user=dto.UserResponse}
-// @Failure      400  {object}  handler.ErrorResponse
-// @Failure      500  {object}  handler.ErrorResponse
+// @Failure      400  {object}  handler.Problem
+// @Failure      409  {object}  handler.Problem
+// @Failure      422  {object}  handler.Problem
+// @Failure      500  {object}  handler.Problem
 // @Router       /register [post]
 func (h *UserHandler) Register(c *gin.Context) {
 	var registration dto.UserRegistrationRequest
 
-	if err := c.ShouldBindJSON(&registration); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+	if !bindJSON(c, &registration) {
 		return
 	}
 
@@ -255,13 +346,11 @@ func (h *UserHandler) Register(c *gin.Context) {
 	}
 
 	if err := h.userService.CreateUser(c.Request.Context(), user); err != nil {
-		if err == domain.ErrInvalidInput {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input or email already exists"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
+	metrics.UsersRegisteredTotal.Inc()
+	c.Set(userIDKey, user.ID)
 
 	userResponse := dto.UserResponse{
 		ID:        user.ID,
@@ -280,16 +369,19 @@ func (h *UserHandler) Register(c *gin.Context) {
 	c.JSON(http.StatusCreated, response)
 }
 
-func (h *UserHandler) RegisterRoutes(router *gin.RouterGroup) {
+// RegisterRoutes registra as rotas de usuários e autenticação. createMiddleware
+// é aplicado somente às rotas de criação (ex.: idempotência).
+func (h *UserHandler) RegisterRoutes(router *gin.RouterGroup, createMiddleware ...gin.HandlerFunc) {
 	users := router.Group("/users")
 	{
 		users.GET("/:id", h.GetUser)
 		users.GET("", h.ListUsers)
-		users.POST("", h.CreateUser)
+		users.POST("", append(createMiddleware, h.CreateUser)...)
 		users.PUT("/:id", h.UpdateUser)
+		users.PATCH("/:id", h.PatchUser)
 		users.DELETE("/:id", h.DeleteUser)
 	}
 
 	router.POST("/login", h.Login)
-	router.POST("/register", h.Register)
+	router.POST("/register", append(createMiddleware, h.Register)...)
 }
@@ -0,0 +1,105 @@
+package imaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// UserAgent identifica a aplicação ao baixar capas externas
+const UserAgent = "BookFlow/1.0"
+
+// ErrInvalidSource indica que a URL da capa não vai funcionar sem ser
+// alterada: esquema inválido, endereço interno, 404, arquivo grande demais
+var ErrInvalidSource = errors.New("cover source cannot be used")
+
+var errPrivateAddress = errors.New("address is not public")
+
+// Fetcher baixa capas de URLs externas
+type Fetcher struct {
+	client *http.Client
+}
+
+// NewFetcher cria um Fetcher que só se conecta a endereços públicos: as URLs
+// das capas vêm dos usuários e não podem ser usadas para alcançar serviços
+// da rede interna
+func NewFetcher(timeout time.Duration) *Fetcher {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !publicIP(ip) {
+				return fmt.Errorf("%w: %s", errPrivateAddress, host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &Fetcher{client: &http.Client{Timeout: timeout, Transport: transport}}
+}
+
+// NewFetcherWithClient usa client sem restrições de endereço (ex.: testes)
+func NewFetcherWithClient(client *http.Client) *Fetcher {
+	return &Fetcher{client: client}
+}
+
+// Fetch baixa até maxSize bytes de rawURL
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string, maxSize int64) ([]byte, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("%w: unsupported URL %q", ErrInvalidSource, rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSource, err)
+	}
+	req.Header.Set("Accept", "image/*")
+	req.Header.Set("User-Agent", UserAgent)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		if errors.Is(err, errPrivateAddress) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSource, err)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusRequestTimeout:
+		return nil, fmt.Errorf("%w: status %d", ErrInvalidSource, resp.StatusCode)
+	default:
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%w: larger than %d bytes", ErrInvalidSource, maxSize)
+	}
+
+	return data, nil
+}
+
+func publicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast())
+}
@@ -0,0 +1,67 @@
+package imaging
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newImageServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cover.png":
+			w.Write([]byte("\x89PNG\r\n\x1a\n"))
+		case "/large.png":
+			w.Write([]byte(strings.Repeat("x", 100)))
+		case "/busy.png":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetch(t *testing.T) {
+	server := newImageServer(t)
+	fetcher := NewFetcherWithClient(server.Client())
+	ctx := context.Background()
+
+	data, err := fetcher.Fetch(ctx, server.URL+"/cover.png", 50)
+	if err != nil || string(data) != "\x89PNG\r\n\x1a\n" {
+		t.Errorf("Fetch() = %q, %v", data, err)
+	}
+
+	for _, path := range []string{"/missing.png", "/large.png"} {
+		if _, err := fetcher.Fetch(ctx, server.URL+path, 50); !errors.Is(err, ErrInvalidSource) {
+			t.Errorf("Fetch(%s) error = %v, want ErrInvalidSource", path, err)
+		}
+	}
+
+	// Falhas temporárias podem ser tentadas de novo
+	if _, err := fetcher.Fetch(ctx, server.URL+"/busy.png", 50); err == nil || errors.Is(err, ErrInvalidSource) {
+		t.Errorf("Fetch(/busy.png) error = %v, want a temporary failure", err)
+	}
+
+	for _, url := range []string{"file:///etc/passwd", "ftp://example.com/cover.png", "not a url", "http:///cover.png"} {
+		if _, err := fetcher.Fetch(ctx, url, 50); !errors.Is(err, ErrInvalidSource) {
+			t.Errorf("Fetch(%q) error = %v, want ErrInvalidSource", url, err)
+		}
+	}
+}
+
+func TestFetchRejectsPrivateAddresses(t *testing.T) {
+	server := newImageServer(t)
+	fetcher := NewFetcher(time.Second)
+
+	for _, url := range []string{server.URL + "/cover.png", "http://localhost:1/cover.png", "http://[::1]:1/cover.png"} {
+		if _, err := fetcher.Fetch(context.Background(), url, 50); !errors.Is(err, ErrInvalidSource) {
+			t.Errorf("Fetch(%s) error = %v, want ErrInvalidSource", url, err)
+		}
+	}
+}
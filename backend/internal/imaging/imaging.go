@@ -0,0 +1,144 @@
+// Package imaging gera as versões redimensionadas das capas. As imagens são
+// sempre decodificadas e codificadas de novo, o que descarta EXIF e qualquer
+// outro metadado do arquivo original.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/HugoSmits86/nativewebp"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+// Limite de pixels da imagem de origem, para que um arquivo pequeno não
+// ocupe gigabytes de memória ao ser decodificado
+const maxSourcePixels = 40_000_000
+
+const jpegQuality = 82
+
+var ErrUnsupportedImage = errors.New("unsupported image")
+
+// Size é a caixa em que uma versão deve caber, mantendo a proporção
+type Size struct {
+	Width  int
+	Height int
+}
+
+// Encoded é uma versão codificada da imagem
+type Encoded struct {
+	Data        []byte
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+}
+
+// Decode lê uma imagem JPEG, PNG, GIF ou WebP e aplica a orientação EXIF,
+// para que a versão sem metadados continue de pé
+func Decode(data []byte) (image.Image, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxSourcePixels {
+		return nil, fmt.Errorf("%w: %dx%d pixels", ErrUnsupportedImage, config.Width, config.Height)
+	}
+
+	var img image.Image
+	switch format {
+	case "jpeg":
+		img, err = jpeg.Decode(bytes.NewReader(data))
+	case "png":
+		img, err = png.Decode(bytes.NewReader(data))
+	case "gif":
+		img, err = gif.Decode(bytes.NewReader(data))
+	case "webp":
+		img, err = webp.Decode(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedImage, format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+
+	if format == "jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+	return img, nil
+}
+
+// Resize reduz a imagem para caber em size, sem nunca ampliá-la
+func Resize(img image.Image, size Size) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	scale := min(float64(size.Width)/float64(width), float64(size.Height)/float64(height))
+	if scale >= 1 {
+		return img
+	}
+
+	target := image.Rect(0, 0, max(1, int(float64(width)*scale+0.5)), max(1, int(float64(height)*scale+0.5)))
+	resized := image.NewNRGBA(target)
+	xdraw.CatmullRom.Scale(resized, target, img, bounds, draw.Src, nil)
+	return resized
+}
+
+// Encode usa JPEG para imagens opacas e WebP sem perdas para as que têm
+// transparência, que o JPEG não suporta
+func Encode(img image.Image) (*Encoded, error) {
+	var buf bytes.Buffer
+	encoded := &Encoded{Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}
+
+	if opaque(img) {
+		if err := jpeg.Encode(&buf, flatten(img), &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+		encoded.ContentType, encoded.Extension = "image/jpeg", ".jpg"
+	} else {
+		if err := nativewebp.Encode(&buf, img, nil); err != nil {
+			return nil, err
+		}
+		encoded.ContentType, encoded.Extension = "image/webp", ".webp"
+	}
+
+	encoded.Data = buf.Bytes()
+	return encoded, nil
+}
+
+func opaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// flatten converte imagens paletadas (GIF) e afins para RGBA, que o
+// codificador JPEG trata melhor
+func flatten(img image.Image) image.Image {
+	switch img.(type) {
+	case *image.YCbCr, *image.Gray, *image.RGBA, *image.NRGBA:
+		return img
+	}
+
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
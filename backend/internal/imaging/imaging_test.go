@@ -0,0 +1,151 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+// solid cria uma imagem de width x height com a metade esquerda vermelha
+func solid(width, height int, alpha uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBA{B: 255, A: alpha}
+			if x < width/2 {
+				c = color.NRGBA{R: 255, A: alpha}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// withOrientation insere um segmento EXIF com a orientação logo após o SOI
+func withOrientation(t *testing.T, jpg []byte, orientation uint16) []byte {
+	t.Helper()
+
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01")
+	entry := make([]byte, 12)
+	binary.BigEndian.PutUint16(entry[0:], 0x0112)
+	binary.BigEndian.PutUint16(entry[2:], 3)
+	binary.BigEndian.PutUint32(entry[4:], 1)
+	binary.BigEndian.PutUint16(entry[8:], orientation)
+	tiff = append(append(tiff, entry...), 0, 0, 0, 0)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	return append(append(append([]byte{}, jpg[:2]...), segment...), jpg[2:]...)
+}
+
+func TestDecodeAppliesOrientation(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, solid(40, 20, 255), &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := Decode(withOrientation(t, buf.Bytes(), 6))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if got := img.Bounds().Size(); got != image.Pt(20, 40) {
+		t.Fatalf("Decode() size = %v, want 20x40", got)
+	}
+	// Girada 90° no sentido horário: o lado esquerdo (vermelho) fica em cima
+	if r, _, b, _ := img.At(10, 5).RGBA(); r < b {
+		t.Errorf("top of the rotated image is not red")
+	}
+	if r, _, b, _ := img.At(10, 35).RGBA(); r > b {
+		t.Errorf("bottom of the rotated image is not blue")
+	}
+}
+
+func TestDecodeRejectsInvalidImages(t *testing.T) {
+	for _, data := range [][]byte{nil, []byte("not an image"), []byte("\x89PNG\r\n\x1a\ntruncated")} {
+		if _, err := Decode(data); !errors.Is(err, ErrUnsupportedImage) {
+			t.Errorf("Decode(%q) error = %v, want ErrUnsupportedImage", data, err)
+		}
+	}
+}
+
+func TestResize(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		size          Size
+		want          image.Point
+	}{
+		{"portrait", 600, 900, Size{200, 300}, image.Pt(200, 300)},
+		{"wide", 1000, 500, Size{200, 300}, image.Pt(200, 100)},
+		{"tall", 100, 1000, Size{200, 300}, image.Pt(30, 300)},
+		{"smaller than the box", 150, 100, Size{200, 300}, image.Pt(150, 100)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Resize(solid(tt.width, tt.height, 255), tt.size).Bounds().Size(); got != tt.want {
+				t.Errorf("Resize() size = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	photo, err := Encode(solid(30, 20, 255))
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if photo.ContentType != "image/jpeg" || photo.Extension != ".jpg" {
+		t.Errorf("opaque image encoded as %s", photo.ContentType)
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(photo.Data)); err != nil {
+		t.Errorf("invalid JPEG: %v", err)
+	}
+
+	transparent, err := Encode(solid(30, 20, 128))
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if transparent.ContentType != "image/webp" || transparent.Extension != ".webp" {
+		t.Errorf("transparent image encoded as %s", transparent.ContentType)
+	}
+	decoded, err := webp.Decode(bytes.NewReader(transparent.Data))
+	if err != nil {
+		t.Fatalf("invalid WebP: %v", err)
+	}
+	if _, _, _, a := decoded.At(5, 5).RGBA(); a == 0xffff {
+		t.Errorf("WebP lost the transparency")
+	}
+}
+
+func TestEncodeStripsMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	png.Encode(&buf, solid(10, 10, 255))
+	var jpg bytes.Buffer
+	img, _ := png.Decode(&buf)
+	jpeg.Encode(&jpg, img, nil)
+
+	source := withOrientation(t, jpg.Bytes(), 1)
+	img, err := Decode(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := Encode(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(encoded.Data, []byte("Exif")) {
+		t.Errorf("encoded image still has EXIF data")
+	}
+}
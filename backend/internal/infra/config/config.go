@@ -1,59 +1,422 @@
 package config
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Env      string
+	Server      ServerConfig
+	Database    DatabaseConfig
+	Cache       CacheConfig
+	Idempotency IdempotencyConfig
+	Metadata    MetadataConfig
+	Storage     StorageConfig
+	Log         LogConfig
+	Tracing     TracingConfig
+	Health      HealthConfig
+	Env         string
+
+	// Valores efetivos por chave, usados por WriteYAML
+	values map[string]any
 }
 
 type ServerConfig struct {
 	Address string
+	// Endereço público da API, usado nas URLs das capas enviadas
+	PublicURL string
+	// Tempos máximos para ler os cabeçalhos, ler a requisição inteira,
+	// escrever a resposta e manter uma conexão ociosa
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// Tempo para as requisições em andamento terminarem no desligamento
+	ShutdownTimeout time.Duration
+	MaxHeaderBytes  int
+	// Habilita HTTP/2: via ALPN com TLS ou h2c (conhecimento prévio) sem TLS
+	HTTP2 bool
+	// Comprime com brotli ou gzip as respostas de texto a partir de
+	// CompressionMinSize bytes
+	Compression        bool
+	CompressionMinSize int
+	TLS                TLSConfig
+}
+
+type TLSConfig struct {
+	// Certificado e chave em PEM; vazios servem HTTP sem TLS
+	CertFile string
+	KeyFile  string
+	// Intervalo entre as verificações de mudança dos arquivos do certificado
+	// (zero desativa a recarga)
+	ReloadInterval time.Duration
 }
 
 type DatabaseConfig struct {
+	// Banco usado: postgres ou sqlite
+	Driver string
+	// Conexão com o Postgres
 	Host     string
 	Port     string
 	User     string
 	Password string
 	DBName   string
 	SSLMode  string
+	// Réplicas de leitura do Postgres (host ou host:porta), com o mesmo
+	// usuário, senha e banco do primário
+	Replicas []string
+	// Arquivo do banco SQLite
+	SQLitePath string
+	// Tamanho do pool de conexões; MaxOpenConns zero não limita
+	MaxOpenConns int
+	MaxIdleConns int
+	// Tempo máximo de vida e de ociosidade de uma conexão (zero não limita)
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// Tentativas de conexão ao iniciar e a espera antes da segunda, que
+	// dobra a cada nova tentativa
+	ConnectAttempts int
+	ConnectBackoff  time.Duration
+	// Aplica as migrações pendentes ao iniciar o servidor
+	MigrateOnStart bool
+}
+
+type CacheConfig struct {
+	// Onde ficam os livros em cache: none (sem cache), memory ou redis
+	Backend string
+	// Tempo pelo qual um livro ou uma listagem fica em cache
+	TTL time.Duration
+	// Quantidade máxima de entradas do cache em memória
+	Size  int
+	Redis RedisConfig
+}
+
+type RedisConfig struct {
+	Addr     string
+	Password string
+	DB       int
+}
+
+type LogConfig struct {
+	// debug, info, warn ou error
+	Level string
+	// json ou text
+	Format string
+}
+
+type TracingConfig struct {
+	// Para onde os spans são enviados: none, stdout ou otlp
+	Exporter    string
+	ServiceName string
+	// Endereço do coletor OTLP/HTTP (host:porta); vazio usa as variáveis
+	// OTEL_EXPORTER_OTLP_* ou localhost:4318
+	OTLPEndpoint string
+	OTLPInsecure bool
+	// Fração das novas traces que é amostrada (0 a 1); traces recebidas de
+	// outro serviço seguem a decisão de quem as iniciou
+	SampleRatio float64
+}
+
+type HealthConfig struct {
+	// Token (Authorization: Bearer) que libera o relatório detalhado de /readyz
+	Token string
+	// Tempo máximo de cada verificação de /readyz
+	CheckTimeout time.Duration
 }
 
-func Load() (*Config, error) {
-	viper.SetConfigFile(".env")
+type IdempotencyConfig struct {
+	// Tempo pelo qual a resposta de uma Idempotency-Key é mantida
+	TTL time.Duration
+	// Tempo pelo qual uma requisição em andamento reserva a chave; depois
+	// dele, se a requisição não terminou (o processo caiu, por exemplo), uma
+	// nova tentativa pode assumi-la
+	LockTimeout time.Duration
+}
+
+type MetadataConfig struct {
+	// Catálogos consultados, em ordem: openlibrary, googlebooks ou fixture
+	Providers []string
+	// Diretório dos arquivos do catálogo fixture
+	FixturesDir       string
+	GoogleBooksAPIKey string
+	// Tempo máximo de cada consulta a um catálogo
+	Timeout time.Duration
+	// Tempo pelo qual as respostas dos catálogos ficam em cache
+	CacheTTL time.Duration
+}
+
+type StorageConfig struct {
+	// Onde os arquivos enviados são gravados: local ou s3
+	Backend string
+	// Diretório do backend local
+	Dir string
+	S3  S3Config
+	// Tamanho máximo, em bytes, de uma imagem de capa
+	MaxCoverSize int64
+	// Tempo máximo para baixar uma capa externa
+	CoverFetchTimeout time.Duration
+	// Intervalo entre as varreduras de capas sem versões redimensionadas
+	CoverSweepInterval time.Duration
+}
 
-	if err := viper.ReadInConfig(); err != nil {
-		viper.SetDefault("SERVER_PORT", "8080")
-		viper.SetDefault("DB_HOST", "localhost")
-		viper.SetDefault("DB_PORT", "5432")
-		viper.SetDefault("DB_USER", "postgres")
-		viper.SetDefault("DB_PASSWORD", "postgres")
-		viper.SetDefault("DB_NAME", "bookflow")
-		viper.SetDefault("DB_SSLMODE", "disable")
-		viper.SetDefault("ENV", "development")
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+type S3Config struct {
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+	Bucket    string
+	Region    string
+	UseSSL    bool
+}
+
+// Variável de ambiente com o caminho do arquivo YAML, quando -config não é usada
+const fileEnv = "CONFIG_FILE"
+
+// Arquivo no formato KEY=valor lido como variáveis de ambiente, se existir
+const dotenvFile = ".env"
+
+// Flags guarda as flags de configuração registradas em um flag.FlagSet
+type Flags struct {
+	set  *flag.FlagSet
+	file *string
+}
+
+// RegisterFlags registra em fs a flag -config e uma flag por opção, com o
+// mesmo nome da chave no YAML (ex.: -database.host)
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	flags := &Flags{
+		set:  fs,
+		file: fs.String("config", "", "YAML configuration file (env "+fileEnv+")"),
+	}
+	for _, opt := range options {
+		fs.String(opt.key, opt.def, fmt.Sprintf("%s (env %s)", opt.usage, opt.env))
+	}
+	return flags
+}
+
+// Load monta a configuração a partir das fontes, nesta ordem de precedência
+// crescente: valores padrão, arquivo YAML (-config ou CONFIG_FILE), variáveis
+// de ambiente (e o arquivo .env, que não sobrepõe as variáveis definidas) e
+// flags. Uma variável NOME_FILE é lida do arquivo indicado, como nos secrets
+// do Docker. flags pode ser nil. Valores inválidos são todos reportados no
+// erro devolvido.
+func Load(flags *Flags) (*Config, error) {
+	env, err := newEnvironment(dotenvFile)
+	if err != nil {
+		return nil, err
+	}
+
+	v := viper.New()
+	for _, opt := range options {
+		v.SetDefault(opt.key, opt.def)
+	}
+
+	file, _, err := env.lookup(fileEnv)
+	if err != nil {
+		return nil, err
+	}
+	if flags != nil && *flags.file != "" {
+		file = *flags.file
+	}
+	if file != "" {
+		if err := mergeFile(v, file); err != nil {
 			return nil, err
 		}
 	}
 
-	viper.AutomaticEnv()
+	for _, opt := range options {
+		value, ok, err := env.lookup(opt.env)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			v.Set(opt.key, value)
+		}
+	}
+
+	if flags != nil {
+		flags.set.Visit(func(f *flag.Flag) {
+			if _, ok := optionsByKey[f.Name]; ok {
+				v.Set(f.Name, f.Value.String())
+			}
+		})
+	}
+
+	cfg, err := build(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
 
-	return &Config{
+// mergeFile aplica o arquivo YAML sobre os valores padrão, rejeitando chaves
+// desconhecidas para que um erro de digitação não passe despercebido
+func mergeFile(v *viper.Viper, file string) error {
+	fv := viper.New()
+	fv.SetConfigFile(file)
+	fv.SetConfigType("yaml")
+	if err := fv.ReadInConfig(); err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	var unknown []string
+	for _, key := range fv.AllKeys() {
+		if _, ok := optionsByKey[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("config file %s: unknown keys %s", file, strings.Join(unknown, ", "))
+	}
+
+	return v.MergeConfigMap(fv.AllSettings())
+}
+
+func build(v *viper.Viper) (*Config, error) {
+	p := &parser{v: v, values: make(map[string]any, len(options))}
+
+	cfg := &Config{
 		Server: ServerConfig{
-			Address: ":" + viper.GetString("SERVER_PORT"),
+			Address:            ":" + p.string("server.port"),
+			PublicURL:          strings.TrimSuffix(p.string("server.public_url"), "/"),
+			ReadHeaderTimeout:  p.duration("server.read_header_timeout"),
+			ReadTimeout:        p.duration("server.read_timeout"),
+			WriteTimeout:       p.duration("server.write_timeout"),
+			IdleTimeout:        p.duration("server.idle_timeout"),
+			ShutdownTimeout:    p.duration("server.shutdown_timeout"),
+			MaxHeaderBytes:     p.int("server.max_header_bytes"),
+			HTTP2:              p.bool("server.http2"),
+			Compression:        p.bool("server.compression"),
+			CompressionMinSize: p.int("server.compression_min_size"),
+			TLS: TLSConfig{
+				CertFile:       p.string("server.tls.cert_file"),
+				KeyFile:        p.string("server.tls.key_file"),
+				ReloadInterval: p.duration("server.tls.reload_interval"),
+			},
 		},
 		Database: DatabaseConfig{
-			Host:     viper.GetString("DB_HOST"),
-			Port:     viper.GetString("DB_PORT"),
-			User:     viper.GetString("DB_USER"),
-			Password: viper.GetString("DB_PASSWORD"),
-			DBName:   viper.GetString("DB_NAME"),
-			SSLMode:  viper.GetString("DB_SSLMODE"),
-		},
-		Env: viper.GetString("ENV"),
-	}, nil
+			Driver:          p.string("database.driver"),
+			SQLitePath:      p.string("database.sqlite_path"),
+			Host:            p.string("database.host"),
+			Port:            p.string("database.port"),
+			User:            p.string("database.user"),
+			Password:        p.string("database.password"),
+			DBName:          p.string("database.name"),
+			SSLMode:         p.string("database.sslmode"),
+			Replicas:        p.list("database.replicas"),
+			MaxOpenConns:    p.int("database.max_open_conns"),
+			MaxIdleConns:    p.int("database.max_idle_conns"),
+			ConnMaxLifetime: p.duration("database.conn_max_lifetime"),
+			ConnMaxIdleTime: p.duration("database.conn_max_idle_time"),
+			ConnectAttempts: p.int("database.connect_attempts"),
+			ConnectBackoff:  p.duration("database.connect_backoff"),
+			MigrateOnStart:  p.bool("database.migrate_on_start"),
+		},
+		Idempotency: IdempotencyConfig{
+			TTL:         p.duration("idempotency.ttl"),
+			LockTimeout: p.duration("idempotency.lock_timeout"),
+		},
+		Metadata: MetadataConfig{
+			Providers:         p.list("metadata.providers"),
+			FixturesDir:       p.string("metadata.fixtures_dir"),
+			GoogleBooksAPIKey: p.string("metadata.google_books_api_key"),
+			Timeout:           p.duration("metadata.timeout"),
+			CacheTTL:          p.duration("metadata.cache_ttl"),
+		},
+		Cache: CacheConfig{
+			Backend: p.string("cache.backend"),
+			TTL:     p.duration("cache.ttl"),
+			Size:    p.int("cache.size"),
+			Redis: RedisConfig{
+				Addr:     p.string("cache.redis.addr"),
+				Password: p.string("cache.redis.password"),
+				DB:       p.int("cache.redis.db"),
+			},
+		},
+		Storage: StorageConfig{
+			Backend: p.string("storage.backend"),
+			Dir:     p.string("storage.dir"),
+			S3: S3Config{
+				Endpoint:  p.string("storage.s3.endpoint"),
+				AccessKey: p.string("storage.s3.access_key"),
+				SecretKey: p.string("storage.s3.secret_key"),
+				Bucket:    p.string("storage.s3.bucket"),
+				Region:    p.string("storage.s3.region"),
+				UseSSL:    p.bool("storage.s3.use_ssl"),
+			},
+			MaxCoverSize:       int64(p.int("storage.max_cover_size")),
+			CoverFetchTimeout:  p.duration("storage.cover_fetch_timeout"),
+			CoverSweepInterval: p.duration("storage.cover_sweep_interval"),
+		},
+		Log: LogConfig{
+			Level:  p.string("log.level"),
+			Format: p.string("log.format"),
+		},
+		Tracing: TracingConfig{
+			Exporter:     p.string("tracing.exporter"),
+			ServiceName:  p.string("tracing.service_name"),
+			OTLPEndpoint: p.string("tracing.otlp_endpoint"),
+			OTLPInsecure: p.bool("tracing.otlp_insecure"),
+			SampleRatio:  p.float("tracing.sample_ratio"),
+		},
+		Health: HealthConfig{
+			Token:        p.string("health.token"),
+			CheckTimeout: p.duration("health.check_timeout"),
+		},
+		Env:    p.string("env"),
+		values: p.values,
+	}
+
+	return cfg, errors.Join(p.errs...)
+}
+
+// WriteYAML escreve a configuração efetiva no formato do arquivo de
+// configuração, com os valores secretos substituídos por [REDACTED]
+func (c *Config) WriteYAML(w io.Writer) error {
+	root := make(map[string]any)
+	for _, opt := range options {
+		value := c.values[opt.key]
+		if opt.secret && value != "" {
+			value = redacted
+		}
+
+		node := root
+		path := strings.Split(opt.key, ".")
+		for _, name := range path[:len(path)-1] {
+			child, ok := node[name].(map[string]any)
+			if !ok {
+				child = make(map[string]any)
+				node[name] = child
+			}
+			node = child
+		}
+		node[path[len(path)-1]] = value
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// splitList separa uma lista de valores separados por vírgula
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile(".env", []byte("DB_NAME=from_dotenv\nLOG_FORMAT=text\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	file := writeFile(t, "config.yaml", `
+database:
+  user: from_file
+  host: db.internal
+  max_open_conns: 50
+server:
+  port: 9090
+metadata:
+  providers: [fixture]
+log:
+  format: json
+`)
+	t.Setenv("CONFIG_FILE", file)
+	t.Setenv("DB_HOST", "db.env")
+	t.Setenv("LOG_FORMAT", "json")
+	t.Setenv("SERVER_PORT", "7070")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := RegisterFlags(fs)
+	if err := fs.Parse([]string{"-server.port", "6060", "migrate", "up"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(flags)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	checks := []struct {
+		name      string
+		got, want any
+	}{
+		{"default", cfg.Idempotency.TTL, 24 * time.Hour},
+		{"default duration", cfg.Idempotency.LockTimeout, time.Minute},
+		{"file over default", cfg.Database.User, "from_file"},
+		{"file list", cfg.Metadata.Providers, []string{"fixture"}},
+		{"file int", cfg.Database.MaxOpenConns, 50},
+		{"dotenv over default", cfg.Database.DBName, "from_dotenv"},
+		{"env over file", cfg.Database.Host, "db.env"},
+		{"env over dotenv", cfg.Log.Format, "json"},
+		{"flag over env", cfg.Server.Address, ":6060"},
+	}
+	for _, check := range checks {
+		if fmt.Sprint(check.got) != fmt.Sprint(check.want) {
+			t.Errorf("%s = %v, want %v", check.name, check.got, check.want)
+		}
+	}
+	if args := fs.Args(); len(args) != 2 || args[0] != "migrate" {
+		t.Errorf("remaining args = %v, want [migrate up]", args)
+	}
+}
+
+func TestLoadSecretFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("DB_USER", "app")
+	t.Setenv("DB_PASSWORD_FILE", writeFile(t, "db_password", "s3cret\n"))
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Database.Password != "s3cret" {
+		t.Errorf("Password = %q, want the file content without the newline", cfg.Database.Password)
+	}
+
+	t.Setenv("DB_PASSWORD", "other")
+	if _, err := Load(nil); err == nil || !strings.Contains(err.Error(), "DB_PASSWORD_FILE") {
+		t.Errorf("Load() with DB_PASSWORD and DB_PASSWORD_FILE error = %v", err)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("DB_USER", "")
+	t.Setenv("SERVER_PORT", "http")
+	t.Setenv("METADATA_TIMEOUT", "5")
+	t.Setenv("LOG_FORMAT", "xml")
+	t.Setenv("DB_MAX_OPEN_CONNS", "2")
+	t.Setenv("SERVER_COMPRESSION_MIN_SIZE", "-1")
+
+	_, err := Load(nil)
+	if err == nil {
+		t.Fatal("Load() error = nil")
+	}
+	// Um número sem unidade é rejeitado antes da validação
+	if !strings.Contains(err.Error(), `metadata.timeout (METADATA_TIMEOUT): invalid duration "5"`) {
+		t.Errorf("Load() error = %v, want the invalid duration", err)
+	}
+
+	t.Setenv("METADATA_TIMEOUT", "5s")
+	_, err = Load(nil)
+	for _, want := range []string{"server.port", "database.user is required", "log.format", "database.max_idle_conns must not exceed", "server.compression_min_size"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Load() error = %v, want it to mention %q", err, want)
+		}
+	}
+}
+
+func TestLoadUnknownFileKey(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("CONFIG_FILE", writeFile(t, "config.yaml", "database:\n  usr: app\n"))
+
+	if _, err := Load(nil); err == nil || !strings.Contains(err.Error(), "database.usr") {
+		t.Errorf("Load() error = %v, want the unknown key", err)
+	}
+}
+
+func TestWriteYAMLRedactsSecrets(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("DB_USER", "app")
+	t.Setenv("DB_PASSWORD", "s3cret")
+	t.Setenv("S3_SECRET_KEY", "minio-secret")
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := cfg.WriteYAML(&out); err != nil {
+		t.Fatalf("WriteYAML() error = %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{"password: '[REDACTED]'", "secret_key: '[REDACTED]'", "user: app", "ttl: 24h\n", "google_books_api_key: \"\""} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteYAML() output does not contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "s3cret") || strings.Contains(got, "minio-secret") {
+		t.Errorf("WriteYAML() leaks a secret:\n%s", got)
+	}
+}
+
+func TestLoadSQLite(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_USER", "")
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v, want the Postgres options to be ignored", err)
+	}
+	if cfg.Database.SQLitePath != "data/bookflow.db" {
+		t.Errorf("SQLitePath = %q, want the default", cfg.Database.SQLitePath)
+	}
+
+	t.Setenv("SQLITE_PATH", "")
+	if _, err := Load(nil); err == nil || !strings.Contains(err.Error(), "database.sqlite_path") {
+		t.Errorf("Load() without a path error = %v", err)
+	}
+}
+
+func TestLoadReplicas(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("DB_USER", "app")
+	t.Setenv("DB_REPLICAS", "replica-1, replica-2:5433")
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if want := []string{"replica-1", "replica-2:5433"}; !slices.Equal(cfg.Database.Replicas, want) {
+		t.Errorf("Replicas = %q, want %q", cfg.Database.Replicas, want)
+	}
+
+	t.Setenv("DB_REPLICAS", "replica-1:pg")
+	if _, err := Load(nil); err == nil || !strings.Contains(err.Error(), "database.replicas must be host or host:port") {
+		t.Errorf("Load() with an invalid replica error = %v", err)
+	}
+
+	t.Setenv("DB_REPLICAS", "replica-1")
+	t.Setenv("DB_DRIVER", "sqlite")
+	if _, err := Load(nil); err == nil || !strings.Contains(err.Error(), "database.replicas are not supported") {
+		t.Errorf("Load() with SQLite replicas error = %v", err)
+	}
+}
+
+func TestLoadCache(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("DB_USER", "app")
+	t.Setenv("CACHE_BACKEND", "redis")
+	t.Setenv("REDIS_ADDR", "redis")
+
+	if _, err := Load(nil); err == nil || !strings.Contains(err.Error(), "cache.redis.addr") {
+		t.Errorf("Load() without the Redis port error = %v", err)
+	}
+
+	t.Setenv("REDIS_ADDR", "redis:6379")
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Cache.Redis.Addr != "redis:6379" || cfg.Cache.TTL != 30*time.Second {
+		t.Errorf("Cache = %+v, want the Redis address and the default ttl", cfg.Cache)
+	}
+}
@@ -0,0 +1,95 @@
+package config
+
+// option é uma configuração: a chave no arquivo YAML e na flag, a variável de
+// ambiente equivalente e o valor padrão
+type option struct {
+	key    string
+	env    string
+	def    string
+	secret bool
+	usage  string
+}
+
+var options = []option{
+	{key: "env", env: "ENV", def: "development", usage: "deployment environment name"},
+
+	{key: "server.port", env: "SERVER_PORT", def: "8080", usage: "HTTP port"},
+	{key: "server.public_url", env: "PUBLIC_URL", def: "http://localhost:8080", usage: "public URL of the API, used in cover URLs"},
+	{key: "server.read_header_timeout", env: "SERVER_READ_HEADER_TIMEOUT", def: "10s", usage: "maximum time to read request headers"},
+	{key: "server.read_timeout", env: "SERVER_READ_TIMEOUT", def: "5m", usage: "maximum time to read a request"},
+	{key: "server.write_timeout", env: "SERVER_WRITE_TIMEOUT", def: "5m", usage: "maximum time to write a response"},
+	{key: "server.idle_timeout", env: "SERVER_IDLE_TIMEOUT", def: "2m", usage: "maximum time to keep an idle connection"},
+	{key: "server.shutdown_timeout", env: "SERVER_SHUTDOWN_TIMEOUT", def: "30s", usage: "time for in-flight requests to finish on shutdown"},
+	{key: "server.max_header_bytes", env: "SERVER_MAX_HEADER_BYTES", def: "1048576", usage: "maximum size of request headers in bytes"},
+	{key: "server.http2", env: "SERVER_HTTP2", def: "true", usage: "enable HTTP/2 (h2c without TLS)"},
+	{key: "server.compression", env: "SERVER_COMPRESSION", def: "true", usage: "compress text responses with brotli or gzip"},
+	{key: "server.compression_min_size", env: "SERVER_COMPRESSION_MIN_SIZE", def: "1024", usage: "minimum response size in bytes to compress"},
+	{key: "server.tls.cert_file", env: "TLS_CERT_FILE", usage: "TLS certificate (PEM); empty serves plain HTTP"},
+	{key: "server.tls.key_file", env: "TLS_KEY_FILE", usage: "TLS private key (PEM)"},
+	{key: "server.tls.reload_interval", env: "TLS_RELOAD_INTERVAL", def: "1m", usage: "how often the certificate files are checked for changes"},
+
+	{key: "database.driver", env: "DB_DRIVER", def: "postgres", usage: "database: postgres or sqlite"},
+	{key: "database.sqlite_path", env: "SQLITE_PATH", def: "data/bookflow.db", usage: "SQLite database file"},
+	{key: "database.host", env: "DB_HOST", def: "localhost", usage: "PostgreSQL host"},
+	{key: "database.port", env: "DB_PORT", def: "5432", usage: "PostgreSQL port"},
+	{key: "database.user", env: "DB_USER", usage: "PostgreSQL user (required by postgres)"},
+	{key: "database.password", env: "DB_PASSWORD", secret: true, usage: "PostgreSQL password"},
+	{key: "database.name", env: "DB_NAME", def: "bookflow", usage: "PostgreSQL database"},
+	{key: "database.sslmode", env: "DB_SSLMODE", def: "disable", usage: "PostgreSQL sslmode"},
+	{key: "database.replicas", env: "DB_REPLICAS", usage: "PostgreSQL read replicas (host[:port]) for list and search queries"},
+	{key: "database.max_open_conns", env: "DB_MAX_OPEN_CONNS", def: "20", usage: "maximum open connections (0 is unlimited)"},
+	{key: "database.max_idle_conns", env: "DB_MAX_IDLE_CONNS", def: "5", usage: "maximum idle connections"},
+	{key: "database.conn_max_lifetime", env: "DB_CONN_MAX_LIFETIME", def: "0s", usage: "maximum lifetime of a connection (0 is unlimited)"},
+	{key: "database.conn_max_idle_time", env: "DB_CONN_MAX_IDLE_TIME", def: "0s", usage: "maximum idle time of a connection (0 is unlimited)"},
+	{key: "database.connect_attempts", env: "DB_CONNECT_ATTEMPTS", def: "5", usage: "connection attempts on start before giving up"},
+	{key: "database.connect_backoff", env: "DB_CONNECT_BACKOFF", def: "1s", usage: "wait before retrying to connect, doubled on each attempt"},
+	{key: "database.migrate_on_start", env: "MIGRATE_ON_START", def: "false", usage: "apply pending migrations on start"},
+
+	{key: "cache.backend", env: "CACHE_BACKEND", def: "none", usage: "book cache: none, memory or redis"},
+	{key: "cache.ttl", env: "CACHE_TTL", def: "30s", usage: "how long books and listings are cached"},
+	{key: "cache.size", env: "CACHE_SIZE", def: "1000", usage: "maximum entries of the memory cache"},
+	{key: "cache.redis.addr", env: "REDIS_ADDR", def: "localhost:6379", usage: "Redis host:port"},
+	{key: "cache.redis.password", env: "REDIS_PASSWORD", secret: true, usage: "Redis password"},
+	{key: "cache.redis.db", env: "REDIS_DB", def: "0", usage: "Redis database number"},
+
+	{key: "log.level", env: "LOG_LEVEL", def: "info", usage: "minimum log level: debug, info, warn or error"},
+	{key: "log.format", env: "LOG_FORMAT", def: "json", usage: "log format: json or text"},
+
+	{key: "tracing.exporter", env: "TRACING_EXPORTER", def: "none", usage: "span exporter: none, stdout or otlp"},
+	{key: "tracing.service_name", env: "TRACING_SERVICE_NAME", def: "bookflow-backend", usage: "service name reported in traces"},
+	{key: "tracing.otlp_endpoint", env: "OTLP_ENDPOINT", usage: "OTLP/HTTP collector host:port"},
+	{key: "tracing.otlp_insecure", env: "OTLP_INSECURE", def: "false", usage: "send OTLP without TLS"},
+	{key: "tracing.sample_ratio", env: "TRACING_SAMPLE_RATIO", def: "1.0", usage: "fraction of new traces that are sampled"},
+
+	{key: "health.token", env: "HEALTH_TOKEN", secret: true, usage: "bearer token that unlocks the detailed /readyz report"},
+	{key: "health.check_timeout", env: "HEALTH_CHECK_TIMEOUT", def: "2s", usage: "timeout of each /readyz check"},
+
+	{key: "idempotency.ttl", env: "IDEMPOTENCY_TTL", def: "24h", usage: "how long Idempotency-Key responses are kept"},
+	{key: "idempotency.lock_timeout", env: "IDEMPOTENCY_LOCK_TIMEOUT", def: "1m", usage: "how long an unfinished request holds its Idempotency-Key before a retry can take it over"},
+
+	{key: "metadata.providers", env: "METADATA_PROVIDERS", def: "openlibrary,googlebooks", usage: "metadata catalogs, in order: openlibrary, googlebooks or fixture"},
+	{key: "metadata.fixtures_dir", env: "METADATA_FIXTURES_DIR", def: "fixtures/metadata", usage: "directory of the fixture catalog"},
+	{key: "metadata.google_books_api_key", env: "GOOGLE_BOOKS_API_KEY", secret: true, usage: "Google Books API key"},
+	{key: "metadata.timeout", env: "METADATA_TIMEOUT", def: "5s", usage: "timeout of each catalog lookup"},
+	{key: "metadata.cache_ttl", env: "METADATA_CACHE_TTL", def: "24h", usage: "how long catalog responses are cached"},
+
+	{key: "storage.backend", env: "STORAGE_BACKEND", def: "local", usage: "blob storage: local or s3"},
+	{key: "storage.dir", env: "STORAGE_DIR", def: "data/blobs", usage: "directory of the local storage"},
+	{key: "storage.s3.endpoint", env: "S3_ENDPOINT", usage: "S3 endpoint host:port"},
+	{key: "storage.s3.access_key", env: "S3_ACCESS_KEY", secret: true, usage: "S3 access key"},
+	{key: "storage.s3.secret_key", env: "S3_SECRET_KEY", secret: true, usage: "S3 secret key"},
+	{key: "storage.s3.bucket", env: "S3_BUCKET", def: "bookflow", usage: "S3 bucket"},
+	{key: "storage.s3.region", env: "S3_REGION", def: "us-east-1", usage: "S3 region"},
+	{key: "storage.s3.use_ssl", env: "S3_USE_SSL", def: "false", usage: "connect to S3 over TLS"},
+	{key: "storage.max_cover_size", env: "COVER_MAX_SIZE", def: "5242880", usage: "maximum cover image size in bytes"},
+	{key: "storage.cover_fetch_timeout", env: "COVER_FETCH_TIMEOUT", def: "10s", usage: "timeout to download an external cover"},
+	{key: "storage.cover_sweep_interval", env: "COVER_SWEEP_INTERVAL", def: "5m", usage: "interval between sweeps for covers without variants"},
+}
+
+var optionsByKey = func() map[string]option {
+	byKey := make(map[string]option, len(options))
+	for _, opt := range options {
+		byKey[opt.key] = opt
+	}
+	return byKey
+}()
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// Valor exibido no lugar dos segredos
+const redacted = "[REDACTED]"
+
+// environment resolve as variáveis de ambiente. As do arquivo .env só valem
+// quando a variável não está definida no processo.
+type environment struct {
+	dotenv map[string]string
+}
+
+func newEnvironment(dotenvFile string) (*environment, error) {
+	env := &environment{dotenv: map[string]string{}}
+
+	if _, err := os.Stat(dotenvFile); errors.Is(err, fs.ErrNotExist) {
+		return env, nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(dotenvFile)
+	v.SetConfigType("env")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", dotenvFile, err)
+	}
+	for key, value := range v.AllSettings() {
+		env.dotenv[strings.ToUpper(key)] = cast.ToString(value)
+	}
+	return env, nil
+}
+
+// lookup devolve o valor de name ou, se NAME_FILE estiver definida, o conteúdo
+// do arquivo indicado, sem a quebra de linha final
+func (e *environment) lookup(name string) (string, bool, error) {
+	value, ok := e.get(name)
+	path, fromFile := e.get(name + "_FILE")
+
+	switch {
+	case ok && fromFile:
+		return "", false, fmt.Errorf("both %s and %s_FILE are set", name, name)
+	case fromFile:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("%s_FILE: %w", name, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), true, nil
+	}
+	return value, ok, nil
+}
+
+func (e *environment) get(name string) (string, bool) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true
+	}
+	value, ok := e.dotenv[name]
+	return value, ok
+}
+
+// parser converte os valores de cada chave, acumulando os erros para que
+// todos os valores inválidos sejam reportados de uma vez
+type parser struct {
+	v      *viper.Viper
+	values map[string]any
+	errs   []error
+}
+
+func (p *parser) fail(key string, kind string) {
+	p.errs = append(p.errs, fmt.Errorf("%s (%s): invalid %s %q", key, optionsByKey[key].env, kind, cast.ToString(p.v.Get(key))))
+}
+
+func (p *parser) string(key string) string {
+	value, err := cast.ToStringE(p.v.Get(key))
+	if err != nil {
+		p.fail(key, "string")
+	}
+	p.values[key] = value
+	return value
+}
+
+func (p *parser) int(key string) int {
+	value, err := cast.ToIntE(p.v.Get(key))
+	if err != nil {
+		p.fail(key, "integer")
+	}
+	p.values[key] = value
+	return value
+}
+
+func (p *parser) bool(key string) bool {
+	value, err := cast.ToBoolE(p.v.Get(key))
+	if err != nil {
+		p.fail(key, "boolean")
+	}
+	p.values[key] = value
+	return value
+}
+
+func (p *parser) float(key string) float64 {
+	value, err := cast.ToFloat64E(p.v.Get(key))
+	if err != nil {
+		p.fail(key, "number")
+	}
+	p.values[key] = value
+	return value
+}
+
+// duration exige a unidade (10s, 5m, 24h); um número sem unidade é rejeitado
+// em vez de virar nanossegundos
+func (p *parser) duration(key string) time.Duration {
+	raw := cast.ToString(p.v.Get(key))
+	value, err := time.ParseDuration(raw)
+	if err != nil {
+		p.fail(key, "duration")
+	}
+	p.values[key] = formatDuration(value)
+	return value
+}
+
+// list aceita uma lista do YAML ou valores separados por vírgula
+func (p *parser) list(key string) []string {
+	var value []string
+	switch raw := p.v.Get(key).(type) {
+	case string:
+		value = splitList(raw)
+	default:
+		items, err := cast.ToStringSliceE(raw)
+		if err != nil {
+			p.fail(key, "list")
+		}
+		for _, item := range items {
+			value = append(value, splitList(item)...)
+		}
+	}
+	p.values[key] = value
+	return value
+}
+
+// formatDuration escreve a duração sem as unidades zeradas do final
+// (24h em vez de 24h0m0s)
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	databaseDrivers   = []string{"postgres", "sqlite"}
+	sslModes          = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+	logFormats        = []string{"json", "text"}
+	tracingExporters  = []string{"none", "stdout", "otlp"}
+	metadataProviders = []string{"openlibrary", "googlebooks", "fixture"}
+	storageBackends   = []string{"local", "s3"}
+	cacheBackends     = []string{"none", "memory", "redis"}
+)
+
+// Validate confere os valores que o parser aceitou mas que o servidor não
+// consegue usar, e devolve todos os problemas encontrados
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	v.port("server.port", c.Server.Address)
+	publicURL, err := url.Parse(c.Server.PublicURL)
+	v.check(err == nil && (publicURL.Scheme == "http" || publicURL.Scheme == "https") && publicURL.Host != "",
+		"server.public_url", "must be an absolute http or https URL")
+	v.check(c.Server.ReadHeaderTimeout >= 0 && c.Server.ReadTimeout >= 0 && c.Server.WriteTimeout >= 0 && c.Server.IdleTimeout >= 0,
+		"server", "timeouts must not be negative")
+	v.check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout", "must be positive")
+	v.check(c.Server.MaxHeaderBytes > 0, "server.max_header_bytes", "must be positive")
+	v.check(c.Server.CompressionMinSize >= 0, "server.compression_min_size", "must not be negative")
+	v.check((c.Server.TLS.CertFile == "") == (c.Server.TLS.KeyFile == ""),
+		"server.tls", "cert_file and key_file must be set together")
+	v.check(c.Server.TLS.ReloadInterval >= 0, "server.tls.reload_interval", "must not be negative")
+
+	v.oneOf("database.driver", c.Database.Driver, databaseDrivers)
+	switch c.Database.Driver {
+	case "postgres":
+		v.check(c.Database.Host != "", "database.host", "is required")
+		v.port("database.port", ":"+c.Database.Port)
+		v.check(c.Database.User != "", "database.user", "is required")
+		v.check(c.Database.DBName != "", "database.name", "is required")
+		v.oneOf("database.sslmode", c.Database.SSLMode, sslModes)
+		for _, replica := range c.Database.Replicas {
+			v.check(replicaAddress(replica), "database.replicas", "must be host or host:port")
+		}
+	case "sqlite":
+		v.check(c.Database.SQLitePath != "", "database.sqlite_path", "is required by the sqlite driver")
+		v.check(len(c.Database.Replicas) == 0, "database.replicas", "are not supported by the sqlite driver")
+	}
+	v.check(c.Database.MaxOpenConns >= 0, "database.max_open_conns", "must not be negative")
+	v.check(c.Database.MaxIdleConns >= 0, "database.max_idle_conns", "must not be negative")
+	v.check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+		"database.max_idle_conns", "must not exceed database.max_open_conns")
+	v.check(c.Database.ConnMaxLifetime >= 0 && c.Database.ConnMaxIdleTime >= 0,
+		"database", "connection lifetimes must not be negative")
+	v.check(c.Database.ConnectAttempts > 0, "database.connect_attempts", "must be positive")
+	v.check(c.Database.ConnectBackoff >= 0, "database.connect_backoff", "must not be negative")
+
+	v.oneOf("cache.backend", c.Cache.Backend, cacheBackends)
+	switch c.Cache.Backend {
+	case "memory":
+		v.check(c.Cache.Size > 0, "cache.size", "must be positive")
+	case "redis":
+		v.port("cache.redis.addr", c.Cache.Redis.Addr)
+		v.check(c.Cache.Redis.DB >= 0, "cache.redis.db", "must not be negative")
+	}
+	v.check(c.Cache.Backend == "none" || c.Cache.TTL > 0, "cache.ttl", "must be positive")
+
+	var level slog.Level
+	v.check(level.UnmarshalText([]byte(c.Log.Level)) == nil, "log.level", "must be debug, info, warn or error")
+	v.oneOf("log.format", c.Log.Format, logFormats)
+
+	v.oneOf("tracing.exporter", c.Tracing.Exporter, tracingExporters)
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio", "must be between 0 and 1")
+	v.check(c.Tracing.ServiceName != "", "tracing.service_name", "is required")
+
+	v.check(c.Health.CheckTimeout > 0, "health.check_timeout", "must be positive")
+	v.check(c.Idempotency.TTL > 0, "idempotency.ttl", "must be positive")
+	v.check(c.Idempotency.LockTimeout > 0, "idempotency.lock_timeout", "must be positive")
+
+	for _, provider := range c.Metadata.Providers {
+		v.oneOf("metadata.providers", provider, metadataProviders)
+	}
+	v.check(c.Metadata.Timeout > 0, "metadata.timeout", "must be positive")
+	v.check(c.Metadata.CacheTTL >= 0, "metadata.cache_ttl", "must not be negative")
+
+	v.oneOf("storage.backend", c.Storage.Backend, storageBackends)
+	switch c.Storage.Backend {
+	case "local":
+		v.check(c.Storage.Dir != "", "storage.dir", "is required by the local backend")
+	case "s3":
+		v.check(c.Storage.S3.Endpoint != "", "storage.s3.endpoint", "is required by the s3 backend")
+		v.check(c.Storage.S3.Bucket != "", "storage.s3.bucket", "is required by the s3 backend")
+	}
+	v.check(c.Storage.MaxCoverSize > 0, "storage.max_cover_size", "must be positive")
+	v.check(c.Storage.CoverFetchTimeout > 0, "storage.cover_fetch_timeout", "must be positive")
+	v.check(c.Storage.CoverSweepInterval > 0, "storage.cover_sweep_interval", "must be positive")
+
+	return errors.Join(v.errs...)
+}
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) check(ok bool, key, problem string) {
+	if !ok {
+		v.errs = append(v.errs, fmt.Errorf("%s %s", key, problem))
+	}
+}
+
+// port confere a porta de um endereço host:porta
+func (v *validator) port(key, address string) {
+	_, value, err := net.SplitHostPort(address)
+	port, convErr := strconv.Atoi(value)
+	v.check(err == nil && convErr == nil && port > 0 && port <= 65535, key, "must be between 1 and 65535")
+}
+
+func (v *validator) oneOf(key, value string, allowed []string) {
+	v.check(slices.Contains(allowed, value), key, fmt.Sprintf("must be one of %v, got %q", allowed, value))
+}
+
+// replicaAddress confere um endereço host ou host:porta
+func replicaAddress(address string) bool {
+	if !strings.Contains(address, ":") {
+		return address != ""
+	}
+	host, value, err := net.SplitHostPort(address)
+	port, convErr := strconv.Atoi(value)
+	return err == nil && host != "" && convErr == nil && port > 0 && port <= 65535
+}
@@ -0,0 +1,65 @@
+// Package database abre as conexões com o banco de dados
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/config"
+)
+
+// Maior espera entre duas tentativas de conexão
+const maxConnectBackoff = 30 * time.Second
+
+// Open conecta ao banco do driver configurado
+func Open(ctx context.Context, cfg config.DatabaseConfig) (*sqlx.DB, error) {
+	switch cfg.Driver {
+	case "postgres":
+		return NewPostgresConnection(ctx, cfg)
+	case "sqlite":
+		return NewSQLiteConnection(ctx, cfg)
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", cfg.Driver)
+	}
+}
+
+// OpenReplicas conecta às réplicas de leitura configuradas, que só o
+// Postgres tem
+func OpenReplicas(ctx context.Context, cfg config.DatabaseConfig) ([]*sqlx.DB, error) {
+	if cfg.Driver != "postgres" {
+		return nil, nil
+	}
+	return NewPostgresReplicas(ctx, cfg)
+}
+
+func configurePool(db *sqlx.DB, cfg config.DatabaseConfig) {
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+}
+
+// ping espera o banco responder, com até cfg.ConnectAttempts tentativas; a
+// espera entre elas começa em cfg.ConnectBackoff e dobra a cada falha, para
+// que o servidor suba junto com um banco que ainda está iniciando
+func ping(ctx context.Context, db *sqlx.DB, cfg config.DatabaseConfig) error {
+	backoff := cfg.ConnectBackoff
+	for attempt := 1; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil || attempt >= cfg.ConnectAttempts {
+			return err
+		}
+
+		slog.Warn("Database unavailable, retrying", "attempt", attempt, "retry_in", backoff, "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+		backoff = min(2*backoff, maxConnectBackoff)
+	}
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/config"
+)
+
+func TestOpenIndependentConnections(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	first, err := Open(ctx, config.DatabaseConfig{Driver: "sqlite", SQLitePath: filepath.Join(dir, "first.db")})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer first.Close()
+	second, err := Open(ctx, config.DatabaseConfig{Driver: "sqlite", SQLitePath: filepath.Join(dir, "second.db")})
+	if err != nil {
+		t.Fatalf("second Open() error = %v", err)
+	}
+	defer second.Close()
+
+	if _, err := first.Exec(`CREATE TABLE only_first (id INTEGER)`); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	if _, err := second.Exec(`SELECT id FROM only_first`); err == nil {
+		t.Error("the second connection sees the table of the first database")
+	}
+}
+
+func TestOpenRetries(t *testing.T) {
+	// Uma porta em que nada escuta
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+
+	cfg := config.DatabaseConfig{
+		Driver:          "postgres",
+		Host:            "127.0.0.1",
+		Port:            port,
+		User:            "app",
+		DBName:          "bookflow",
+		SSLMode:         "disable",
+		ConnectAttempts: 3,
+		ConnectBackoff:  20 * time.Millisecond,
+	}
+
+	start := time.Now()
+	if _, err := Open(context.Background(), cfg); err == nil {
+		t.Fatal("Open() error = nil, want the connection to be refused")
+	}
+	// Duas esperas, de 20ms e 40ms, entre as três tentativas
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("Open() gave up after %v, want it to wait between the attempts", elapsed)
+	}
+
+	// O cancelamento do contexto interrompe as tentativas
+	cfg.ConnectAttempts, cfg.ConnectBackoff = 10, time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if _, err := Open(ctx, cfg); err == nil {
+		t.Fatal("Open() with a canceled context error = nil")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Open() took %v, want it to stop when the context is done", elapsed)
+	}
+}
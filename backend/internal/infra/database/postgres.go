@@ -1,49 +1,67 @@
 package database
 
 import (
+	"context"
 	"fmt"
-	"sync"
+	"net"
 
+	"github.com/XSAM/otelsql"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 
 	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/config"
 )
 
-var (
-	instance *sqlx.DB
-	once     sync.Once
-)
+// NewPostgresConnection conecta ao Postgres de cfg. Cada chamada abre um pool
+// novo, que quem chamou deve fechar.
+func NewPostgresConnection(ctx context.Context, cfg config.DatabaseConfig) (*sqlx.DB, error) {
+	dsn := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode,
+	)
 
-func NewPostgresConnection(cfg config.DatabaseConfig) (*sqlx.DB, error) {
-	var err error
+	// Cada consulta gera um span com o SQL executado, nunca com os
+	// valores dos parâmetros
+	sqlDB, err := otelsql.Open("postgres", dsn,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBNamespace(cfg.DBName), semconv.ServerAddress(cfg.Host)),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{OmitConnResetSession: true, OmitRows: true}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	db := sqlx.NewDb(sqlDB, "postgres")
 
-	once.Do(func() {
-		dsn := fmt.Sprintf(
-			"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-			cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode,
-		)
+	configurePool(db, cfg)
 
-		db, e := sqlx.Connect("postgres", dsn)
-		if e != nil {
-			err = e
-			return
-		}
+	if err := ping(ctx, db, cfg); err != nil {
+		db.Close()
+		return nil, err
+	}
 
-		db.SetMaxOpenConns(20)
-		db.SetMaxIdleConns(5)
+	return db, nil
+}
 
-		if e = db.Ping(); e != nil {
-			err = e
-			return
+// NewPostgresReplicas conecta às réplicas de leitura de cfg.Replicas com o
+// usuário, a senha e o banco do primário; sem porta, usa a do primário
+func NewPostgresReplicas(ctx context.Context, cfg config.DatabaseConfig) ([]*sqlx.DB, error) {
+	replicas := make([]*sqlx.DB, 0, len(cfg.Replicas))
+	for _, address := range cfg.Replicas {
+		replicaCfg := cfg
+		replicaCfg.Host = address
+		if host, port, err := net.SplitHostPort(address); err == nil {
+			replicaCfg.Host, replicaCfg.Port = host, port
 		}
 
-		instance = db
-	})
-
-	if err != nil {
-		return nil, err
+		db, err := NewPostgresConnection(ctx, replicaCfg)
+		if err != nil {
+			for _, replica := range replicas {
+				replica.Close()
+			}
+			return nil, fmt.Errorf("replica %s: %w", address, err)
+		}
+		replicas = append(replicas, db)
 	}
 
-	return instance, nil
+	return replicas, nil
 }
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/XSAM/otelsql"
+	"github.com/jmoiron/sqlx"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	_ "modernc.org/sqlite"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/config"
+)
+
+func init() {
+	// O driver modernc.org/sqlite usa ? como o sqlite3
+	sqlx.BindDriver("sqlite", sqlx.QUESTION)
+}
+
+// NewSQLiteConnection abre o arquivo SQLite de cfg.SQLitePath, criando-o se
+// não existir. O banco usa WAL, para que as leituras não esperem as escritas,
+// e as transações já começam com o lock de escrita, para que duas transações
+// não falhem ao tentar gravar ao mesmo tempo.
+func NewSQLiteConnection(ctx context.Context, cfg config.DatabaseConfig) (*sqlx.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(cfg.SQLitePath), 0o755); err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "foreign_keys(ON)")
+	params.Add("_pragma", "synchronous(NORMAL)")
+	params.Set("_txlock", "immediate")
+	// Datas gravadas como "2006-01-02 15:04:05.999999999-07:00". Elas são
+	// comparadas e ordenadas como texto, o que só é correto com o mesmo
+	// deslocamento em todas, então os repositórios gravam sempre em UTC
+	params.Set("_time_format", "sqlite")
+	dsn := fmt.Sprintf("file:%s?%s", cfg.SQLitePath, params.Encode())
+
+	sqlDB, err := otelsql.Open("sqlite", dsn,
+		otelsql.WithAttributes(semconv.DBSystemSqlite, semconv.DBNamespace(filepath.Base(cfg.SQLitePath))),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{OmitConnResetSession: true, OmitRows: true}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	db := sqlx.NewDb(sqlDB, "sqlite")
+
+	configurePool(db, cfg)
+
+	if err := ping(ctx, db, cfg); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
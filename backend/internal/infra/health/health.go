@@ -0,0 +1,141 @@
+// Package health reúne as verificações das dependências usadas pela sonda de
+// prontidão (readiness)
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// Tempo máximo de uma verificação registrada sem timeout próprio
+const DefaultTimeout = 2 * time.Second
+
+// CheckFunc verifica uma dependência; nil indica que ela está disponível.
+// ctx expira no timeout da verificação.
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name    string
+	timeout time.Duration
+	run     CheckFunc
+}
+
+// Registry guarda as verificações nomeadas e as executa em paralelo
+type Registry struct {
+	mu     sync.RWMutex
+	checks []check
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adiciona a verificação name. timeout zero usa DefaultTimeout.
+func (r *Registry) Register(name string, timeout time.Duration, run CheckFunc) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, check{name: name, timeout: timeout, run: run})
+}
+
+// Result é o resultado de uma verificação
+type Result struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	Error      string  `json:"error,omitempty"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// Report é o resultado de todas as verificações; Status só é ok se todas
+// passaram
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// OK indica se todas as verificações passaram
+func (r *Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+// Run executa todas as verificações, cada uma com o seu timeout, e devolve os
+// resultados em ordem alfabética
+func (r *Registry) Run(ctx context.Context) *Report {
+	r.mu.RLock()
+	checks := append([]check(nil), r.checks...)
+	r.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.execute(ctx)
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	report := &Report{Status: StatusOK, Checks: results}
+	for _, result := range results {
+		if result.Status != StatusOK {
+			report.Status = StatusUnavailable
+		}
+	}
+	return report
+}
+
+func (c check) execute(ctx context.Context) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("check panicked: %v", p)
+			}
+		}()
+		done <- c.run(ctx)
+	}()
+
+	// Uma verificação que ignora o contexto não segura a resposta além do
+	// timeout
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := Result{
+		Name:       c.name,
+		Status:     StatusOK,
+		DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusUnavailable
+		result.Error = err.Error()
+		if errors.Is(err, context.DeadlineExceeded) {
+			result.Error = fmt.Sprintf("timed out after %s", c.timeout)
+		}
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRegistryRun(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("storage", 0, func(context.Context) error { return nil })
+	registry.Register("database", time.Second, func(context.Context) error { return nil })
+
+	report := registry.Run(context.Background())
+	if !report.OK() {
+		t.Fatalf("Run() status = %q, want ok: %+v", report.Status, report.Checks)
+	}
+	if len(report.Checks) != 2 || report.Checks[0].Name != "database" || report.Checks[1].Name != "storage" {
+		t.Errorf("Run() checks = %+v, want database and storage in order", report.Checks)
+	}
+}
+
+func TestRegistryRunFailures(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("database", 0, func(context.Context) error { return nil })
+	registry.Register("migrations", 0, func(context.Context) error { return errors.New("2 pending") })
+	registry.Register("panics", 0, func(context.Context) error { panic("boom") })
+	// Ignora o contexto: o resultado não pode esperar pelo retorno
+	registry.Register("slow", 20*time.Millisecond, func(context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	start := time.Now()
+	report := registry.Run(context.Background())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Run() took %v, want the slow check to time out", elapsed)
+	}
+	if report.OK() {
+		t.Fatal("Run() status = ok, want unavailable")
+	}
+
+	want := map[string]string{
+		"database":   "",
+		"migrations": "2 pending",
+		"panics":     "check panicked: boom",
+		"slow":       "timed out after 20ms",
+	}
+	for _, result := range report.Checks {
+		if result.Error != want[result.Name] {
+			t.Errorf("%s error = %q, want %q", result.Name, result.Error, want[result.Name])
+		}
+		wantStatus := StatusOK
+		if want[result.Name] != "" {
+			wantStatus = StatusUnavailable
+		}
+		if result.Status != wantStatus {
+			t.Errorf("%s status = %q, want %q", result.Name, result.Status, wantStatus)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package httpserver
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// certReloader entrega o certificado TLS e o relê quando os arquivos mudam,
+// para que certificados renovados (por exemplo pelo cert-manager ou certbot)
+// sejam usados sem reiniciar o servidor
+type certReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+func newCertReloader(certFile, keyFile string, interval time.Duration) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, interval: interval}
+
+	modTime, err := r.lastModified()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(modTime); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GetCertificate é usado em tls.Config. Os arquivos são verificados no
+// máximo uma vez por intervalo; se a releitura falhar (por exemplo com o
+// certificado e a chave trocados pela metade) o certificado anterior continua
+// em uso e a leitura é tentada de novo no próximo intervalo.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.interval > 0 && time.Since(r.checked) >= r.interval {
+		r.checked = time.Now()
+
+		modTime, err := r.lastModified()
+		if err == nil && !modTime.Equal(r.modTime) {
+			err = r.load(modTime)
+			if err == nil {
+				slog.Info("Reloaded TLS certificate", "file", r.certFile)
+			}
+		}
+		if err != nil {
+			slog.Error("Failed to reload TLS certificate", "error", err)
+		}
+	}
+
+	return r.cert, nil
+}
+
+func (r *certReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load TLS certificate: %w", err)
+	}
+
+	r.cert = &cert
+	r.modTime = modTime
+	return nil
+}
+
+// lastModified devolve a data da alteração mais recente entre os dois arquivos
+func (r *certReloader) lastModified() (time.Time, error) {
+	var latest time.Time
+	for _, name := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,95 @@
+// Package httpserver configura o servidor HTTP da API: tempos limite, TLS com
+// recarga do certificado, HTTP/2 e desligamento gracioso
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/config"
+)
+
+type Server struct {
+	srv *http.Server
+	// Guardado à parte porque o net/http altera srv.TLSConfig ao iniciar
+	tls bool
+}
+
+func New(handler http.Handler, cfg config.ServerConfig) (*Server, error) {
+	srv := &http.Server{
+		Addr:              cfg.Address,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+
+	if cfg.TLS.CertFile != "" || cfg.TLS.KeyFile != "" {
+		if cfg.TLS.CertFile == "" || cfg.TLS.KeyFile == "" {
+			return nil, errors.New("TLS requires both a certificate and a key file")
+		}
+
+		certs, err := newCertReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.ReloadInterval)
+		if err != nil {
+			return nil, err
+		}
+
+		srv.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.GetCertificate,
+		}
+		protocols.SetHTTP2(cfg.HTTP2)
+	} else {
+		// Sem TLS o HTTP/2 só é aceito com conhecimento prévio (h2c), como o
+		// usado por proxies e balanceadores de carga
+		protocols.SetUnencryptedHTTP2(cfg.HTTP2)
+	}
+	srv.Protocols = protocols
+
+	return &Server{srv: srv, tls: srv.TLSConfig != nil}, nil
+}
+
+// TLS indica se o servidor atende com TLS
+func (s *Server) TLS() bool {
+	return s.tls
+}
+
+// ListenAndServe atende no endereço configurado até Shutdown ser chamado,
+// quando retorna nil
+func (s *Server) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.srv.Addr)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(listener)
+}
+
+// Serve atende as conexões de listener até Shutdown ser chamado, quando retorna nil
+func (s *Server) Serve(listener net.Listener) error {
+	var err error
+	if s.TLS() {
+		err = s.srv.ServeTLS(listener, "", "")
+	} else {
+		err = s.srv.Serve(listener)
+	}
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown para de aceitar conexões e espera as requisições em andamento
+// terminarem, até o fim de ctx
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
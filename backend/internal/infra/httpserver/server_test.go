@@ -0,0 +1,227 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/config"
+)
+
+// start atende em uma porta livre e desliga o servidor no fim do teste
+func start(t *testing.T, handler http.Handler, cfg config.ServerConfig) (*Server, string) {
+	t.Helper()
+
+	server, err := New(handler, cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(listener) }()
+	t.Cleanup(func() {
+		server.Shutdown(context.Background())
+		if err := <-done; err != nil {
+			t.Errorf("Serve() error = %v", err)
+		}
+	})
+
+	return server, listener.Addr().String()
+}
+
+func TestShutdownDrainsRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	})
+
+	server, addr := start(t, handler, config.ServerConfig{})
+
+	response := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr)
+		if err != nil {
+			response <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		response <- string(body)
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- server.Shutdown(context.Background()) }()
+
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown() returned %v before the request finished", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if body := <-response; body != "done" {
+		t.Errorf("in-flight request got %q, want done", body)
+	}
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+}
+
+func TestHTTP2(t *testing.T) {
+	certFile, keyFile := writeCert(t, t.TempDir(), 1)
+	protocol := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.Proto)
+	})
+
+	tests := []struct {
+		name  string
+		cfg   config.ServerConfig
+		proto string
+	}{
+		{"tls", config.ServerConfig{HTTP2: true, TLS: config.TLSConfig{CertFile: certFile, KeyFile: keyFile}}, "HTTP/2.0"},
+		{"tls without http2", config.ServerConfig{TLS: config.TLSConfig{CertFile: certFile, KeyFile: keyFile}}, "HTTP/1.1"},
+		{"h2c", config.ServerConfig{HTTP2: true}, "HTTP/2.0"},
+		{"cleartext without http2", config.ServerConfig{}, "HTTP/1.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, addr := start(t, protocol, tt.cfg)
+
+			protocols := new(http.Protocols)
+			protocols.SetHTTP1(true)
+			protocols.SetHTTP2(true)
+			scheme := "https"
+			if !server.TLS() {
+				scheme = "http"
+				// Conhecimento prévio: o cliente só fala h2c se o servidor aceitar
+				protocols = new(http.Protocols)
+				protocols.SetUnencryptedHTTP2(tt.cfg.HTTP2)
+				protocols.SetHTTP1(!tt.cfg.HTTP2)
+			}
+			client := &http.Client{Transport: &http.Transport{
+				Protocols:       protocols,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			}}
+
+			resp, err := client.Get(scheme + "://" + addr)
+			if err != nil {
+				t.Fatalf("GET error = %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != tt.proto {
+				t.Errorf("server saw %s, want %s", body, tt.proto)
+			}
+		})
+	}
+}
+
+func TestNewRequiresCertAndKey(t *testing.T) {
+	if _, err := New(http.NotFoundHandler(), config.ServerConfig{TLS: config.TLSConfig{CertFile: "cert.pem"}}); err == nil {
+		t.Error("New() with only a certificate error = nil")
+	}
+	if _, err := New(http.NotFoundHandler(), config.ServerConfig{TLS: config.TLSConfig{CertFile: "missing.pem", KeyFile: "missing.key"}}); err == nil {
+		t.Error("New() with missing files error = nil")
+	}
+}
+
+func TestCertificateReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir, 1)
+
+	_, addr := start(t, http.NotFoundHandler(), config.ServerConfig{TLS: config.TLSConfig{
+		CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Millisecond,
+	}})
+
+	if serial := servedSerial(t, addr); serial != 1 {
+		t.Fatalf("served certificate %d, want 1", serial)
+	}
+
+	// Um par incompleto mantém o certificado anterior
+	future := time.Now().Add(time.Hour)
+	os.WriteFile(certFile, []byte("not a certificate"), 0o600)
+	os.Chtimes(certFile, future, future)
+	time.Sleep(5 * time.Millisecond)
+	if serial := servedSerial(t, addr); serial != 1 {
+		t.Fatalf("served certificate %d after a broken update, want 1", serial)
+	}
+
+	writeCert(t, dir, 2)
+	future = future.Add(time.Hour)
+	os.Chtimes(certFile, future, future)
+	time.Sleep(5 * time.Millisecond)
+	if serial := servedSerial(t, addr); serial != 2 {
+		t.Errorf("served certificate %d after renewal, want 2", serial)
+	}
+}
+
+func servedSerial(t *testing.T, addr string) int64 {
+	t.Helper()
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatalf("TLS dial error = %v", err)
+	}
+	defer conn.Close()
+
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64()
+}
+
+// writeCert grava em dir um certificado autoassinado com o número de série informado
+func writeCert(t *testing.T, dir string, serial int64) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return certFile, keyFile
+}
@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNewRedactsSecrets(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := New(&out, "info", FormatJSON)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	logger.Info("login",
+		"email", "joao@example.com",
+		"password", "senha123",
+		slog.Group("headers", "Authorization", "Bearer abc", "X-Api-Key", "k", "Accept", "*/*"),
+		"S3_SECRET_KEY", "minioadmin",
+	)
+
+	var entry map[string]any
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid JSON log %q: %v", out.String(), err)
+	}
+
+	headers := entry["headers"].(map[string]any)
+	checks := map[string]any{
+		"email":         entry["email"],
+		"password":      entry["password"],
+		"Authorization": headers["Authorization"],
+		"X-Api-Key":     headers["X-Api-Key"],
+		"Accept":        headers["Accept"],
+		"S3_SECRET_KEY": entry["S3_SECRET_KEY"],
+	}
+	want := map[string]any{
+		"email":         "joao@example.com",
+		"password":      redacted,
+		"Authorization": redacted,
+		"X-Api-Key":     redacted,
+		"Accept":        "*/*",
+		"S3_SECRET_KEY": redacted,
+	}
+	for key, value := range want {
+		if checks[key] != value {
+			t.Errorf("%s = %v, want %v", key, checks[key], value)
+		}
+	}
+	if strings.Contains(out.String(), "senha123") || strings.Contains(out.String(), "Bearer") {
+		t.Errorf("log leaks a secret: %s", out.String())
+	}
+}
+
+func TestNewLevelAndFormat(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := New(&out, "WARN", FormatText)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	logger.Info("hidden")
+	logger.Warn("shown", "status", 404)
+	if got := out.String(); strings.Contains(got, "hidden") || !strings.Contains(got, "msg=shown status=404") {
+		t.Errorf("text log = %q", got)
+	}
+
+	if _, err := New(&out, "verbose", FormatJSON); err == nil {
+		t.Error("New() with an invalid level error = nil")
+	}
+	if _, err := New(&out, "info", "xml"); err == nil {
+		t.Error("New() with an invalid format error = nil")
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if FromContext(context.Background()) != slog.Default() {
+		t.Error("FromContext() without a logger should return the default logger")
+	}
+
+	logger := slog.New(slog.DiscardHandler).With("request_id", "abc")
+	if FromContext(WithContext(context.Background(), logger)) != logger {
+		t.Error("FromContext() did not return the stored logger")
+	}
+}
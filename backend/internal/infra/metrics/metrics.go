@@ -0,0 +1,123 @@
+// Package metrics expõe as métricas da aplicação no formato do Prometheus
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "bookflow"
+
+// Valores do label result de LoginsTotal
+const (
+	LoginSucceeded = "succeeded"
+	LoginFailed    = "failed"
+	LoginDisabled  = "disabled"
+)
+
+// Valores do label source de BooksCreatedTotal
+const (
+	SourceAPI    = "api"
+	SourceBatch  = "batch"
+	SourceImport = "import"
+)
+
+// Valores do label result de BookCacheRequestsTotal
+const (
+	CacheHit   = "hit"
+	CacheMiss  = "miss"
+	CacheError = "error"
+)
+
+// Registry reúne as métricas da aplicação, do runtime do Go e do processo
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+// Métricas HTTP. route é o modelo da rota (/api/books/:id), nunca o caminho
+// recebido, para que a quantidade de séries não cresça com os IDs.
+var (
+	HTTPRequestsTotal = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled, by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time to handle HTTP requests, by method and route template.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	HTTPRequestsInFlight = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "HTTP requests currently being handled.",
+	})
+)
+
+// Contadores do domínio
+var (
+	BooksCreatedTotal = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "books_created_total",
+		Help:      "Books created, by source (api, batch or import).",
+	}, []string{"source"})
+
+	LoginsTotal = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts, by result (succeeded, failed or disabled).",
+	}, []string{"result"})
+
+	UsersRegisteredTotal = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "users_registered_total",
+		Help:      "Users registered through the API.",
+	})
+)
+
+// Cache de livros. query é find_by_id ou find_all.
+var BookCacheRequestsTotal = factory.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "book_cache_requests_total",
+	Help:      "Book cache lookups, by query and result (hit, miss or error).",
+}, []string{"query", "result"})
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	// Séries zeradas desde o início, para que rate() e alertas funcionem
+	// antes do primeiro evento
+	for _, source := range []string{SourceAPI, SourceBatch, SourceImport} {
+		BooksCreatedTotal.WithLabelValues(source)
+	}
+	for _, result := range []string{LoginSucceeded, LoginFailed, LoginDisabled} {
+		LoginsTotal.WithLabelValues(result)
+	}
+	for _, query := range []string{"find_by_id", "find_all"} {
+		for _, result := range []string{CacheHit, CacheMiss, CacheError} {
+			BookCacheRequestsTotal.WithLabelValues(query, result)
+		}
+	}
+}
+
+// RegisterDB expõe as estatísticas do pool de conexões (sql.DB.Stats) como
+// as métricas go_sql_*, com o label db_name
+func RegisterDB(db *sql.DB, name string) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler atende o endpoint /metrics
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
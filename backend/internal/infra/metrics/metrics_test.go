@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestHandlerExposesMetrics(t *testing.T) {
+	HTTPRequestsTotal.WithLabelValues("GET", "/api/books/:id", "200").Inc()
+	HTTPRequestDuration.WithLabelValues("GET", "/api/books/:id").Observe(0.01)
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+
+	for _, want := range []string{
+		`bookflow_http_requests_total{method="GET",route="/api/books/:id",status="200"} 1`,
+		`bookflow_http_request_duration_seconds_count{method="GET",route="/api/books/:id"} 1`,
+		`bookflow_books_created_total{source="batch"} 0`,
+		`bookflow_logins_total{result="failed"} 0`,
+		`bookflow_users_registered_total 0`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("/metrics does not contain %q", want)
+		}
+	}
+}
+
+func TestRegisterDB(t *testing.T) {
+	// sql.Open não conecta, então o driver não precisa existir para o teste
+	sql.Register("metrics-test", nopDriver{})
+	db, err := sql.Open("metrics-test", "")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer db.Close()
+
+	RegisterDB(db, "bookflow_test")
+
+	count, err := testutil.GatherAndCount(Registry, "go_sql_open_connections", "go_sql_max_open_connections")
+	if err != nil {
+		t.Fatalf("GatherAndCount() error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("pool gauges = %d, want 2", count)
+	}
+}
+
+type nopDriver struct{}
+
+func (nopDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("not implemented")
+}
@@ -0,0 +1,113 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Usage descreve os subcomandos aceitos por Run
+const Usage = "up | down [steps] | status | force <version>"
+
+// ErrUsage indica argumentos inválidos para Run
+var ErrUsage = errors.New("invalid usage")
+
+// usageError descreve um argumento inválido e é ErrUsage
+type usageError struct {
+	message string
+}
+
+func (e *usageError) Error() string {
+	return e.message
+}
+
+func (e *usageError) Is(target error) bool {
+	return target == ErrUsage
+}
+
+// Result é o resultado de Run; apenas os campos do subcomando são preenchidos
+type Result struct {
+	Command  string
+	Applied  int
+	Reverted int
+	Version  int64
+	Statuses []Status
+}
+
+// Run executa o subcomando de manutenção dado nos argumentos da linha de
+// comando: up, down [steps], status ou force <version>. Argumentos inválidos
+// resultam em ErrUsage ou em um erro que o descreve e é ErrUsage.
+func (m *Migrator) Run(ctx context.Context, args []string) (*Result, error) {
+	if len(args) == 0 {
+		return nil, ErrUsage
+	}
+
+	result := &Result{Command: args[0]}
+	var err error
+	switch args[0] {
+	case "up":
+		if len(args) != 1 {
+			return nil, ErrUsage
+		}
+		result.Applied, err = m.Up(ctx)
+	case "down":
+		if len(args) > 2 {
+			return nil, ErrUsage
+		}
+		steps := 1
+		if len(args) == 2 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return nil, &usageError{fmt.Sprintf("invalid number of steps %q", args[1])}
+			}
+		}
+		result.Reverted, err = m.Down(ctx, steps)
+	case "force":
+		if len(args) != 2 {
+			return nil, ErrUsage
+		}
+		result.Version, err = strconv.ParseInt(args[1], 10, 64)
+		if err != nil || result.Version < 0 {
+			return nil, &usageError{fmt.Sprintf("invalid version %q", args[1])}
+		}
+		err = m.Force(ctx, result.Version)
+	case "status":
+		if len(args) != 1 {
+			return nil, ErrUsage
+		}
+		result.Statuses, err = m.Status(ctx)
+	default:
+		return nil, ErrUsage
+	}
+
+	return result, err
+}
+
+// State resume a situação da versão: applied, pending, dirty ou unknown
+func (s Status) State() string {
+	switch {
+	case s.Dirty:
+		return "dirty"
+	case s.Unknown:
+		return "unknown"
+	case s.Applied:
+		return "applied"
+	default:
+		return "pending"
+	}
+}
+
+// WriteStatus escreve a tabela de status com as colunas separadas por
+// tabulação, para ser alinhada por um tabwriter
+func WriteStatus(w io.Writer, statuses []Status) {
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := ""
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", status.Version, status.Name, status.State(), appliedAt)
+	}
+}
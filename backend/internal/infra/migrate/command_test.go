@@ -0,0 +1,87 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/config"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/database"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/migrations"
+)
+
+func TestRun(t *testing.T) {
+	db, err := database.NewSQLiteConnection(context.Background(), config.DatabaseConfig{SQLitePath: filepath.Join(t.TempDir(), "bookflow.db")})
+	if err != nil {
+		t.Fatalf("NewSQLiteConnection() error = %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	m, err := New(db, migrations.SQLite)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	total := len(m.migrations)
+
+	if result, err := m.Run(ctx, []string{"up"}); err != nil || result.Applied != total {
+		t.Fatalf("Run(up) = %+v, %v; want %d applied", result, err, total)
+	}
+	if result, err := m.Run(ctx, []string{"down"}); err != nil || result.Reverted != 1 {
+		t.Fatalf("Run(down) = %+v, %v; want 1 reverted", result, err)
+	}
+	if result, err := m.Run(ctx, []string{"force", "1"}); err != nil || result.Version != 1 {
+		t.Fatalf("Run(force 1) = %+v, %v", result, err)
+	}
+
+	result, err := m.Run(ctx, []string{"status"})
+	if err != nil || len(result.Statuses) != total {
+		t.Fatalf("Run(status) = %+v, %v; want %d statuses", result, err, total)
+	}
+	var table strings.Builder
+	WriteStatus(&table, result.Statuses)
+	lines := strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n")
+	if len(lines) != total+1 || !strings.HasPrefix(lines[1], "001\t") || !strings.Contains(lines[1], "\tapplied\t") ||
+		!strings.HasSuffix(lines[total], "\tpending\t") {
+		t.Errorf("WriteStatus() = %q, want version 1 applied and the last one pending", table.String())
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	m := &Migrator{}
+	for _, args := range [][]string{
+		nil,
+		{"sideways"},
+		{"up", "2"},
+		{"down", "0"},
+		{"down", "x"},
+		{"down", "1", "2"},
+		{"force"},
+		{"force", "-1"},
+		{"status", "all"},
+	} {
+		if _, err := m.Run(context.Background(), args); !errors.Is(err, ErrUsage) {
+			t.Errorf("Run(%q) error = %v, want ErrUsage", args, err)
+		}
+	}
+}
+
+func TestStatusState(t *testing.T) {
+	tests := []struct {
+		status Status
+		want   string
+	}{
+		{Status{}, "pending"},
+		{Status{Applied: true}, "applied"},
+		{Status{Applied: true, Dirty: true}, "dirty"},
+		{Status{Applied: true, Unknown: true}, "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.status.State(); got != tt.want {
+			t.Errorf("%+v.State() = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
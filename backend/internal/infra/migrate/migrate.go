@@ -0,0 +1,470 @@
+// Package migrate aplica as migrações versionadas do banco de dados
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Tabela que registra as versões aplicadas
+const table = "schema_migrations"
+
+// Chave do advisory lock que impede duas réplicas de migrarem ao mesmo tempo
+// (os bytes de "bookflow")
+const lockID int64 = 0x626f6f6b666c6f77
+
+// Diretiva, em uma linha própria do script, para comandos que não podem rodar
+// dentro de uma transação, como CREATE INDEX CONCURRENTLY
+const noTransactionDirective = "-- migrate:no-transaction"
+
+var (
+	// ErrDirty indica uma migração que falhou no meio e precisa de correção manual
+	ErrDirty = errors.New("database is dirty")
+	// ErrUnknownVersion indica uma versão que não existe entre as migrações embutidas
+	ErrUnknownVersion = errors.New("unknown migration version")
+	// ErrPending indica migrações embutidas que ainda não foram aplicadas
+	ErrPending = errors.New("database has pending migrations")
+)
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Script é o SQL de um sentido da migração
+type Script struct {
+	SQL           string
+	NoTransaction bool
+}
+
+// Migration é um par de scripts up/down com a mesma versão
+type Migration struct {
+	Version int64
+	Name    string
+	Up      Script
+	// Nil quando a migração não pode ser revertida
+	Down *Script
+}
+
+// Status é a situação de uma versão no banco
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	Dirty     bool       `json:"dirty"`
+	AppliedAt *time.Time `json:"applied_at"`
+	// Registrada no banco, mas ausente deste binário
+	Unknown bool `json:"unknown"`
+}
+
+type record struct {
+	Version   int64     `db:"version"`
+	Name      string    `db:"name"`
+	Dirty     bool      `db:"dirty"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+
+// Load lê os arquivos NNN_nome.up.sql e NNN_nome.down.sql da raiz de fsys
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", entry.Name())
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		script := Script{SQL: string(content), NoTransaction: hasDirective(string(content))}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %q and %q", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = script
+		} else {
+			migration.Down = &script
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up.SQL == "" {
+			return nil, fmt.Errorf("migration %d (%s) has no up script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func hasDirective(sql string) bool {
+	for _, line := range strings.Split(sql, "\n") {
+		if strings.TrimSpace(line) == noTransactionDirective {
+			return true
+		}
+	}
+	return false
+}
+
+// dialect reúne o SQL que muda entre os bancos suportados
+type dialect struct {
+	// Bloqueio que impede duas réplicas de migrarem ao mesmo tempo; vazio no
+	// SQLite, usado por um único processo
+	lock, unlock string
+	// Tipo da coluna applied_at
+	timestamp string
+	// Consulta que informa se a tabela $1 existe
+	exists string
+}
+
+var dialects = map[string]dialect{
+	"postgres": {
+		lock:      `SELECT pg_advisory_lock($1)`,
+		unlock:    `SELECT pg_advisory_unlock($1)`,
+		timestamp: "TIMESTAMPTZ",
+		exists:    `SELECT to_regclass($1) IS NOT NULL`,
+	},
+	"sqlite": {
+		timestamp: "TIMESTAMP",
+		exists:    `SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = $1)`,
+	},
+}
+
+// Migrator aplica e reverte as migrações em um banco Postgres ou SQLite
+type Migrator struct {
+	db         *sqlx.DB
+	dialect    dialect
+	migrations []Migration
+}
+
+func New(db *sqlx.DB, fsys fs.FS) (*Migrator, error) {
+	dialect, ok := dialects[db.DriverName()]
+	if !ok {
+		return nil, fmt.Errorf("migrations are not supported for driver %q", db.DriverName())
+	}
+
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		dialect:    dialect,
+		migrations: migrations,
+	}, nil
+}
+
+// Up aplica todas as migrações pendentes e retorna quantas foram aplicadas
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.locked(ctx, func(conn *sqlx.Conn) error {
+		records, err := m.records(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := checkDirty(records); err != nil {
+			return err
+		}
+
+		done := make(map[int64]bool, len(records))
+		for _, r := range records {
+			done[r.Version] = true
+		}
+
+		for _, migration := range m.migrations {
+			if done[migration.Version] {
+				continue
+			}
+			if err := m.run(ctx, conn, migration, migration.Up, true); err != nil {
+				return err
+			}
+			applied++
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverte as últimas steps migrações aplicadas e retorna quantas foram revertidas
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+	err := m.locked(ctx, func(conn *sqlx.Conn) error {
+		records, err := m.records(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := checkDirty(records); err != nil {
+			return err
+		}
+
+		for i := len(records) - 1; i >= 0 && reverted < steps; i-- {
+			migration, ok := m.find(records[i].Version)
+			if !ok {
+				return fmt.Errorf("%w: %d is applied but not embedded in this binary", ErrUnknownVersion, records[i].Version)
+			}
+			if migration.Down == nil {
+				return fmt.Errorf("migration %d (%s) has no down script", migration.Version, migration.Name)
+			}
+			if err := m.run(ctx, conn, migration, *migration.Down, false); err != nil {
+				return err
+			}
+			reverted++
+		}
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Force marca as migrações até version como aplicadas e sem erro, e as
+// posteriores como não aplicadas, sem executar nenhum script. Serve para
+// recuperar o banco depois de corrigir à mão uma migração que falhou.
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	if _, ok := m.find(version); !ok && version != 0 {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	return m.locked(ctx, func(conn *sqlx.Conn) error {
+		tx, err := conn.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE version > $1`, version); err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if migration.Version > version {
+				break
+			}
+			if err := markApplied(ctx, tx, migration); err != nil {
+				return err
+			}
+		}
+
+		return tx.Commit()
+	})
+}
+
+// Status lista as migrações conhecidas e as registradas no banco, por versão
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var exists bool
+	if err := m.db.GetContext(ctx, &exists, m.dialect.exists, table); err != nil {
+		return nil, err
+	}
+
+	var records []record
+	if exists {
+		if err := m.db.SelectContext(ctx, &records, `SELECT version, name, dirty, applied_at FROM `+table+` ORDER BY version`); err != nil {
+			return nil, err
+		}
+	}
+
+	byVersion := make(map[int64]record, len(records))
+	for _, r := range records {
+		byVersion[r.Version] = r
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if r, ok := byVersion[migration.Version]; ok {
+			appliedAt := r.AppliedAt
+			status.Applied = true
+			status.Dirty = r.Dirty
+			status.AppliedAt = &appliedAt
+			delete(byVersion, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, r := range byVersion {
+		appliedAt := r.AppliedAt
+		statuses = append(statuses, Status{
+			Version:   r.Version,
+			Name:      r.Name,
+			Applied:   true,
+			Dirty:     r.Dirty,
+			AppliedAt: &appliedAt,
+			Unknown:   true,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// Check confirma que todas as migrações embutidas foram aplicadas e nenhuma
+// está suja. Versões mais novas que o binário (de uma réplica já atualizada)
+// não são erro.
+func (m *Migrator) Check(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		if status.Dirty {
+			return fmt.Errorf("%w: migration %d (%s) failed", ErrDirty, status.Version, status.Name)
+		}
+		if !status.Applied {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%w: %d of %d not applied", ErrPending, pending, len(m.migrations))
+	}
+	return nil
+}
+
+// locked executa fn em uma conexão exclusiva que mantém o advisory lock, no
+// Postgres
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sqlx.Conn) error) error {
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.dialect.lock != "" {
+		if _, err := conn.ExecContext(ctx, m.dialect.lock, lockID); err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		defer conn.ExecContext(context.Background(), m.dialect.unlock, lockID)
+	}
+
+	schema := `CREATE TABLE IF NOT EXISTS ` + table + ` (
+        version BIGINT PRIMARY KEY,
+        name TEXT NOT NULL,
+        dirty BOOLEAN NOT NULL DEFAULT FALSE,
+        applied_at ` + m.dialect.timestamp + ` NOT NULL DEFAULT CURRENT_TIMESTAMP
+    )`
+	if _, err := conn.ExecContext(ctx, schema); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) records(ctx context.Context, conn *sqlx.Conn) ([]record, error) {
+	var records []record
+	err := conn.SelectContext(ctx, &records, `SELECT version, name, dirty, applied_at FROM `+table+` ORDER BY version`)
+	return records, err
+}
+
+func (m *Migrator) find(version int64) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// run executa um script e atualiza o registro da versão na mesma transação.
+// Scripts sem transação marcam a versão como suja antes de rodar, para que uma
+// falha no meio fique visível em Status e bloqueie novas migrações.
+func (m *Migrator) run(ctx context.Context, conn *sqlx.Conn, migration Migration, script Script, up bool) error {
+	direction := "up"
+	if !up {
+		direction = "down"
+	}
+	wrap := func(err error) error {
+		return fmt.Errorf("migration %d (%s) %s: %w", migration.Version, migration.Name, direction, err)
+	}
+
+	if script.NoTransaction {
+		const dirty = `INSERT INTO ` + table + ` (version, name, dirty) VALUES ($1, $2, TRUE)
+            ON CONFLICT (version) DO UPDATE SET dirty = TRUE`
+		if _, err := conn.ExecContext(ctx, dirty, migration.Version, migration.Name); err != nil {
+			return wrap(err)
+		}
+		if _, err := conn.ExecContext(ctx, script.SQL); err != nil {
+			return wrap(err)
+		}
+		if err := finish(ctx, conn, migration, up); err != nil {
+			return wrap(err)
+		}
+	} else {
+		tx, err := conn.BeginTxx(ctx, nil)
+		if err != nil {
+			return wrap(err)
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.ExecContext(ctx, script.SQL); err != nil {
+			return wrap(err)
+		}
+		if err := finish(ctx, tx, migration, up); err != nil {
+			return wrap(err)
+		}
+		if err := tx.Commit(); err != nil {
+			return wrap(err)
+		}
+	}
+
+	slog.Info("Migration applied", "version", migration.Version, "name", migration.Name, "direction", direction)
+	return nil
+}
+
+// finish registra a versão como aplicada (up) ou remove o registro (down)
+func finish(ctx context.Context, exec sqlx.ExecerContext, migration Migration, up bool) error {
+	if up {
+		return markApplied(ctx, exec, migration)
+	}
+	_, err := exec.ExecContext(ctx, `DELETE FROM `+table+` WHERE version = $1`, migration.Version)
+	return err
+}
+
+func markApplied(ctx context.Context, exec sqlx.ExecerContext, migration Migration) error {
+	const query = `INSERT INTO ` + table + ` (version, name, dirty, applied_at) VALUES ($1, $2, FALSE, CURRENT_TIMESTAMP)
+        ON CONFLICT (version) DO UPDATE SET dirty = FALSE`
+	_, err := exec.ExecContext(ctx, query, migration.Version, migration.Name)
+	return err
+}
+
+func checkDirty(records []record) error {
+	for _, r := range records {
+		if r.Dirty {
+			return fmt.Errorf("%w: migration %d (%s) failed; fix it by hand and run migrate force", ErrDirty, r.Version, r.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/config"
+)
+
+func TestSetupPropagatesTraceContext(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.TracingConfig{Exporter: ExporterNone, SampleRatio: 1})
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	defer shutdown(context.Background())
+
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	incoming := http.Header{"Traceparent": {traceparent}}
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(incoming))
+
+	outgoing := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(outgoing))
+	if got := outgoing.Get("Traceparent"); got != traceparent {
+		t.Errorf("traceparent = %q, want %q", got, traceparent)
+	}
+}
+
+func TestSetupInvalidConfig(t *testing.T) {
+	tests := []config.TracingConfig{
+		{Exporter: "zipkin", SampleRatio: 1},
+		{Exporter: ExporterStdout, SampleRatio: 1.5},
+	}
+	for _, cfg := range tests {
+		if _, err := Setup(context.Background(), cfg); err == nil {
+			t.Errorf("Setup(%+v) error = nil", cfg)
+		}
+	}
+}
+
+func TestStdoutExporter(t *testing.T) {
+	var out bytes.Buffer
+	exporter, err := newExporter(context.Background(), config.TracingConfig{Exporter: "STDOUT"}, &out)
+	if err != nil {
+		t.Fatalf("newExporter() error = %v", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	_, span := provider.Tracer("test").Start(context.Background(), "BookService.ListBooks")
+	span.End()
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	if !strings.Contains(out.String(), `"Name":"BookService.ListBooks"`) {
+		t.Errorf("stdout exporter output = %s", out.String())
+	}
+}
@@ -0,0 +1,117 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+type breakerProvider struct {
+	MetadataProvider
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// WithCircuitBreaker deixa de consultar o catálogo após threshold falhas
+// seguidas. Depois de cooldown uma única consulta de teste é liberada: se ela
+// funcionar o circuito fecha, senão volta a abrir. ISBN não encontrado não
+// conta como falha.
+func WithCircuitBreaker(provider MetadataProvider, threshold int, cooldown time.Duration) MetadataProvider {
+	if threshold <= 0 {
+		threshold = 5
+	}
+	if cooldown <= 0 {
+		cooldown = 30 * time.Second
+	}
+	return &breakerProvider{
+		MetadataProvider: provider,
+		threshold:        threshold,
+		cooldown:         cooldown,
+		now:              time.Now,
+	}
+}
+
+func (p *breakerProvider) Lookup(ctx context.Context, isbn domain.ISBN) (*domain.BookMetadata, error) {
+	if !p.allow() {
+		return nil, fmt.Errorf("%w: circuit open for %s", domain.ErrMetadataUnavailable, p.Name())
+	}
+
+	metadata, err := p.MetadataProvider.Lookup(ctx, isbn)
+	switch {
+	case err == nil || errors.Is(err, domain.ErrMetadataNotFound):
+		p.succeed()
+	case ctx.Err() != nil:
+		// O cliente desistiu; isso não diz nada sobre o catálogo
+		p.release()
+	default:
+		p.fail()
+	}
+
+	return metadata, err
+}
+
+func (p *breakerProvider) allow() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch p.state {
+	case breakerOpen:
+		if p.now().Sub(p.openedAt) < p.cooldown {
+			return false
+		}
+		p.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		// Já existe uma consulta de teste em andamento
+		return false
+	default:
+		return true
+	}
+}
+
+func (p *breakerProvider) succeed() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.state = breakerClosed
+	p.failures = 0
+}
+
+func (p *breakerProvider) fail() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.failures++
+	if p.state == breakerHalfOpen || p.failures >= p.threshold {
+		p.state = breakerOpen
+		p.openedAt = p.now()
+	}
+}
+
+func (p *breakerProvider) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.state == breakerHalfOpen {
+		// Libera uma nova consulta de teste imediatamente
+		p.state = breakerOpen
+	}
+}
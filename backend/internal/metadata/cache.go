@@ -0,0 +1,102 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+// ISBNs não encontrados ficam em cache por menos tempo, já que o catálogo
+// pode passar a conhecê-los
+const maxNotFoundTTL = time.Hour
+
+type cacheEntry struct {
+	metadata  *domain.BookMetadata
+	expiresAt time.Time
+}
+
+type cachedProvider struct {
+	MetadataProvider
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[domain.ISBN]cacheEntry
+	// Ordem de inserção, usada para descartar as entradas mais antigas
+	order []domain.ISBN
+}
+
+// WithCache guarda em memória as respostas do catálogo, inclusive as de ISBN
+// não encontrado. Falhas não são guardadas.
+func WithCache(provider MetadataProvider, ttl time.Duration, maxEntries int) MetadataProvider {
+	if maxEntries <= 0 {
+		maxEntries = 1000
+	}
+	return &cachedProvider{
+		MetadataProvider: provider,
+		ttl:              ttl,
+		maxEntries:       maxEntries,
+		now:              time.Now,
+		entries:          make(map[domain.ISBN]cacheEntry),
+	}
+}
+
+func (p *cachedProvider) Lookup(ctx context.Context, isbn domain.ISBN) (*domain.BookMetadata, error) {
+	if entry, ok := p.get(isbn); ok {
+		if entry.metadata == nil {
+			return nil, domain.ErrMetadataNotFound
+		}
+		return copyMetadata(entry.metadata), nil
+	}
+
+	metadata, err := p.MetadataProvider.Lookup(ctx, isbn)
+	switch {
+	case err == nil:
+		p.set(isbn, copyMetadata(metadata), p.ttl)
+		return metadata, nil
+	case errors.Is(err, domain.ErrMetadataNotFound):
+		ttl := p.ttl
+		if ttl > maxNotFoundTTL {
+			ttl = maxNotFoundTTL
+		}
+		p.set(isbn, nil, ttl)
+	}
+
+	return nil, err
+}
+
+func (p *cachedProvider) get(isbn domain.ISBN) (cacheEntry, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry, ok := p.entries[isbn]
+	if !ok || p.now().After(entry.expiresAt) {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (p *cachedProvider) set(isbn domain.ISBN, metadata *domain.BookMetadata, ttl time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.entries[isbn]; !exists {
+		for len(p.entries) >= p.maxEntries && len(p.order) > 0 {
+			delete(p.entries, p.order[0])
+			p.order = p.order[1:]
+		}
+		p.order = append(p.order, isbn)
+	}
+
+	p.entries[isbn] = cacheEntry{metadata: metadata, expiresAt: p.now().Add(ttl)}
+}
+
+func copyMetadata(metadata *domain.BookMetadata) *domain.BookMetadata {
+	copied := *metadata
+	copied.Subjects = append([]string(nil), metadata.Subjects...)
+	return &copied
+}
@@ -0,0 +1,57 @@
+// Package fixture implementa um catálogo falso que lê os metadados de
+// arquivos JSON, para testes e desenvolvimento sem acesso à internet
+package fixture
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/metadata"
+)
+
+const Name = "fixture"
+
+type provider struct {
+	dir string
+}
+
+// NewProvider lê os metadados de <dir>/<ISBN-13>.json, no formato de
+// domain.BookMetadata. ISBNs sem arquivo não são encontrados.
+func NewProvider(dir string) metadata.MetadataProvider {
+	return &provider{dir: dir}
+}
+
+func (p *provider) Name() string {
+	return Name
+}
+
+func (p *provider) Lookup(ctx context.Context, isbn domain.ISBN) (*domain.BookMetadata, error) {
+	data, err := os.ReadFile(filepath.Join(p.dir, isbn.String()+".json"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, domain.ErrMetadataNotFound
+		}
+		return nil, err
+	}
+
+	var result domain.BookMetadata
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("invalid fixture for %s: %w", isbn, err)
+	}
+
+	if result.Source == "" {
+		result.Source = Name
+	}
+	result.ISBN = isbn.String()
+	if result.Subjects == nil {
+		result.Subjects = []string{}
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,111 @@
+// Package googlebooks consulta a API de volumes do Google Books
+// (https://developers.google.com/books/docs/v1/using)
+package googlebooks
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/metadata"
+)
+
+const (
+	Name           = "googlebooks"
+	DefaultBaseURL = "https://www.googleapis.com/books/v1"
+)
+
+type provider struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+}
+
+// NewProvider cria o provedor; baseURL vazio usa a API pública. A chave de
+// API é opcional, mas sem ela a cota de consultas é bem menor.
+func NewProvider(client *http.Client, baseURL, apiKey string) metadata.MetadataProvider {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &provider{
+		client:  client,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+	}
+}
+
+type volumes struct {
+	TotalItems int `json:"totalItems"`
+	Items      []struct {
+		VolumeInfo volumeInfo `json:"volumeInfo"`
+	} `json:"items"`
+}
+
+type volumeInfo struct {
+	Title         string   `json:"title"`
+	Subtitle      string   `json:"subtitle"`
+	Authors       []string `json:"authors"`
+	Publisher     string   `json:"publisher"`
+	PublishedDate string   `json:"publishedDate"`
+	Description   string   `json:"description"`
+	Categories    []string `json:"categories"`
+	ImageLinks    struct {
+		SmallThumbnail string `json:"smallThumbnail"`
+		Thumbnail      string `json:"thumbnail"`
+	} `json:"imageLinks"`
+}
+
+func (p *provider) Name() string {
+	return Name
+}
+
+func (p *provider) Lookup(ctx context.Context, isbn domain.ISBN) (*domain.BookMetadata, error) {
+	query := url.Values{"q": {"isbn:" + isbn.String()}}
+	if p.apiKey != "" {
+		query.Set("key", p.apiKey)
+	}
+
+	var response volumes
+	if err := metadata.GetJSON(ctx, p.client, p.baseURL+"/volumes?"+query.Encode(), &response); err != nil {
+		return nil, err
+	}
+
+	if response.TotalItems == 0 || len(response.Items) == 0 || response.Items[0].VolumeInfo.Title == "" {
+		return nil, domain.ErrMetadataNotFound
+	}
+
+	info := response.Items[0].VolumeInfo
+	result := &domain.BookMetadata{
+		Source:          Name,
+		ISBN:            isbn.String(),
+		Title:           info.Title,
+		Author:          strings.Join(info.Authors, ", "),
+		Description:     info.Description,
+		Publisher:       info.Publisher,
+		PublicationYear: metadata.Year(info.PublishedDate),
+		CoverURL:        coverURL(info),
+		Subjects:        append([]string{}, info.Categories...),
+	}
+
+	if info.Subtitle != "" {
+		result.Title += ": " + info.Subtitle
+	}
+
+	return result, nil
+}
+
+// coverURL devolve a miniatura por HTTPS e sem o efeito de página dobrada
+func coverURL(info volumeInfo) string {
+	link := info.ImageLinks.Thumbnail
+	if link == "" {
+		link = info.ImageLinks.SmallThumbnail
+	}
+	if link == "" {
+		return ""
+	}
+
+	link = strings.Replace(link, "http://", "https://", 1)
+	return strings.Replace(link, "&edge=curl", "", 1)
+}
@@ -0,0 +1,78 @@
+package googlebooks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+// newServer responde com testdata/<isbn>.json, ou com uma busca vazia se o
+// arquivo não existe, como a API real faz com ISBNs desconhecidos
+func newServer(t *testing.T, wantKey string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/volumes" || r.URL.Query().Get("key") != wantKey {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		isbn := strings.TrimPrefix(r.URL.Query().Get("q"), "isbn:")
+		data, err := os.ReadFile("testdata/" + isbn + ".json")
+		if err != nil {
+			data = []byte(`{"kind": "books#volumes", "totalItems": 0}`)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLookup(t *testing.T) {
+	server := newServer(t, "secret")
+	provider := NewProvider(server.Client(), server.URL, "secret")
+
+	result, err := provider.Lookup(context.Background(), domain.ISBN("9780261103573"))
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+
+	want := domain.BookMetadata{
+		Source:          Name,
+		ISBN:            "9780261103573",
+		Title:           "The Fellowship of the Ring: Being the First Part of The Lord of the Rings",
+		Author:          "J. R. R. Tolkien",
+		Description:     "The first volume of the epic trilogy.",
+		CoverURL:        "https://books.google.com/books/content?id=aWZzLPhY4o0C&printsec=frontcover&img=1&zoom=1&source=gbs_api",
+		Publisher:       "HarperCollins",
+		PublicationYear: 1999,
+		Subjects:        []string{"Fiction"},
+	}
+	if !reflect.DeepEqual(*result, want) {
+		t.Errorf("Lookup() = %+v, want %+v", *result, want)
+	}
+}
+
+func TestLookupNotFound(t *testing.T) {
+	server := newServer(t, "")
+	provider := NewProvider(server.Client(), server.URL, "")
+
+	if _, err := provider.Lookup(context.Background(), domain.ISBN("9780306406157")); !errors.Is(err, domain.ErrMetadataNotFound) {
+		t.Errorf("Lookup() error = %v, want ErrMetadataNotFound", err)
+	}
+}
+
+func TestLookupServerError(t *testing.T) {
+	server := newServer(t, "expected")
+	provider := NewProvider(server.Client(), server.URL, "wrong")
+
+	_, err := provider.Lookup(context.Background(), domain.ISBN("9780261103573"))
+	if err == nil || errors.Is(err, domain.ErrMetadataNotFound) {
+		t.Errorf("Lookup() error = %v, want a provider failure", err)
+	}
+}
@@ -0,0 +1,54 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+// UserAgent identifica a aplicação nas consultas aos catálogos
+const UserAgent = "BookFlow/1.0"
+
+// Tamanho máximo aceito de uma resposta de catálogo
+const maxResponseSize = 4 << 20
+
+var yearPattern = regexp.MustCompile(`[0-9]{4}`)
+
+// GetJSON faz um GET e decodifica a resposta JSON em target. Uma resposta 404
+// é devolvida como domain.ErrMetadataNotFound.
+func GetJSON(ctx context.Context, client *http.Client, url string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", UserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return domain.ErrMetadataNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(target)
+}
+
+// Year extrai o ano de uma data em formato livre ("2000", "May 2000",
+// "2000-05-01"); zero se não houver ano
+func Year(date string) int {
+	year, _ := strconv.Atoi(yearPattern.FindString(date))
+	return year
+}
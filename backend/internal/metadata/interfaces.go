@@ -0,0 +1,19 @@
+// Package metadata define os catálogos externos consultados para preencher os
+// dados de um livro a partir do ISBN. As implementações ficam nos
+// subpacotes; aqui estão os decoradores de cache, timeout e circuit breaker.
+package metadata
+
+import (
+	"context"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+// MetadataProvider consulta um catálogo externo pelo ISBN
+type MetadataProvider interface {
+	// Name identifica o catálogo, ex.: "openlibrary"
+	Name() string
+	// Lookup devolve os metadados do livro ou domain.ErrMetadataNotFound se o
+	// catálogo não conhecer o ISBN
+	Lookup(ctx context.Context, isbn domain.ISBN) (*domain.BookMetadata, error)
+}
@@ -0,0 +1,99 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+// Options configura a composição montada por New
+type Options struct {
+	// Tempo máximo de cada consulta a um catálogo
+	Timeout time.Duration
+	// Tempo pelo qual uma resposta fica em cache; zero desativa o cache
+	CacheTTL time.Duration
+	// Quantidade máxima de ISBNs em cache
+	CacheSize int
+	// Falhas seguidas que abrem o circuito de um catálogo
+	FailureThreshold int
+	// Tempo que o circuito fica aberto antes de uma nova tentativa
+	Cooldown time.Duration
+}
+
+// New consulta os catálogos em ordem, cada um com timeout e circuit breaker
+// próprios, e guarda as respostas em cache
+func New(opts Options, providers ...MetadataProvider) MetadataProvider {
+	guarded := make([]MetadataProvider, len(providers))
+	for i, provider := range providers {
+		guarded[i] = WithCircuitBreaker(WithTimeout(provider, opts.Timeout), opts.FailureThreshold, opts.Cooldown)
+	}
+
+	provider := Chain(guarded...)
+	if opts.CacheTTL > 0 {
+		provider = WithCache(provider, opts.CacheTTL, opts.CacheSize)
+	}
+	return provider
+}
+
+type chain []MetadataProvider
+
+// Chain consulta os catálogos em ordem e devolve a primeira resposta
+// encontrada. Se nenhum encontrar o ISBN e algum tiver falhado, o resultado é
+// domain.ErrMetadataUnavailable, já que o livro pode existir no catálogo que
+// não respondeu.
+func Chain(providers ...MetadataProvider) MetadataProvider {
+	return chain(providers)
+}
+
+func (c chain) Name() string {
+	names := make([]string, len(c))
+	for i, provider := range c {
+		names[i] = provider.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+func (c chain) Lookup(ctx context.Context, isbn domain.ISBN) (*domain.BookMetadata, error) {
+	var failures []string
+	for _, provider := range c {
+		metadata, err := provider.Lookup(ctx, isbn)
+		if err == nil {
+			return metadata, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !errors.Is(err, domain.ErrMetadataNotFound) {
+			failures = append(failures, fmt.Sprintf("%s: %v", provider.Name(), err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return nil, fmt.Errorf("%w: %s", domain.ErrMetadataUnavailable, strings.Join(failures, "; "))
+	}
+	return nil, domain.ErrMetadataNotFound
+}
+
+type timeoutProvider struct {
+	MetadataProvider
+	timeout time.Duration
+}
+
+// WithTimeout limita o tempo de cada consulta; zero não impõe limite
+func WithTimeout(provider MetadataProvider, timeout time.Duration) MetadataProvider {
+	if timeout <= 0 {
+		return provider
+	}
+	return &timeoutProvider{MetadataProvider: provider, timeout: timeout}
+}
+
+func (p *timeoutProvider) Lookup(ctx context.Context, isbn domain.ISBN) (*domain.BookMetadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	return p.MetadataProvider.Lookup(ctx, isbn)
+}
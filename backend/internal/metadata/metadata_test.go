@@ -0,0 +1,206 @@
+package metadata_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/metadata"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/metadata/fixture"
+)
+
+const (
+	knownISBN   = domain.ISBN("9788533615120")
+	unknownISBN = domain.ISBN("9780306406157")
+)
+
+// Os mesmos arquivos usados em desenvolvimento com METADATA_PROVIDERS=fixture
+const fixturesDir = "../../fixtures/metadata"
+
+// stubProvider conta as consultas e delega a lookup, se definida
+type stubProvider struct {
+	name   string
+	lookup func(ctx context.Context, isbn domain.ISBN) (*domain.BookMetadata, error)
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (p *stubProvider) Name() string {
+	return p.name
+}
+
+func (p *stubProvider) Lookup(ctx context.Context, isbn domain.ISBN) (*domain.BookMetadata, error) {
+	p.mu.Lock()
+	p.calls++
+	p.mu.Unlock()
+	return p.lookup(ctx, isbn)
+}
+
+func (p *stubProvider) Calls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls
+}
+
+func failing(err error) *stubProvider {
+	return &stubProvider{name: "failing", lookup: func(context.Context, domain.ISBN) (*domain.BookMetadata, error) {
+		return nil, err
+	}}
+}
+
+func counting(provider metadata.MetadataProvider) *stubProvider {
+	return &stubProvider{name: provider.Name(), lookup: provider.Lookup}
+}
+
+func TestFixtureProvider(t *testing.T) {
+	provider := fixture.NewProvider(fixturesDir)
+
+	result, err := provider.Lookup(context.Background(), knownISBN)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if result.Title != "O Senhor dos Anéis" || result.ISBN != knownISBN.String() || result.Source != fixture.Name {
+		t.Errorf("Lookup() = %+v", result)
+	}
+
+	if _, err := provider.Lookup(context.Background(), unknownISBN); !errors.Is(err, domain.ErrMetadataNotFound) {
+		t.Errorf("Lookup() of an unknown ISBN error = %v, want ErrMetadataNotFound", err)
+	}
+}
+
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+	notFound := failing(domain.ErrMetadataNotFound)
+	broken := failing(errors.New("connection refused"))
+	fixtures := fixture.NewProvider(fixturesDir)
+
+	if result, err := metadata.Chain(notFound, broken, fixtures).Lookup(ctx, knownISBN); err != nil || result.Source != fixture.Name {
+		t.Errorf("Chain found %+v, %v; want the fixture result", result, err)
+	}
+
+	if _, err := metadata.Chain(notFound, fixtures).Lookup(ctx, unknownISBN); !errors.Is(err, domain.ErrMetadataNotFound) {
+		t.Errorf("Chain error = %v, want ErrMetadataNotFound", err)
+	}
+
+	// Um catálogo fora do ar impede afirmar que o livro não existe
+	if _, err := metadata.Chain(broken, fixtures).Lookup(ctx, unknownISBN); !errors.Is(err, domain.ErrMetadataUnavailable) {
+		t.Errorf("Chain error = %v, want ErrMetadataUnavailable", err)
+	}
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	source := counting(fixture.NewProvider(fixturesDir))
+	cached := metadata.WithCache(source, time.Hour, 10)
+
+	for i := 0; i < 3; i++ {
+		result, err := cached.Lookup(ctx, knownISBN)
+		if err != nil {
+			t.Fatalf("Lookup() error = %v", err)
+		}
+		// Alterar o resultado não pode afetar o que está em cache
+		result.Title = "alterado"
+		result.Subjects[0] = "alterado"
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := cached.Lookup(ctx, unknownISBN); !errors.Is(err, domain.ErrMetadataNotFound) {
+			t.Fatalf("Lookup() error = %v, want ErrMetadataNotFound", err)
+		}
+	}
+
+	if calls := source.Calls(); calls != 2 {
+		t.Errorf("provider called %d times, want 2", calls)
+	}
+
+	result, _ := cached.Lookup(ctx, knownISBN)
+	if result.Title != "O Senhor dos Anéis" || result.Subjects[0] != "Fantasia" {
+		t.Errorf("cached result was modified: %+v", result)
+	}
+}
+
+func TestCacheDoesNotStoreFailures(t *testing.T) {
+	broken := failing(errors.New("timeout"))
+	cached := metadata.WithCache(broken, time.Hour, 10)
+
+	cached.Lookup(context.Background(), knownISBN)
+	cached.Lookup(context.Background(), knownISBN)
+
+	if calls := broken.Calls(); calls != 2 {
+		t.Errorf("provider called %d times, want 2", calls)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	healthy := true
+	source := &stubProvider{name: "flaky", lookup: func(context.Context, domain.ISBN) (*domain.BookMetadata, error) {
+		if healthy {
+			return &domain.BookMetadata{Title: "ok"}, nil
+		}
+		return nil, errors.New("503 Service Unavailable")
+	}}
+	cooldown := 50 * time.Millisecond
+	breaker := metadata.WithCircuitBreaker(source, 3, cooldown)
+
+	healthy = false
+	for i := 0; i < 3; i++ {
+		breaker.Lookup(ctx, knownISBN)
+	}
+
+	// Circuito aberto: o catálogo não é mais consultado
+	if _, err := breaker.Lookup(ctx, knownISBN); !errors.Is(err, domain.ErrMetadataUnavailable) {
+		t.Fatalf("Lookup() with open circuit error = %v, want ErrMetadataUnavailable", err)
+	}
+	if calls := source.Calls(); calls != 3 {
+		t.Fatalf("provider called %d times, want 3", calls)
+	}
+
+	// A consulta de teste falha e o circuito volta a abrir
+	time.Sleep(cooldown)
+	breaker.Lookup(ctx, knownISBN)
+	if _, err := breaker.Lookup(ctx, knownISBN); !errors.Is(err, domain.ErrMetadataUnavailable) {
+		t.Fatalf("Lookup() after failed probe error = %v, want ErrMetadataUnavailable", err)
+	}
+
+	// A consulta de teste funciona e o circuito fecha
+	healthy = true
+	time.Sleep(cooldown)
+	for i := 0; i < 3; i++ {
+		if _, err := breaker.Lookup(ctx, knownISBN); err != nil {
+			t.Fatalf("Lookup() after recovery error = %v", err)
+		}
+	}
+}
+
+func TestCircuitBreakerIgnoresNotFound(t *testing.T) {
+	source := failing(domain.ErrMetadataNotFound)
+	breaker := metadata.WithCircuitBreaker(source, 2, time.Minute)
+
+	for i := 0; i < 5; i++ {
+		if _, err := breaker.Lookup(context.Background(), unknownISBN); !errors.Is(err, domain.ErrMetadataNotFound) {
+			t.Fatalf("Lookup() error = %v, want ErrMetadataNotFound", err)
+		}
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	slow := &stubProvider{name: "slow", lookup: func(ctx context.Context, isbn domain.ISBN) (*domain.BookMetadata, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}}
+
+	provider := metadata.New(metadata.Options{Timeout: 20 * time.Millisecond}, slow)
+
+	start := time.Now()
+	_, err := provider.Lookup(context.Background(), knownISBN)
+	if !errors.Is(err, domain.ErrMetadataUnavailable) {
+		t.Errorf("Lookup() error = %v, want ErrMetadataUnavailable", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Lookup() took %v, timeout not applied", elapsed)
+	}
+}
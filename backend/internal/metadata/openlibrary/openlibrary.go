@@ -0,0 +1,148 @@
+// Package openlibrary consulta a API de livros da Open Library
+// (https://openlibrary.org/dev/docs/api/books)
+package openlibrary
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/metadata"
+)
+
+const (
+	Name           = "openlibrary"
+	DefaultBaseURL = "https://openlibrary.org"
+
+	// A Open Library pode listar dezenas de assuntos por livro
+	maxSubjects = 10
+)
+
+type provider struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewProvider cria o provedor; baseURL vazio usa a API pública
+func NewProvider(client *http.Client, baseURL string) metadata.MetadataProvider {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &provider{
+		client:  client,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+type named struct {
+	Name string `json:"name"`
+}
+
+type edition struct {
+	Title       string          `json:"title"`
+	Subtitle    string          `json:"subtitle"`
+	Authors     []named         `json:"authors"`
+	Publishers  []named         `json:"publishers"`
+	PublishDate string          `json:"publish_date"`
+	Subjects    []named         `json:"subjects"`
+	Notes       json.RawMessage `json:"notes"`
+	Excerpts    []struct {
+		Text string `json:"text"`
+	} `json:"excerpts"`
+	Cover struct {
+		Small  string `json:"small"`
+		Medium string `json:"medium"`
+		Large  string `json:"large"`
+	} `json:"cover"`
+}
+
+func (p *provider) Name() string {
+	return Name
+}
+
+func (p *provider) Lookup(ctx context.Context, isbn domain.ISBN) (*domain.BookMetadata, error) {
+	key := "ISBN:" + isbn.String()
+	query := url.Values{
+		"bibkeys": {key},
+		"format":  {"json"},
+		"jscmd":   {"data"},
+	}
+
+	// A resposta é um objeto indexado pela chave pedida; vazio se o ISBN não existe
+	var response map[string]edition
+	if err := metadata.GetJSON(ctx, p.client, p.baseURL+"/api/books?"+query.Encode(), &response); err != nil {
+		return nil, err
+	}
+
+	book, ok := response[key]
+	if !ok || book.Title == "" {
+		return nil, domain.ErrMetadataNotFound
+	}
+
+	result := &domain.BookMetadata{
+		Source:          Name,
+		ISBN:            isbn.String(),
+		Title:           book.Title,
+		Author:          joinNames(book.Authors),
+		Description:     description(book),
+		Publisher:       joinNames(book.Publishers),
+		PublicationYear: metadata.Year(book.PublishDate),
+		Subjects:        []string{},
+	}
+
+	if book.Subtitle != "" {
+		result.Title += ": " + book.Subtitle
+	}
+
+	switch {
+	case book.Cover.Large != "":
+		result.CoverURL = book.Cover.Large
+	case book.Cover.Medium != "":
+		result.CoverURL = book.Cover.Medium
+	default:
+		result.CoverURL = book.Cover.Small
+	}
+
+	for _, subject := range book.Subjects {
+		if len(result.Subjects) == maxSubjects {
+			break
+		}
+		result.Subjects = append(result.Subjects, subject.Name)
+	}
+
+	return result, nil
+}
+
+// description usa as notas da edição, que podem vir como texto ou como
+// {"type": "/type/text", "value": "..."}, ou o primeiro trecho publicado
+func description(book edition) string {
+	var text string
+	if err := json.Unmarshal(book.Notes, &text); err == nil && text != "" {
+		return text
+	}
+
+	var typed struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(book.Notes, &typed); err == nil && typed.Value != "" {
+		return typed.Value
+	}
+
+	if len(book.Excerpts) > 0 {
+		return book.Excerpts[0].Text
+	}
+	return ""
+}
+
+func joinNames(values []named) string {
+	names := make([]string, 0, len(values))
+	for _, value := range values {
+		if value.Name != "" {
+			names = append(names, value.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,91 @@
+package openlibrary
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+// newServer responde com testdata/<isbn>.json, ou {} se o arquivo não existe,
+// como a API real faz com ISBNs desconhecidos
+func newServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/books" || r.URL.Query().Get("jscmd") != "data" {
+			http.NotFound(w, r)
+			return
+		}
+		isbn := strings.TrimPrefix(r.URL.Query().Get("bibkeys"), "ISBN:")
+		data, err := os.ReadFile("testdata/" + isbn + ".json")
+		if err != nil {
+			data = []byte("{}")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLookup(t *testing.T) {
+	server := newServer(t)
+	provider := NewProvider(server.Client(), server.URL)
+
+	result, err := provider.Lookup(context.Background(), domain.ISBN("9780261103573"))
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+
+	want := domain.BookMetadata{
+		Source:          Name,
+		ISBN:            "9780261103573",
+		Title:           "The Fellowship of the Ring: being the first part of The Lord of the Rings",
+		Author:          "J.R.R. Tolkien",
+		Description:     "First volume of the trilogy.",
+		CoverURL:        "https://covers.openlibrary.org/b/id/8474036-L.jpg",
+		Publisher:       "HarperCollins",
+		PublicationYear: 1999,
+		Subjects:        []string{"Fiction", "Middle Earth (Imaginary place)"},
+	}
+	if !reflect.DeepEqual(*result, want) {
+		t.Errorf("Lookup() = %+v, want %+v", *result, want)
+	}
+}
+
+func TestLookupNotFound(t *testing.T) {
+	server := newServer(t)
+	provider := NewProvider(server.Client(), server.URL)
+
+	if _, err := provider.Lookup(context.Background(), domain.ISBN("9780306406157")); !errors.Is(err, domain.ErrMetadataNotFound) {
+		t.Errorf("Lookup() error = %v, want ErrMetadataNotFound", err)
+	}
+}
+
+func TestDescription(t *testing.T) {
+	tests := []struct {
+		name string
+		book edition
+		want string
+	}{
+		{"plain notes", edition{Notes: []byte(`"Notas"`)}, "Notas"},
+		{"typed notes", edition{Notes: []byte(`{"type":"/type/text","value":"Notas"}`)}, "Notas"},
+		{"excerpt", edition{Excerpts: []struct {
+			Text string `json:"text"`
+		}{{Text: "Trecho"}}}, "Trecho"},
+		{"empty", edition{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := description(tt.book); got != tt.want {
+				t.Errorf("description() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
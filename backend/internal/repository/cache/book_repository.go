@@ -0,0 +1,210 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/metrics"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
+)
+
+// Consultas em cache, no label query de metrics.BookCacheRequestsTotal
+const (
+	queryFindByID = "find_by_id"
+	queryFindAll  = "find_all"
+)
+
+// Contador que faz parte das chaves das listagens: incrementá-lo invalida
+// todas as páginas de uma vez, qualquer que seja o tamanho delas
+const listGenerationKey = "books:list:generation"
+
+type bookRepository struct {
+	repository.BookRepository
+	store Store
+	ttl   time.Duration
+	group singleflight.Group
+}
+
+// NewBookRepository guarda em store, por ttl, os livros lidos por FindByID e
+// a primeira página de FindAll, as consultas mais frequentes, lidas do
+// primário e não das réplicas. As escritas invalidam as entradas afetadas;
+// dentro de uma transação as leituras vão direto a repo e a invalidação
+// espera o commit. Uma leitura concorrente com uma escrita ainda pode guardar
+// o valor anterior, que vale até expirar.
+func NewBookRepository(repo repository.BookRepository, store Store, ttl time.Duration) repository.BookRepository {
+	return &bookRepository{
+		BookRepository: repo,
+		store:          store,
+		ttl:            ttl,
+	}
+}
+
+func (r *bookRepository) FindByID(ctx context.Context, id string) (*domain.Book, error) {
+	if repository.InTx(ctx) {
+		return r.BookRepository.FindByID(ctx, id)
+	}
+
+	var book domain.Book
+	err := r.cached(ctx, queryFindByID, bookKey(id), &book, func(ctx context.Context) (interface{}, error) {
+		return r.BookRepository.FindByID(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &book, nil
+}
+
+func (r *bookRepository) FindAll(ctx context.Context, limit, offset int) ([]*domain.Book, error) {
+	if offset != 0 || repository.InTx(ctx) {
+		return r.BookRepository.FindAll(ctx, limit, offset)
+	}
+
+	generation, err := r.store.Counter(ctx, listGenerationKey)
+	if err != nil {
+		metrics.BookCacheRequestsTotal.WithLabelValues(queryFindAll, metrics.CacheError).Inc()
+		slog.WarnContext(ctx, "Book cache unavailable", "error", err)
+		return r.BookRepository.FindAll(ctx, limit, offset)
+	}
+
+	var books []*domain.Book
+	key := fmt.Sprintf("books:list:%d:%d", generation, limit)
+	err = r.cached(ctx, queryFindAll, key, &books, func(ctx context.Context) (interface{}, error) {
+		// Uma réplica atrasada devolveria a lista de antes da última escrita,
+		// que ficaria guardada na geração nova até expirar
+		return r.BookRepository.FindAll(repository.WithPrimary(ctx), limit, offset)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+func (r *bookRepository) Create(ctx context.Context, book *domain.Book) error {
+	if err := r.BookRepository.Create(ctx, book); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx)
+	return nil
+}
+
+func (r *bookRepository) CreateMany(ctx context.Context, books []*domain.Book) error {
+	if err := r.BookRepository.CreateMany(ctx, books); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx)
+	return nil
+}
+
+func (r *bookRepository) Update(ctx context.Context, book *domain.Book) error {
+	err := r.BookRepository.Update(ctx, book)
+	// Um conflito de versão pode vir de uma entrada desatualizada: sem ela, o
+	// cliente relê a versão atual
+	if err == nil || errors.Is(err, domain.ErrVersionConflict) {
+		r.invalidate(ctx, book.ID)
+	}
+
+	return err
+}
+
+func (r *bookRepository) Delete(ctx context.Context, id string, version int64) error {
+	err := r.BookRepository.Delete(ctx, id, version)
+	if err == nil || errors.Is(err, domain.ErrVersionConflict) {
+		r.invalidate(ctx, id)
+	}
+
+	return err
+}
+
+func (r *bookRepository) UpdateCoverVariants(ctx context.Context, id string, variants domain.CoverVariants) error {
+	if err := r.BookRepository.UpdateCoverVariants(ctx, id, variants); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, id)
+	return nil
+}
+
+// cached preenche dest com o valor de key ou, na falta dele, com o resultado
+// de load, que é guardado. Leituras simultâneas de uma chave ausente esperam
+// uma única chamada de load, para que uma entrada expirada não dispare uma
+// consulta por requisição.
+func (r *bookRepository) cached(ctx context.Context, query, key string, dest interface{}, load func(ctx context.Context) (interface{}, error)) error {
+	data, ok, err := r.store.Get(ctx, key)
+	switch {
+	case err != nil:
+		metrics.BookCacheRequestsTotal.WithLabelValues(query, metrics.CacheError).Inc()
+		slog.WarnContext(ctx, "Book cache unavailable", "error", err)
+	case ok:
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(dest); err == nil {
+			metrics.BookCacheRequestsTotal.WithLabelValues(query, metrics.CacheHit).Inc()
+			return nil
+		}
+		// Uma entrada ilegível, de outra versão do Book, é substituída
+		metrics.BookCacheRequestsTotal.WithLabelValues(query, metrics.CacheMiss).Inc()
+	default:
+		metrics.BookCacheRequestsTotal.WithLabelValues(query, metrics.CacheMiss).Inc()
+	}
+
+	result, err, _ := r.group.Do(key, func() (interface{}, error) {
+		// A consulta serve também às requisições que esperam por ela, então
+		// não é cancelada junto com a requisição que a iniciou
+		ctx := context.WithoutCancel(ctx)
+		value, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+			return nil, err
+		}
+		if err := r.store.Set(ctx, key, buf.Bytes(), r.ttl); err != nil {
+			slog.WarnContext(ctx, "Failed to store book in cache", "error", err)
+		}
+		return buf.Bytes(), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Cada chamada decodifica a sua cópia, que pode alterar à vontade
+	return gob.NewDecoder(bytes.NewReader(result.([]byte))).Decode(dest)
+}
+
+// invalidate remove do cache os livros ids e todas as listagens; dentro de
+// uma transação, só depois do commit
+func (r *bookRepository) invalidate(ctx context.Context, ids ...string) {
+	repository.AfterCommit(ctx, func() {
+		ctx := context.WithoutCancel(ctx)
+
+		keys := make([]string, len(ids))
+		for i, id := range ids {
+			keys[i] = bookKey(id)
+			// Leituras iniciadas antes da escrita não são compartilhadas
+			// com as próximas
+			r.group.Forget(keys[i])
+		}
+		if err := r.store.Delete(ctx, keys...); err != nil {
+			slog.WarnContext(ctx, "Failed to invalidate cached books", "error", err)
+		}
+		if _, err := r.store.Incr(ctx, listGenerationKey); err != nil {
+			slog.WarnContext(ctx, "Failed to invalidate cached book lists", "error", err)
+		}
+	})
+}
+
+func bookKey(id string) string {
+	return "books:id:" + id
+}
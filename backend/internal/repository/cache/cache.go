@@ -0,0 +1,21 @@
+// Package cache guarda em cache as consultas mais frequentes dos
+// repositórios, em memória ou no Redis
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Store guarda valores com prazo de validade
+type Store interface {
+	// Get devolve o valor de key; ok é false se a chave não existir ou tiver
+	// expirado
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// Counter devolve o valor do contador key, zero se ele não existir
+	Counter(ctx context.Context, key string) (int64, error)
+	// Incr incrementa o contador key, que não expira, e devolve o novo valor
+	Incr(ctx context.Context, key string) (int64, error)
+}
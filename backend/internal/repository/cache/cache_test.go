@@ -0,0 +1,308 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/metrics"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository/memory"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository/repotest"
+)
+
+// countingRepository conta as consultas que chegam ao repositório decorado e,
+// com gate, as segura até o canal ser fechado
+type countingRepository struct {
+	repository.BookRepository
+	findByID atomic.Int32
+	findAll  atomic.Int32
+	gate     chan struct{}
+}
+
+func (r *countingRepository) FindByID(ctx context.Context, id string) (*domain.Book, error) {
+	r.findByID.Add(1)
+	if r.gate != nil {
+		<-r.gate
+	}
+	return r.BookRepository.FindByID(ctx, id)
+}
+
+func (r *countingRepository) FindAll(ctx context.Context, limit, offset int) ([]*domain.Book, error) {
+	r.findAll.Add(1)
+	return r.BookRepository.FindAll(ctx, limit, offset)
+}
+
+func newRedis(t *testing.T) (*Redis, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	store := NewRedis(server.Addr(), "", 0)
+	t.Cleanup(func() { store.Close() })
+	return store, server
+}
+
+// stores são os Stores testados, cada um criado vazio por subteste
+var stores = map[string]func(t *testing.T) Store{
+	"LRU": func(t *testing.T) Store { return NewLRU(100) },
+	"Redis": func(t *testing.T) Store {
+		store, _ := newRedis(t)
+		return store
+	},
+}
+
+func TestBookRepositoryContract(t *testing.T) {
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			repotest.RunBooks(t, func(t *testing.T) repository.BookRepository {
+				return NewBookRepository(memory.NewBookRepository(), newStore(t), time.Minute)
+			})
+		})
+	}
+}
+
+func TestBookRepositoryInvalidation(t *testing.T) {
+	ctx := context.Background()
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			books := memory.NewBookRepository()
+			inner := &countingRepository{BookRepository: books}
+			repo := NewBookRepository(inner, newStore(t), time.Minute)
+
+			book := &domain.Book{ID: uuid.NewString(), Title: "Livro", Author: "Autor", Status: domain.StatusAvailable}
+			if err := repo.Create(ctx, book); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			for range 3 {
+				if _, err := repo.FindByID(ctx, book.ID); err != nil {
+					t.Fatalf("FindByID() error = %v", err)
+				}
+				if _, err := repo.FindAll(ctx, 20, 0); err != nil {
+					t.Fatalf("FindAll() error = %v", err)
+				}
+			}
+			if inner.findByID.Load() != 1 || inner.findAll.Load() != 1 {
+				t.Fatalf("repository queried %d and %d times, want the cache to answer after the first query",
+					inner.findByID.Load(), inner.findAll.Load())
+			}
+
+			// Só a primeira página fica em cache
+			repo.FindAll(ctx, 20, 20)
+			repo.FindAll(ctx, 20, 20)
+			if inner.findAll.Load() != 3 {
+				t.Errorf("FindAll() of the second page queried the repository %d times, want 3", inner.findAll.Load())
+			}
+
+			book.Title = "Outro título"
+			if err := repo.Update(ctx, book); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			got, _ := repo.FindByID(ctx, book.ID)
+			list, _ := repo.FindAll(ctx, 20, 0)
+			if got.Title != book.Title || len(list) != 1 || list[0].Title != book.Title {
+				t.Errorf("after Update() FindByID() = %q and FindAll() = %v, want the new title", got.Title, list)
+			}
+
+			// Escritas em uma transação invalidam depois do commit
+			err := memory.NewTxManager(books).WithinTx(ctx, func(ctx context.Context) error {
+				return repo.Delete(ctx, book.ID, book.Version)
+			})
+			if err != nil {
+				t.Fatalf("WithinTx() error = %v", err)
+			}
+			if _, err := repo.FindByID(ctx, book.ID); err != domain.ErrBookNotFound {
+				t.Errorf("FindByID() after Delete error = %v, want ErrBookNotFound", err)
+			}
+			if list, _ := repo.FindAll(ctx, 20, 0); len(list) != 0 {
+				t.Errorf("FindAll() after Delete = %v, want an empty list", list)
+			}
+		})
+	}
+}
+
+func TestBookRepositoryCopies(t *testing.T) {
+	ctx := context.Background()
+	repo := NewBookRepository(memory.NewBookRepository(), NewLRU(10), time.Minute)
+
+	book := &domain.Book{ID: uuid.NewString(), Title: "Livro", Author: "Autor", Subjects: domain.StringList{"Fantasia"}}
+	if err := repo.Create(ctx, book); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	first, _ := repo.FindByID(ctx, book.ID)
+	first.Title = "Alterado"
+	first.Subjects[0] = "Alterado"
+	if again, _ := repo.FindByID(ctx, book.ID); again.Title != "Livro" || again.Subjects[0] != "Fantasia" {
+		t.Errorf("FindByID() = %+v, want a copy unaffected by the previous caller", again)
+	}
+}
+
+// laggingRepository simula uma réplica atrasada: FindAll fora do primário
+// devolve sempre a lista vazia
+type laggingRepository struct {
+	repository.BookRepository
+}
+
+func (r *laggingRepository) FindAll(ctx context.Context, limit, offset int) ([]*domain.Book, error) {
+	if !repository.PrimaryOnly(ctx) {
+		return nil, nil
+	}
+	return r.BookRepository.FindAll(ctx, limit, offset)
+}
+
+func TestBookRepositoryReadsPrimary(t *testing.T) {
+	ctx := context.Background()
+	repo := NewBookRepository(&laggingRepository{BookRepository: memory.NewBookRepository()}, NewLRU(10), time.Minute)
+
+	if _, err := repo.FindAll(ctx, 20, 0); err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	book := &domain.Book{ID: uuid.NewString(), Title: "Livro", Author: "Autor"}
+	if err := repo.Create(ctx, book); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// A listagem guardada depois da escrita precisa incluir o livro novo
+	for range 2 {
+		list, err := repo.FindAll(ctx, 20, 0)
+		if err != nil {
+			t.Fatalf("FindAll() error = %v", err)
+		}
+		if len(list) != 1 || list[0].ID != book.ID {
+			t.Fatalf("FindAll() after Create() = %v, want the new book read from the primary", list)
+		}
+	}
+}
+
+func TestBookRepositorySingleflight(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingRepository{BookRepository: memory.NewBookRepository(), gate: make(chan struct{})}
+	repo := NewBookRepository(inner, NewLRU(10), time.Minute)
+
+	book := &domain.Book{ID: uuid.NewString(), Title: "Livro", Author: "Autor"}
+	if err := inner.Create(ctx, book); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.FindByID(ctx, book.ID)
+			errs <- err
+		}()
+	}
+
+	// Espera a primeira consulta chegar ao repositório antes de liberá-la
+	for inner.findByID.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(inner.gate)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("FindByID() error = %v", err)
+		}
+	}
+	if got := inner.findByID.Load(); got != 1 {
+		t.Errorf("repository queried %d times by concurrent misses, want 1", got)
+	}
+}
+
+func TestBookRepositoryMetrics(t *testing.T) {
+	ctx := context.Background()
+	repo := NewBookRepository(memory.NewBookRepository(), NewLRU(10), time.Minute)
+	book := &domain.Book{ID: uuid.NewString(), Title: "Livro", Author: "Autor"}
+	repo.Create(ctx, book)
+
+	hits := testutil.ToFloat64(metrics.BookCacheRequestsTotal.WithLabelValues(queryFindByID, metrics.CacheHit))
+	misses := testutil.ToFloat64(metrics.BookCacheRequestsTotal.WithLabelValues(queryFindByID, metrics.CacheMiss))
+
+	repo.FindByID(ctx, book.ID)
+	repo.FindByID(ctx, book.ID)
+	repo.FindByID(ctx, book.ID)
+
+	if got := testutil.ToFloat64(metrics.BookCacheRequestsTotal.WithLabelValues(queryFindByID, metrics.CacheHit)) - hits; got != 2 {
+		t.Errorf("hits = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(metrics.BookCacheRequestsTotal.WithLabelValues(queryFindByID, metrics.CacheMiss)) - misses; got != 1 {
+		t.Errorf("misses = %v, want 1", got)
+	}
+}
+
+func TestBookRepositoryRedisDown(t *testing.T) {
+	ctx := context.Background()
+	store, server := newRedis(t)
+	repo := NewBookRepository(memory.NewBookRepository(), store, time.Minute)
+
+	book := &domain.Book{ID: uuid.NewString(), Title: "Livro", Author: "Autor"}
+	if err := repo.Create(ctx, book); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	server.Close()
+
+	// Sem o Redis, as consultas vão direto ao repositório
+	if _, err := repo.FindByID(ctx, book.ID); err != nil {
+		t.Errorf("FindByID() error = %v, want the repository to answer", err)
+	}
+	if list, err := repo.FindAll(ctx, 20, 0); err != nil || len(list) != 1 {
+		t.Errorf("FindAll() = %v, %v; want the repository to answer", list, err)
+	}
+	if err := repo.Update(ctx, book); err != nil {
+		t.Errorf("Update() error = %v, want the write to succeed", err)
+	}
+}
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	lru := NewLRU(2)
+	lru.now = func() time.Time { return now }
+
+	lru.Set(ctx, "a", []byte("1"), time.Minute)
+	lru.Set(ctx, "b", []byte("2"), time.Hour)
+	// Ler a torna a usada mais recentemente: a próxima descartada é b
+	lru.Get(ctx, "a")
+	lru.Set(ctx, "c", []byte("3"), time.Hour)
+
+	if _, ok, _ := lru.Get(ctx, "b"); ok {
+		t.Error("Get(b) found the least recently used entry")
+	}
+	if value, ok, _ := lru.Get(ctx, "a"); !ok || string(value) != "1" {
+		t.Errorf("Get(a) = %q, %v; want 1", value, ok)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok, _ := lru.Get(ctx, "a"); ok {
+		t.Error("Get(a) found an expired entry")
+	}
+	if _, ok, _ := lru.Get(ctx, "c"); !ok {
+		t.Error("Get(c) did not find an entry within its ttl")
+	}
+
+	lru.Delete(ctx, "c")
+	if _, ok, _ := lru.Get(ctx, "c"); ok {
+		t.Error("Get(c) found a deleted entry")
+	}
+
+	// Contadores não são descartados pelas entradas
+	lru.Incr(ctx, "generation")
+	for _, key := range []string{"d", "e", "f"} {
+		lru.Set(ctx, key, nil, time.Hour)
+	}
+	if got, _ := lru.Counter(ctx, "generation"); got != 1 {
+		t.Errorf("Counter() = %d, want 1", got)
+	}
+}
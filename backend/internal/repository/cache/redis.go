@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Prefixo das chaves no Redis, que pode ser compartilhado com outras aplicações
+const redisPrefix = "bookflow:"
+
+// Redis é um Store no Redis, compartilhado por todas as instâncias da API
+type Redis struct {
+	client *redis.Client
+}
+
+// NewRedis cria um Redis conectado ao servidor addr (host:porta) e ao banco db
+func NewRedis(addr, password string, db int) *Redis {
+	return &Redis{
+		// Um cache fora do ar não deve atrasar as requisições: as falhas são
+		// rápidas e as consultas vão direto ao banco
+		client: redis.NewClient(&redis.Options{
+			Addr:          addr,
+			Password:      password,
+			DB:            db,
+			DialTimeout:   time.Second,
+			ReadTimeout:   500 * time.Millisecond,
+			WriteTimeout:  500 * time.Millisecond,
+			MaxRetries:    -1,
+			DialerRetries: 1,
+		}),
+	}
+}
+
+func (c *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, redisPrefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (c *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, redisPrefix+key, value, ttl).Err()
+}
+
+func (c *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = redisPrefix + key
+	}
+	return c.client.Del(ctx, prefixed...).Err()
+}
+
+func (c *Redis) Counter(ctx context.Context, key string) (int64, error) {
+	value, err := c.client.Get(ctx, redisPrefix+key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return value, err
+}
+
+func (c *Redis) Incr(ctx context.Context, key string) (int64, error) {
+	return c.client.Incr(ctx, redisPrefix+key).Result()
+}
+
+func (c *Redis) Close() error {
+	return c.client.Close()
+}
@@ -9,9 +9,29 @@ import (
 type BookRepository interface {
     FindByID(ctx context.Context, id string) (*domain.Book, error)
     FindAll(ctx context.Context, limit, offset int) ([]*domain.Book, error)
+    // Iterate percorre os livros na mesma ordem de FindAll sem carregá-los todos
+    // em memória; limit zero percorre todos
+    Iterate(ctx context.Context, limit, offset int, fn func(book *domain.Book) error) error
+    // FindByISBN devolve os livros com o ISBN informado, em qualquer formato
+    // em que tenham sido cadastrados (ISBN-10 ou ISBN-13, com ou sem hífens)
+    FindByISBN(ctx context.Context, isbn domain.ISBN) ([]*domain.Book, error)
+    // ExistingISBNs devolve quais dos ISBN-13 informados já estão cadastrados,
+    // em qualquer formato
+    ExistingISBNs(ctx context.Context, isbns []string) ([]string, error)
     Create(ctx context.Context, book *domain.Book) error
+    // CreateMany insere vários livros usando INSERTs de múltiplas linhas
+    CreateMany(ctx context.Context, books []*domain.Book) error
+    // Update grava o livro somente se book.Version ainda for a versão atual,
+    // incrementando-a; caso contrário retorna domain.ErrVersionConflict
     Update(ctx context.Context, book *domain.Book) error
-    Delete(ctx context.Context, id string) error
+    Delete(ctx context.Context, id string, version int64) error
+    // UpdateCoverVariants grava as versões redimensionadas da capa somente se
+    // a capa do livro ainda for variants.Source, incrementando a versão;
+    // caso contrário retorna domain.ErrVersionConflict
+    UpdateCoverVariants(ctx context.Context, id string, variants domain.CoverVariants) error
+    // FindStaleCovers devolve os IDs de até limit livros cujas versões da
+    // capa não correspondem à capa atual, dos atualizados há mais tempo
+    FindStaleCovers(ctx context.Context, limit int) ([]string, error)
 }
 
 type UserRepository interface {
@@ -20,5 +40,27 @@ type UserRepository interface {
     FindAll(ctx context.Context, limit, offset int) ([]*domain.User, error)
     Create(ctx context.Context, user *domain.User) error
     Update(ctx context.Context, user *domain.User) error
-    Delete(ctx context.Context, id string) error
-}
\ No newline at end of file
+    Delete(ctx context.Context, id string, version int64) error
+}
+
+type IdempotencyRepository interface {
+    // Acquire reserva a chave do registro até record.LockedUntil. Retorna nil
+    // se a reserva foi feita, inclusive sobre uma reserva vencida com o mesmo
+    // fingerprint, ou o registro existente (não expirado) com a mesma chave.
+    Acquire(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error)
+    // Complete e Release só alteram a chave se ela ainda estiver reservada até
+    // record.LockedUntil; uma reserva vencida e assumida por outra requisição
+    // não é tocada.
+    Complete(ctx context.Context, record *domain.IdempotencyRecord) error
+    Release(ctx context.Context, record *domain.IdempotencyRecord) error
+}
+// TxManager executa operações de vários repositórios em uma única transação
+type TxManager interface {
+    // WithinTx executa fn em uma transação levada pelo contexto: os
+    // repositórios chamados com o ctx recebido por fn participam dela. A
+    // transação é desfeita se fn retornar erro ou entrar em pânico e, quando o
+    // banco a aborta por conflito com outra transação, fn é executada de novo,
+    // então efeitos fora do banco devem ser registrados com AfterCommit.
+    // Chamadas aninhadas participam da transação externa.
+    WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
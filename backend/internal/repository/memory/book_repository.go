@@ -0,0 +1,286 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
+)
+
+type bookRepository struct {
+	mu    sync.RWMutex
+	books map[string]*domain.Book
+}
+
+func NewBookRepository() repository.BookRepository {
+	return &bookRepository{
+		books: make(map[string]*domain.Book),
+	}
+}
+
+func (r *bookRepository) FindByID(ctx context.Context, id string) (*domain.Book, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	book, ok := r.books[id]
+	if !ok {
+		return nil, domain.ErrBookNotFound
+	}
+
+	return cloneBook(book), nil
+}
+
+func (r *bookRepository) FindAll(ctx context.Context, limit, offset int) ([]*domain.Book, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var books []*domain.Book
+	for _, book := range page(r.sorted(), limit, offset) {
+		books = append(books, cloneBook(book))
+	}
+
+	return books, nil
+}
+
+func (r *bookRepository) Iterate(ctx context.Context, limit, offset int, fn func(book *domain.Book) error) error {
+	if limit <= 0 {
+		limit = -1
+	}
+
+	// A página é copiada antes de chamar fn, que pode usar o repositório
+	books, err := r.FindAll(ctx, limit, offset)
+	if err != nil {
+		return err
+	}
+
+	for _, book := range books {
+		if err := fn(book); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *bookRepository) FindByISBN(ctx context.Context, isbn domain.ISBN) ([]*domain.Book, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	forms := []string{isbn.String()}
+	if isbn10, ok := isbn.ISBN10(); ok {
+		forms = append(forms, isbn10)
+	}
+
+	books := []*domain.Book{}
+	for _, book := range r.sorted() {
+		if slices.Contains(forms, isbnDigits(book.ISBN)) {
+			books = append(books, cloneBook(book))
+		}
+	}
+
+	return books, nil
+}
+
+func (r *bookRepository) ExistingISBNs(ctx context.Context, isbns []string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored := make(map[string]bool, len(r.books))
+	for _, book := range r.books {
+		stored[isbnDigits(book.ISBN)] = true
+	}
+
+	existing := []string{}
+	for _, isbn := range isbns {
+		found := stored[isbn]
+		if isbn10, ok := domain.ISBN(isbn).ISBN10(); ok {
+			found = found || stored[isbn10]
+		}
+		if found && !slices.Contains(existing, isbn) {
+			existing = append(existing, isbn)
+		}
+	}
+
+	return existing, nil
+}
+
+func (r *bookRepository) Create(ctx context.Context, book *domain.Book) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.insert(book)
+}
+
+func (r *bookRepository) CreateMany(ctx context.Context, books []*domain.Book) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Como o INSERT, nenhum livro é gravado se algum falhar
+	for i, book := range books {
+		if _, ok := r.books[book.ID]; ok || slices.ContainsFunc(books[:i], func(b *domain.Book) bool { return b.ID == book.ID }) {
+			return fmt.Errorf("book %s already exists", book.ID)
+		}
+	}
+	for _, book := range books {
+		if err := r.insert(book); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *bookRepository) Update(ctx context.Context, book *domain.Book) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, err := r.current(book.ID, book.Version)
+	if err != nil {
+		return err
+	}
+
+	// As versões da capa e a data de criação não são alteradas por Update
+	updated := cloneBook(book)
+	updated.Covers = current.Covers
+	updated.CreatedAt = current.CreatedAt
+	updated.Version = current.Version + 1
+	r.books[book.ID] = updated
+
+	book.Version = updated.Version
+	return nil
+}
+
+func (r *bookRepository) Delete(ctx context.Context, id string, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.current(id, version); err != nil {
+		return err
+	}
+
+	delete(r.books, id)
+	return nil
+}
+
+func (r *bookRepository) UpdateCoverVariants(ctx context.Context, id string, variants domain.CoverVariants) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	book, ok := r.books[id]
+	if !ok {
+		return domain.ErrBookNotFound
+	}
+	if book.CoverURL != variants.Source {
+		return domain.ErrVersionConflict
+	}
+
+	book.Covers = variants
+	book.Version++
+	return nil
+}
+
+func (r *bookRepository) FindStaleCovers(ctx context.Context, limit int) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var stale []*domain.Book
+	for _, book := range r.books {
+		if book.CoverURL != book.Covers.Source {
+			stale = append(stale, book)
+		}
+	}
+	slices.SortFunc(stale, func(a, b *domain.Book) int {
+		return cmp.Or(a.UpdatedAt.Compare(b.UpdatedAt), strings.Compare(a.ID, b.ID))
+	})
+
+	ids := []string{}
+	for _, book := range page(stale, limit, 0) {
+		ids = append(ids, book.ID)
+	}
+
+	return ids, nil
+}
+
+func (r *bookRepository) snapshot() func() {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	books := make(map[string]*domain.Book, len(r.books))
+	for id, book := range r.books {
+		books[id] = cloneBook(book)
+	}
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.books = books
+	}
+}
+
+func (r *bookRepository) insert(book *domain.Book) error {
+	if _, ok := r.books[book.ID]; ok {
+		return fmt.Errorf("book %s already exists", book.ID)
+	}
+
+	if book.Version == 0 {
+		book.Version = 1
+	}
+
+	// Como o INSERT, Create não grava as versões da capa
+	stored := cloneBook(book)
+	stored.Covers = domain.CoverVariants{}
+	r.books[book.ID] = stored
+	return nil
+}
+
+// current devolve o livro se version ainda for a versão atual
+func (r *bookRepository) current(id string, version int64) (*domain.Book, error) {
+	book, ok := r.books[id]
+	if !ok {
+		return nil, domain.ErrBookNotFound
+	}
+	if book.Version != version {
+		return nil, domain.ErrVersionConflict
+	}
+	return book, nil
+}
+
+// sorted devolve os livros na ordem de FindAll: os criados mais recentemente
+// primeiro
+func (r *bookRepository) sorted() []*domain.Book {
+	books := make([]*domain.Book, 0, len(r.books))
+	for _, book := range r.books {
+		books = append(books, book)
+	}
+	slices.SortFunc(books, func(a, b *domain.Book) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), strings.Compare(a.ID, b.ID))
+	})
+	return books
+}
+
+func cloneBook(book *domain.Book) *domain.Book {
+	clone := *book
+	if book.Subjects != nil {
+		clone.Subjects = slices.Clone(book.Subjects)
+	}
+	if book.SubjectCodes != nil {
+		clone.SubjectCodes = slices.Clone(book.SubjectCodes)
+	}
+	return &clone
+}
+
+// isbnDigits normaliza o ISBN gravado como a coluna é comparada no Postgres:
+// maiúsculas, somente dígitos e X
+func isbnDigits(isbn string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' || r == 'X' {
+			return r
+		}
+		return -1
+	}, strings.ToUpper(isbn))
+}
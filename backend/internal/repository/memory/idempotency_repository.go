@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
+)
+
+type idempotencyRepository struct {
+	mu      sync.Mutex
+	records map[string]*domain.IdempotencyRecord
+}
+
+func NewIdempotencyRepository() repository.IdempotencyRepository {
+	return &idempotencyRepository{
+		records: make(map[string]*domain.IdempotencyRecord),
+	}
+}
+
+func (r *idempotencyRepository) Acquire(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.records[record.Key]
+	if ok && existing.ExpiresAt.Before(record.CreatedAt) {
+		ok = false
+	}
+	// Uma reserva vencida passa para uma nova tentativa com o mesmo corpo
+	if !ok || (!existing.Completed && existing.Fingerprint == record.Fingerprint && existing.LockedUntil.Before(record.CreatedAt)) {
+		r.records[record.Key] = cloneRecord(record)
+		return nil, nil
+	}
+
+	return cloneRecord(existing), nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.records[record.Key]
+	if !ok || !held(existing, record) {
+		return nil
+	}
+	existing.Completed = true
+	existing.StatusCode = record.StatusCode
+	existing.Headers = maps.Clone(record.Headers)
+	existing.Body = slices.Clone(record.Body)
+
+	return nil
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, record *domain.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.records[record.Key]; ok && held(existing, record) {
+		delete(r.records, record.Key)
+	}
+
+	return nil
+}
+
+// held informa se a reserva de record ainda é a reserva atual da chave
+func held(existing, record *domain.IdempotencyRecord) bool {
+	return !existing.Completed && existing.LockedUntil.Equal(record.LockedUntil)
+}
+
+func cloneRecord(record *domain.IdempotencyRecord) *domain.IdempotencyRecord {
+	clone := *record
+	clone.Headers = maps.Clone(record.Headers)
+	clone.Body = slices.Clone(record.Body)
+	return &clone
+}
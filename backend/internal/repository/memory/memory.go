@@ -0,0 +1,17 @@
+// Package memory implementa os repositórios em memória, seguros para uso
+// concorrente, para testes que não dependem de um banco de dados. Os registros
+// são copiados ao gravar e ao devolver, então alterar um registro devolvido
+// não altera o armazenado.
+package memory
+
+// page aplica limit e offset a records já ordenados; limit negativo não limita
+func page[T any](records []T, limit, offset int) []T {
+	if offset >= len(records) {
+		return nil
+	}
+	records = records[max(offset, 0):]
+	if limit >= 0 && limit < len(records) {
+		records = records[:limit]
+	}
+	return records
+}
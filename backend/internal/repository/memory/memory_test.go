@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository/repotest"
+)
+
+func TestBookRepository(t *testing.T) {
+	repotest.RunBooks(t, func(t *testing.T) repository.BookRepository {
+		return NewBookRepository()
+	})
+}
+
+func TestUserRepository(t *testing.T) {
+	repotest.RunUsers(t, func(t *testing.T) repository.UserRepository {
+		return NewUserRepository()
+	})
+}
+
+func TestIdempotencyRepository(t *testing.T) {
+	repotest.RunIdempotency(t, func(t *testing.T) repository.IdempotencyRepository {
+		return NewIdempotencyRepository()
+	})
+}
+
+func TestTxManager(t *testing.T) {
+	repotest.RunTx(t, func(t *testing.T) repotest.TxRepos {
+		books, users := NewBookRepository(), NewUserRepository()
+		return repotest.TxRepos{
+			Tx:          NewTxManager(books, users),
+			Books:       books,
+			Users:       users,
+			NoIsolation: true,
+		}
+	})
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	ctx := context.Background()
+	repo := NewBookRepository()
+	book := &domain.Book{ID: "book", Title: "Livro", Author: "Autor", Status: domain.StatusAvailable}
+	if err := repo.Create(ctx, book); err != nil {
+		t.Fatal(err)
+	}
+
+	// Todas partem da mesma versão: só uma atualização pode vencer
+	const writers = 20
+	var wg sync.WaitGroup
+	var won atomic.Int32
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			update := *book
+			update.Title = fmt.Sprintf("Livro %d", i)
+			switch err := repo.Update(ctx, &update); {
+			case err == nil:
+				won.Add(1)
+			case !errors.Is(err, domain.ErrVersionConflict):
+				t.Errorf("Update() error = %v", err)
+			}
+			repo.FindAll(ctx, 10, 0)
+		}()
+	}
+	wg.Wait()
+
+	if won.Load() != 1 {
+		t.Errorf("%d concurrent updates succeeded, want 1", won.Load())
+	}
+	if got, _ := repo.FindByID(ctx, book.ID); got.Version != 2 {
+		t.Errorf("version = %d, want 2", got.Version)
+	}
+}
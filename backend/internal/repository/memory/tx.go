@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
+)
+
+// snapshotter é implementado pelos repositórios deste pacote: snapshot copia
+// os registros e devolve a função que os restaura
+type snapshotter interface {
+	snapshot() (restore func())
+}
+
+// NewTxManager cria um repository.TxManager para repos, repositórios criados
+// por este pacote. As transações rodam uma de cada vez e, quando desfeitas,
+// restauram os registros de antes do início. Não há isolamento: as escritas
+// aparecem fora da transação antes do commit.
+func NewTxManager(repos ...interface{}) repository.TxManager {
+	var mu sync.Mutex
+	begin := func(ctx context.Context) (context.Context, repository.Tx, error) {
+		mu.Lock()
+		tx := &tx{unlock: mu.Unlock}
+		for _, repo := range repos {
+			tx.restore = append(tx.restore, repo.(snapshotter).snapshot())
+		}
+		return ctx, tx, nil
+	}
+
+	return repository.NewTxManager(begin, func(error) bool { return false })
+}
+
+type tx struct {
+	unlock  func()
+	restore []func()
+}
+
+func (t *tx) Commit() error {
+	t.unlock()
+	return nil
+}
+
+func (t *tx) Rollback() error {
+	for _, restore := range t.restore {
+		restore()
+	}
+	t.unlock()
+	return nil
+}
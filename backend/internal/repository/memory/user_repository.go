@@ -0,0 +1,165 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
+)
+
+type userRepository struct {
+	mu    sync.RWMutex
+	users map[string]*domain.User
+}
+
+func NewUserRepository() repository.UserRepository {
+	return &userRepository{
+		users: make(map[string]*domain.User),
+	}
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+
+	clone := *user
+	return &clone, nil
+}
+
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user := r.byEmail(email)
+	if user == nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	clone := *user
+	return &clone, nil
+}
+
+func (r *userRepository) FindAll(ctx context.Context, limit, offset int) ([]*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sorted := make([]*domain.User, 0, len(r.users))
+	for _, user := range r.users {
+		sorted = append(sorted, user)
+	}
+	slices.SortFunc(sorted, func(a, b *domain.User) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), strings.Compare(a.ID, b.ID))
+	})
+
+	var users []*domain.User
+	for _, user := range page(sorted, limit, offset) {
+		// Como no Postgres, a listagem não inclui a senha
+		clone := *user
+		clone.Password = ""
+		users = append(users, &clone)
+	}
+
+	return users, nil
+}
+
+func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.ID]; ok {
+		return fmt.Errorf("user %s already exists", user.ID)
+	}
+	if r.byEmail(user.Email) != nil {
+		return domain.ErrEmailTaken
+	}
+
+	if user.Version == 0 {
+		user.Version = 1
+	}
+
+	clone := *user
+	r.users[user.ID] = &clone
+	return nil
+}
+
+func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, err := r.current(user.ID, user.Version)
+	if err != nil {
+		return err
+	}
+	if other := r.byEmail(user.Email); other != nil && other.ID != user.ID {
+		return domain.ErrEmailTaken
+	}
+
+	// A data de criação não é alterada por Update
+	updated := *user
+	updated.CreatedAt = current.CreatedAt
+	updated.Version = current.Version + 1
+	r.users[user.ID] = &updated
+
+	user.Version = updated.Version
+	return nil
+}
+
+func (r *userRepository) Delete(ctx context.Context, id string, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.current(id, version); err != nil {
+		return err
+	}
+
+	delete(r.users, id)
+	return nil
+}
+
+func (r *userRepository) snapshot() func() {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make(map[string]*domain.User, len(r.users))
+	for id, user := range r.users {
+		clone := *user
+		users[id] = &clone
+	}
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.users = users
+	}
+}
+
+func (r *userRepository) byEmail(email string) *domain.User {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user
+		}
+	}
+	return nil
+}
+
+// current devolve o usuário se version ainda for a versão atual
+func (r *userRepository) current(id string, version int64) (*domain.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	if user.Version != version {
+		return nil, domain.ErrVersionConflict
+	}
+	return user, nil
+}
@@ -4,29 +4,40 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 
 	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
 	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
 )
 
+// Quantidade máxima de livros por INSERT em CreateMany; com 14 colunas fica
+// bem abaixo do limite de 65535 parâmetros do Postgres
+const createManyChunkSize = 500
+
 type bookRepository struct {
-	db *sqlx.DB
+	db       dbtx
+	replicas *replicaSet
 }
 
-func NewBookRepository(db *sqlx.DB) repository.BookRepository {
+// NewBookRepository cria o repositório sobre o primário db. FindAll, Iterate
+// e FindByISBN usam as réplicas de leitura, quando houver.
+func NewBookRepository(db *sqlx.DB, replicas ...*sqlx.DB) repository.BookRepository {
 	return &bookRepository{
-		db: db,
+		db:       db,
+		replicas: newReplicaSet(replicas),
 	}
 }
 
 func (r *bookRepository) FindByID(ctx context.Context, id string) (*domain.Book, error) {
-	const query = `SELECT id, title, author, isbn, description, cover_url, status, 
-                  created_at, updated_at FROM books WHERE id = $1`
+	const query = `SELECT id, title, author, isbn, description, cover_url, cover_variants, publisher, 
+                  publication_year, subjects, subject_codes, status, version, created_at, updated_at FROM books WHERE id = $1`
 
 	var book domain.Book
-	err := r.db.GetContext(ctx, &book, query, id)
+	err := conn(ctx, r.db).GetContext(ctx, &book, query, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrBookNotFound
@@ -38,11 +49,11 @@ func (r *bookRepository) FindByID(ctx context.Context, id string) (*domain.Book,
 }
 
 func (r *bookRepository) FindAll(ctx context.Context, limit, offset int) ([]*domain.Book, error) {
-	const query = `SELECT id, title, author, isbn, description, cover_url, status, 
-                  created_at, updated_at FROM books ORDER BY created_at DESC LIMIT $1 OFFSET $2`
+	const query = `SELECT id, title, author, isbn, description, cover_url, cover_variants, publisher, 
+                  publication_year, subjects, subject_codes, status, version, created_at, updated_at FROM books ORDER BY created_at DESC LIMIT $1 OFFSET $2`
 
 	var books []*domain.Book
-	err := r.db.SelectContext(ctx, &books, query, limit, offset)
+	err := r.replicas.reader(ctx, r.db).SelectContext(ctx, &books, query, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -50,22 +61,170 @@ func (r *bookRepository) FindAll(ctx context.Context, limit, offset int) ([]*dom
 	return books, nil
 }
 
+func (r *bookRepository) Iterate(ctx context.Context, limit, offset int, fn func(book *domain.Book) error) error {
+	const query = `SELECT id, title, author, isbn, description, cover_url, cover_variants, publisher, 
+                  publication_year, subjects, subject_codes, status, version, created_at, updated_at FROM books ORDER BY created_at DESC LIMIT $1 OFFSET $2`
+
+	// LIMIT NULL equivale a sem limite
+	var limitArg interface{}
+	if limit > 0 {
+		limitArg = limit
+	}
+
+	rows, err := r.replicas.reader(ctx, r.db).QueryxContext(ctx, query, limitArg, offset)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var book domain.Book
+		if err := rows.StructScan(&book); err != nil {
+			return err
+		}
+		if err := fn(&book); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// isbnDigits normaliza a coluna isbn para comparação: registros antigos podem
+// ter hífens, espaços ou estar no formato ISBN-10
+const isbnDigits = `regexp_replace(upper(isbn), '[^0-9X]', '', 'g')`
+
+func (r *bookRepository) FindByISBN(ctx context.Context, isbn domain.ISBN) ([]*domain.Book, error) {
+	const query = `SELECT id, title, author, isbn, description, cover_url, cover_variants, publisher, 
+                  publication_year, subjects, subject_codes, status, version, created_at, updated_at FROM books 
+                  WHERE ` + isbnDigits + ` = ANY($1) ORDER BY created_at DESC`
+
+	forms := []string{isbn.String()}
+	if isbn10, ok := isbn.ISBN10(); ok {
+		forms = append(forms, isbn10)
+	}
+
+	books := []*domain.Book{}
+	err := r.replicas.reader(ctx, r.db).SelectContext(ctx, &books, query, pq.Array(forms))
+	if err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+func (r *bookRepository) ExistingISBNs(ctx context.Context, isbns []string) ([]string, error) {
+	const query = `SELECT DISTINCT i.isbn13 FROM unnest($1::text[], $2::text[]) AS i(isbn13, isbn10) 
+                  JOIN books ON ` + isbnDigits + ` IN (i.isbn13, i.isbn10)`
+
+	existing := []string{}
+	if len(isbns) == 0 {
+		return existing, nil
+	}
+
+	// Sem forma ISBN-10 (prefixo 979) a comparação repete o ISBN-13
+	isbn10s := make([]string, len(isbns))
+	for i, isbn := range isbns {
+		isbn10, ok := domain.ISBN(isbn).ISBN10()
+		if !ok {
+			isbn10 = isbn
+		}
+		isbn10s[i] = isbn10
+	}
+
+	err := conn(ctx, r.db).SelectContext(ctx, &existing, query, pq.Array(isbns), pq.Array(isbn10s))
+	if err != nil {
+		return nil, err
+	}
+
+	return existing, nil
+}
+
 func (r *bookRepository) Create(ctx context.Context, book *domain.Book) error {
-	const query = `INSERT INTO books (id, title, author, isbn, description, cover_url, status, 
-                   created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	const query = `INSERT INTO books (id, title, author, isbn, description, cover_url, publisher, 
+                   publication_year, subjects, subject_codes, status, version, created_at, updated_at) 
+                   VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+
+	if book.Version == 0 {
+		book.Version = 1
+	}
 
-	_, err := r.db.ExecContext(ctx, query, book.ID, book.Title, book.Author, book.ISBN,
-		book.Description, book.CoverURL, book.Status, book.CreatedAt, book.UpdatedAt)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, book.ID, book.Title, book.Author, book.ISBN,
+		book.Description, book.CoverURL, book.Publisher, book.PublicationYear, book.Subjects,
+		book.SubjectCodes, book.Status, book.Version, book.CreatedAt, book.UpdatedAt)
 
 	return err
 }
 
+func (r *bookRepository) CreateMany(ctx context.Context, books []*domain.Book) error {
+	const prefix = `INSERT INTO books (id, title, author, isbn, description, cover_url, publisher, 
+                    publication_year, subjects, subject_codes, status, version, created_at, updated_at) VALUES `
+	const columns = 14
+
+	for start := 0; start < len(books); start += createManyChunkSize {
+		end := start + createManyChunkSize
+		if end > len(books) {
+			end = len(books)
+		}
+		chunk := books[start:end]
+
+		var query strings.Builder
+		query.WriteString(prefix)
+		args := make([]interface{}, 0, len(chunk)*columns)
+		for i, book := range chunk {
+			if book.Version == 0 {
+				book.Version = 1
+			}
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			n := i * columns
+			query.WriteString("(")
+			for col := 1; col <= columns; col++ {
+				if col > 1 {
+					query.WriteString(", ")
+				}
+				fmt.Fprintf(&query, "$%d", n+col)
+			}
+			query.WriteString(")")
+			args = append(args, book.ID, book.Title, book.Author, book.ISBN, book.Description,
+				book.CoverURL, book.Publisher, book.PublicationYear, book.Subjects, book.SubjectCodes,
+				book.Status, book.Version, book.CreatedAt, book.UpdatedAt)
+		}
+
+		if _, err := conn(ctx, r.db).ExecContext(ctx, query.String(), args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (r *bookRepository) Update(ctx context.Context, book *domain.Book) error {
 	const query = `UPDATE books SET title = $1, author = $2, isbn = $3, description = $4, 
-                  cover_url = $5, status = $6, updated_at = $7 WHERE id = $8`
+                  cover_url = $5, publisher = $6, publication_year = $7, subjects = $8, 
+                  subject_codes = $9, status = $10, updated_at = $11, version = version + 1 
+                  WHERE id = $12 AND version = $13 RETURNING version`
 
-	result, err := r.db.ExecContext(ctx, query, book.Title, book.Author, book.ISBN,
-		book.Description, book.CoverURL, book.Status, book.UpdatedAt, book.ID)
+	var version int64
+	err := conn(ctx, r.db).GetContext(ctx, &version, query, book.Title, book.Author, book.ISBN,
+		book.Description, book.CoverURL, book.Publisher, book.PublicationYear, book.Subjects,
+		book.SubjectCodes, book.Status, book.UpdatedAt, book.ID, book.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return r.missingOrConflict(ctx, book.ID)
+		}
+		return err
+	}
+
+	book.Version = version
+	return nil
+}
+
+func (r *bookRepository) Delete(ctx context.Context, id string, version int64) error {
+	const query = `DELETE FROM books WHERE id = $1 AND version = $2`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, version)
 	if err != nil {
 		return err
 	}
@@ -76,16 +235,17 @@ func (r *bookRepository) Update(ctx context.Context, book *domain.Book) error {
 	}
 
 	if rowsAffected == 0 {
-		return domain.ErrBookNotFound
+		return r.missingOrConflict(ctx, id)
 	}
 
 	return nil
 }
 
-func (r *bookRepository) Delete(ctx context.Context, id string) error {
-	const query = `DELETE FROM books WHERE id = $1`
+func (r *bookRepository) UpdateCoverVariants(ctx context.Context, id string, variants domain.CoverVariants) error {
+	const query = `UPDATE books SET cover_variants = $1, version = version + 1 
+                  WHERE id = $2 AND COALESCE(cover_url, '') = $3`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, variants, id, variants.Source)
 	if err != nil {
 		return err
 	}
@@ -96,8 +256,39 @@ func (r *bookRepository) Delete(ctx context.Context, id string) error {
 	}
 
 	if rowsAffected == 0 {
-		return domain.ErrBookNotFound
+		return r.missingOrConflict(ctx, id)
 	}
 
 	return nil
 }
+
+func (r *bookRepository) FindStaleCovers(ctx context.Context, limit int) ([]string, error) {
+	const query = `SELECT id FROM books 
+                  WHERE COALESCE(cover_url, '') <> COALESCE(cover_variants::jsonb ->> 'source', '') 
+                  ORDER BY updated_at LIMIT $1`
+
+	ids := []string{}
+	err := conn(ctx, r.db).SelectContext(ctx, &ids, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// missingOrConflict distingue, após uma escrita condicional sem efeito, se o
+// livro não existe ou se a versão informada está desatualizada
+func (r *bookRepository) missingOrConflict(ctx context.Context, id string) error {
+	const query = `SELECT EXISTS (SELECT 1 FROM books WHERE id = $1)`
+
+	var exists bool
+	if err := conn(ctx, r.db).GetContext(ctx, &exists, query, id); err != nil {
+		return err
+	}
+
+	if !exists {
+		return domain.ErrBookNotFound
+	}
+
+	return domain.ErrVersionConflict
+}
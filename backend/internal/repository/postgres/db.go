@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
+)
+
+// dbtx é o subconjunto comum de *sqlx.DB e *sqlx.Tx usado pelos repositórios
+type dbtx interface {
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error)
+}
+
+// replicaSet distribui as consultas de listagem e busca entre as réplicas de
+// leitura, em rodízio. As réplicas podem estar um pouco atrás do primário,
+// então leituras que precisam ver a última escrita, como FindByID antes de
+// um Update, continuam no primário.
+type replicaSet struct {
+	dbs  []*sqlx.DB
+	next atomic.Uint64
+}
+
+func newReplicaSet(dbs []*sqlx.DB) *replicaSet {
+	return &replicaSet{dbs: dbs}
+}
+
+// reader devolve a conexão de uma consulta de leitura: dentro de uma
+// transação, a própria transação, que precisa ver as suas escritas; com
+// repository.WithPrimary, db; fora delas, a próxima réplica ou, sem réplicas,
+// db
+func (s *replicaSet) reader(ctx context.Context, db dbtx) dbtx {
+	current := conn(ctx, db)
+	if _, ok := current.(*sqlx.Tx); ok || s == nil || len(s.dbs) == 0 || repository.PrimaryOnly(ctx) {
+		return current
+	}
+	return s.dbs[(s.next.Add(1)-1)%uint64(len(s.dbs))]
+}
@@ -0,0 +1,38 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
+)
+
+func TestReplicaReader(t *testing.T) {
+	ctx := context.Background()
+	primary := sqlx.NewDb(nil, "postgres")
+	replicas := []*sqlx.DB{sqlx.NewDb(nil, "postgres"), sqlx.NewDb(nil, "postgres")}
+
+	var none *replicaSet
+	if got := none.reader(ctx, primary); got != primary {
+		t.Errorf("reader() without replicas = %p, want the primary", got)
+	}
+
+	set := newReplicaSet(replicas)
+	for i, want := range []*sqlx.DB{replicas[0], replicas[1], replicas[0]} {
+		if got := set.reader(ctx, primary); got != want {
+			t.Errorf("reader() call %d = %p, want replica %p", i, got, want)
+		}
+	}
+
+	if got := set.reader(repository.WithPrimary(ctx), primary); got != primary {
+		t.Errorf("reader() with WithPrimary = %p, want the primary", got)
+	}
+
+	// Dentro de uma transação as leituras ficam nela
+	tx := &sqlx.Tx{}
+	if got := set.reader(context.WithValue(ctx, txKey{}, tx), primary); got != tx {
+		t.Errorf("reader() inside a TxManager transaction = %p, want the transaction", got)
+	}
+}
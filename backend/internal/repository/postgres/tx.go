@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
+)
+
+// txKey guarda no contexto a transação aberta pelo TxManager
+type txKey struct{}
+
+// conn devolve a conexão de uma operação: a transação aberta pelo TxManager
+// em ctx ou, fora dela, db
+func conn(ctx context.Context, db dbtx) dbtx {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// NewTxManager cria um repository.TxManager cujas transações são
+// serializáveis: o Postgres aborta, em vez de aplicar, escritas baseadas em
+// leituras que outra transação alterou, e a transação é repetida
+func NewTxManager(db *sqlx.DB) repository.TxManager {
+	opts := &sql.TxOptions{Isolation: sql.LevelSerializable}
+	return repository.NewTxManager(func(ctx context.Context) (context.Context, repository.Tx, error) {
+		tx, err := db.BeginTxx(ctx, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return context.WithValue(ctx, txKey{}, tx), tx, nil
+	}, retryable)
+}
+
+// retryable informa se err é uma falha de serialização ou um deadlock, após
+// os quais a transação pode ser repetida
+func retryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	switch pqErr.Code.Name() {
+	case "serialization_failure", "deadlock_detected":
+		return true
+	}
+	return false
+}
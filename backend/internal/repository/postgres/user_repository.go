@@ -6,26 +6,34 @@ import (
 	"errors"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 
 	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
 	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
 )
 
+// Restrição de unicidade do email, criada em 001_create_users
+const emailConstraint = "users_email_key"
+
 type userRepository struct {
-	db *sqlx.DB
+	db       *sqlx.DB
+	replicas *replicaSet
 }
 
-func NewUserRepository(db *sqlx.DB) repository.UserRepository {
+// NewUserRepository cria o repositório sobre o primário db. FindAll usa as
+// réplicas de leitura, quando houver.
+func NewUserRepository(db *sqlx.DB, replicas ...*sqlx.DB) repository.UserRepository {
 	return &userRepository{
-		db: db,
+		db:       db,
+		replicas: newReplicaSet(replicas),
 	}
 }
 
 func (r *userRepository) FindByID(ctx context.Context, id string) (*domain.User, error) {
-	const query = `SELECT id, name, email, password, created_at, updated_at FROM users WHERE id = $1`
+	const query = `SELECT id, name, email, password, version, disabled, created_at, updated_at FROM users WHERE id = $1`
 
 	var user domain.User
-	err := r.db.GetContext(ctx, &user, query, id)
+	err := conn(ctx, r.db).GetContext(ctx, &user, query, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrUserNotFound
@@ -37,10 +45,10 @@ func (r *userRepository) FindByID(ctx context.Context, id string) (*domain.User,
 }
 
 func (r *userRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
-	const query = `SELECT id, name, email, password, created_at, updated_at FROM users WHERE email = $1`
+	const query = `SELECT id, name, email, password, version, disabled, created_at, updated_at FROM users WHERE email = $1`
 
 	var user domain.User
-	err := r.db.GetContext(ctx, &user, query, email)
+	err := conn(ctx, r.db).GetContext(ctx, &user, query, email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrUserNotFound
@@ -52,10 +60,10 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (*domain
 }
 
 func (r *userRepository) FindAll(ctx context.Context, limit, offset int) ([]*domain.User, error) {
-	const query = `SELECT id, name, email, created_at, updated_at FROM users ORDER BY created_at DESC LIMIT $1 OFFSET $2`
+	const query = `SELECT id, name, email, version, disabled, created_at, updated_at FROM users ORDER BY created_at DESC LIMIT $1 OFFSET $2`
 
 	var users []*domain.User
-	err := r.db.SelectContext(ctx, &users, query, limit, offset)
+	err := r.replicas.reader(ctx, r.db).SelectContext(ctx, &users, query, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -64,21 +72,42 @@ func (r *userRepository) FindAll(ctx context.Context, limit, offset int) ([]*dom
 }
 
 func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
-	const query = `INSERT INTO users (id, name, email, password, created_at, updated_at) 
-                  VALUES ($1, $2, $3, $4, $5, $6)`
+	const query = `INSERT INTO users (id, name, email, password, version, disabled, created_at, updated_at) 
+                  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	if user.Version == 0 {
+		user.Version = 1
+	}
 
-	_, err := r.db.ExecContext(ctx, query, user.ID, user.Name, user.Email,
-		user.Password, user.CreatedAt, user.UpdatedAt)
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, user.ID, user.Name, user.Email,
+		user.Password, user.Version, user.Disabled, user.CreatedAt, user.UpdatedAt)
 
-	return err
+	return emailTaken(err)
 }
 
 func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
-	const query = `UPDATE users SET name = $1, email = $2, password = $3, 
-                  updated_at = $4 WHERE id = $5`
+	const query = `UPDATE users SET name = $1, email = $2, password = $3, disabled = $4, 
+                  updated_at = $5, version = version + 1 
+                  WHERE id = $6 AND version = $7 RETURNING version`
+
+	var version int64
+	err := conn(ctx, r.db).GetContext(ctx, &version, query, user.Name, user.Email,
+		user.Password, user.Disabled, user.UpdatedAt, user.ID, user.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return r.missingOrConflict(ctx, user.ID)
+		}
+		return emailTaken(err)
+	}
+
+	user.Version = version
+	return nil
+}
+
+func (r *userRepository) Delete(ctx context.Context, id string, version int64) error {
+	const query = `DELETE FROM users WHERE id = $1 AND version = $2`
 
-	result, err := r.db.ExecContext(ctx, query, user.Name, user.Email,
-		user.Password, user.UpdatedAt, user.ID)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, version)
 	if err != nil {
 		return err
 	}
@@ -89,28 +118,34 @@ func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
 	}
 
 	if rowsAffected == 0 {
-		return domain.ErrUserNotFound
+		return r.missingOrConflict(ctx, id)
 	}
 
 	return nil
 }
 
-func (r *userRepository) Delete(ctx context.Context, id string) error {
-	const query = `DELETE FROM users WHERE id = $1`
+// missingOrConflict distingue, após uma escrita condicional sem efeito, se o
+// usuário não existe ou se a versão informada está desatualizada
+func (r *userRepository) missingOrConflict(ctx context.Context, id string) error {
+	const query = `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`
 
-	result, err := r.db.ExecContext(ctx, query, id)
-	if err != nil {
-		return err
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
+	var exists bool
+	if err := conn(ctx, r.db).GetContext(ctx, &exists, query, id); err != nil {
 		return err
 	}
 
-	if rowsAffected == 0 {
+	if !exists {
 		return domain.ErrUserNotFound
 	}
 
-	return nil
+	return domain.ErrVersionConflict
+}
+
+// emailTaken traduz a violação da unicidade do email para domain.ErrEmailTaken
+func emailTaken(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code.Name() == "unique_violation" && pqErr.Constraint == emailConstraint {
+		return domain.ErrEmailTaken
+	}
+	return err
 }
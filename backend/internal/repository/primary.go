@@ -0,0 +1,18 @@
+package repository
+
+import "context"
+
+type primaryKey struct{}
+
+// WithPrimary marca as leituras feitas com ctx para irem ao primário, mesmo
+// as que normalmente usam as réplicas de leitura, quando o resultado precisa
+// refletir as últimas escritas
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// PrimaryOnly informa se as leituras de ctx devem ir ao primário
+func PrimaryOnly(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryKey{}).(bool)
+	return primary
+}
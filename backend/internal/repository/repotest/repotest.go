@@ -0,0 +1,758 @@
+// Package repotest verifica que as implementações de repository.BookRepository,
+// repository.UserRepository, repository.IdempotencyRepository e
+// repository.TxManager seguem o contrato das interfaces
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
+)
+
+// Instante dos registros criados; sem frações abaixo do microssegundo, que o
+// Postgres descartaria
+var base = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// RunBooks executa os testes do contrato de BookRepository. newRepo é chamada
+// por subteste e deve devolver um repositório vazio.
+func RunBooks(t *testing.T, newRepo func(t *testing.T) repository.BookRepository) {
+	ctx := context.Background()
+
+	t.Run("NotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		id := uuid.NewString()
+
+		if _, err := repo.FindByID(ctx, id); !errors.Is(err, domain.ErrBookNotFound) {
+			t.Errorf("FindByID() error = %v, want ErrBookNotFound", err)
+		}
+		if err := repo.Update(ctx, newBook(0)); !errors.Is(err, domain.ErrBookNotFound) {
+			t.Errorf("Update() error = %v, want ErrBookNotFound", err)
+		}
+		if err := repo.Delete(ctx, id, 1); !errors.Is(err, domain.ErrBookNotFound) {
+			t.Errorf("Delete() error = %v, want ErrBookNotFound", err)
+		}
+		if err := repo.UpdateCoverVariants(ctx, id, domain.CoverVariants{}); !errors.Is(err, domain.ErrBookNotFound) {
+			t.Errorf("UpdateCoverVariants() error = %v, want ErrBookNotFound", err)
+		}
+	})
+
+	t.Run("CreateFind", func(t *testing.T) {
+		repo := newRepo(t)
+		book := newBook(0)
+		if err := repo.Create(ctx, book); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if book.Version != 1 {
+			t.Errorf("Create() version = %d, want 1", book.Version)
+		}
+
+		got, err := repo.FindByID(ctx, book.ID)
+		if err != nil {
+			t.Fatalf("FindByID() error = %v", err)
+		}
+		assertBook(t, got, book)
+
+		// Alterar o livro devolvido não altera o gravado
+		got.Title = "Alterado"
+		got.Subjects[0] = "Alterado"
+		if again, _ := repo.FindByID(ctx, book.ID); again.Title != book.Title || again.Subjects[0] != book.Subjects[0] {
+			t.Errorf("FindByID() returned a book that shares memory with the stored one")
+		}
+	})
+
+	t.Run("OrderAndPagination", func(t *testing.T) {
+		repo := newRepo(t)
+		books := make([]*domain.Book, 5)
+		for i := range books {
+			books[i] = newBook(i)
+		}
+		// Inseridos fora de ordem: a ordem vem de created_at, não da inserção
+		if err := repo.CreateMany(ctx, []*domain.Book{books[2], books[0], books[4]}); err != nil {
+			t.Fatalf("CreateMany() error = %v", err)
+		}
+		for _, book := range []*domain.Book{books[1], books[3]} {
+			if err := repo.Create(ctx, book); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+		}
+
+		pages := []struct {
+			limit, offset int
+			want          []int
+		}{
+			{10, 0, []int{4, 3, 2, 1, 0}},
+			{2, 0, []int{4, 3}},
+			{2, 2, []int{2, 1}},
+			{2, 4, []int{0}},
+			{2, 5, nil},
+		}
+		for _, p := range pages {
+			got, err := repo.FindAll(ctx, p.limit, p.offset)
+			if err != nil {
+				t.Fatalf("FindAll(%d, %d) error = %v", p.limit, p.offset, err)
+			}
+			assertOrder(t, fmt.Sprintf("FindAll(%d, %d)", p.limit, p.offset), got, books, p.want)
+
+			var iterated []*domain.Book
+			err = repo.Iterate(ctx, p.limit, p.offset, func(book *domain.Book) error {
+				iterated = append(iterated, book)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Iterate(%d, %d) error = %v", p.limit, p.offset, err)
+			}
+			assertOrder(t, fmt.Sprintf("Iterate(%d, %d)", p.limit, p.offset), iterated, books, p.want)
+		}
+
+		var all []*domain.Book
+		if err := repo.Iterate(ctx, 0, 1, func(book *domain.Book) error {
+			all = append(all, book)
+			return nil
+		}); err != nil {
+			t.Fatalf("Iterate(0, 1) error = %v", err)
+		}
+		assertOrder(t, "Iterate(0, 1)", all, books, []int{3, 2, 1, 0})
+
+		stop := errors.New("stop")
+		calls := 0
+		err := repo.Iterate(ctx, 0, 0, func(book *domain.Book) error {
+			calls++
+			return stop
+		})
+		if !errors.Is(err, stop) || calls != 1 {
+			t.Errorf("Iterate() with a failing callback = %v after %d calls, want stop after 1", err, calls)
+		}
+	})
+
+	t.Run("Versioning", func(t *testing.T) {
+		repo := newRepo(t)
+		book := newBook(0)
+		if err := repo.Create(ctx, book); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+
+		stale := *book
+		book.Title = "Segunda edição"
+		book.UpdatedAt = base.Add(time.Hour)
+		if err := repo.Update(ctx, book); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if book.Version != 2 {
+			t.Errorf("Update() version = %d, want 2", book.Version)
+		}
+		got, err := repo.FindByID(ctx, book.ID)
+		if err != nil {
+			t.Fatalf("FindByID() error = %v", err)
+		}
+		assertBook(t, got, book)
+
+		stale.Title = "Desatualizado"
+		if err := repo.Update(ctx, &stale); !errors.Is(err, domain.ErrVersionConflict) {
+			t.Errorf("Update() with a stale version error = %v, want ErrVersionConflict", err)
+		}
+		if err := repo.Delete(ctx, book.ID, 1); !errors.Is(err, domain.ErrVersionConflict) {
+			t.Errorf("Delete() with a stale version error = %v, want ErrVersionConflict", err)
+		}
+		if err := repo.Delete(ctx, book.ID, book.Version); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := repo.FindByID(ctx, book.ID); !errors.Is(err, domain.ErrBookNotFound) {
+			t.Errorf("FindByID() after Delete error = %v, want ErrBookNotFound", err)
+		}
+	})
+
+	t.Run("ISBN", func(t *testing.T) {
+		repo := newRepo(t)
+		// Cadastros antigos: ISBN-10 com hífens e ISBN-13 com espaços
+		older, newer, other := newBook(0), newBook(1), newBook(2)
+		older.ISBN = "85-336-1512-4"
+		newer.ISBN = "978 85 336 1512 0"
+		other.ISBN = "9780261103573"
+		for _, book := range []*domain.Book{older, newer, other} {
+			if err := repo.Create(ctx, book); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+		}
+
+		isbn, err := domain.ParseISBN("978-85-336-1512-0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := repo.FindByISBN(ctx, isbn)
+		if err != nil {
+			t.Fatalf("FindByISBN() error = %v", err)
+		}
+		assertOrder(t, "FindByISBN()", got, []*domain.Book{older, newer}, []int{1, 0})
+
+		existing, err := repo.ExistingISBNs(ctx, []string{"9788533615120", "9780000000002", "9780261103573"})
+		if err != nil {
+			t.Fatalf("ExistingISBNs() error = %v", err)
+		}
+		if len(existing) != 2 || !slices.Contains(existing, "9788533615120") || !slices.Contains(existing, "9780261103573") {
+			t.Errorf("ExistingISBNs() = %v, want 9788533615120 and 9780261103573", existing)
+		}
+		if existing, err := repo.ExistingISBNs(ctx, nil); err != nil || len(existing) != 0 {
+			t.Errorf("ExistingISBNs(nil) = %v, %v", existing, err)
+		}
+	})
+
+	t.Run("CoverVariants", func(t *testing.T) {
+		repo := newRepo(t)
+		fresh, stale, noCover := newBook(0), newBook(1), newBook(2)
+		fresh.CoverURL = "http://localhost:8080/api/covers/fresh.jpg"
+		fresh.UpdatedAt = base.Add(2 * time.Hour)
+		stale.CoverURL = "http://localhost:8080/api/covers/stale.jpg"
+		stale.UpdatedAt = base.Add(time.Hour)
+		noCover.CoverURL = ""
+		for _, book := range []*domain.Book{fresh, stale, noCover} {
+			if err := repo.Create(ctx, book); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+		}
+
+		ids, err := repo.FindStaleCovers(ctx, 10)
+		if err != nil {
+			t.Fatalf("FindStaleCovers() error = %v", err)
+		}
+		if len(ids) != 2 || ids[0] != stale.ID || ids[1] != fresh.ID {
+			t.Errorf("FindStaleCovers() = %v, want [%s %s] (least recently updated first)", ids, stale.ID, fresh.ID)
+		}
+		if ids, _ := repo.FindStaleCovers(ctx, 1); len(ids) != 1 {
+			t.Errorf("FindStaleCovers(1) = %v, want one ID", ids)
+		}
+
+		variants := domain.CoverVariants{Source: "http://localhost:8080/api/covers/old.jpg", Thumbnail: "thumbnail.jpg"}
+		if err := repo.UpdateCoverVariants(ctx, fresh.ID, variants); !errors.Is(err, domain.ErrVersionConflict) {
+			t.Errorf("UpdateCoverVariants() for another cover error = %v, want ErrVersionConflict", err)
+		}
+
+		variants.Source = fresh.CoverURL
+		if err := repo.UpdateCoverVariants(ctx, fresh.ID, variants); err != nil {
+			t.Fatalf("UpdateCoverVariants() error = %v", err)
+		}
+		got, err := repo.FindByID(ctx, fresh.ID)
+		if err != nil {
+			t.Fatalf("FindByID() error = %v", err)
+		}
+		if got.Version != 2 || !got.Covers.Ready(fresh.CoverURL) || got.Covers.Thumbnail != "thumbnail.jpg" {
+			t.Errorf("FindByID() after UpdateCoverVariants = version %d, covers %+v", got.Version, got.Covers)
+		}
+
+		// Update não apaga as versões da capa
+		got.Title = "Com capa"
+		if err := repo.Update(ctx, got); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if again, _ := repo.FindByID(ctx, fresh.ID); !again.Covers.Ready(fresh.CoverURL) {
+			t.Errorf("Update() dropped the cover variants: %+v", again.Covers)
+		}
+
+		if ids, _ := repo.FindStaleCovers(ctx, 10); len(ids) != 1 || ids[0] != stale.ID {
+			t.Errorf("FindStaleCovers() after UpdateCoverVariants = %v, want [%s]", ids, stale.ID)
+		}
+	})
+}
+
+// RunUsers executa os testes do contrato de UserRepository. newRepo é chamada
+// por subteste e deve devolver um repositório vazio.
+func RunUsers(t *testing.T, newRepo func(t *testing.T) repository.UserRepository) {
+	ctx := context.Background()
+
+	t.Run("NotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		id := uuid.NewString()
+
+		if _, err := repo.FindByID(ctx, id); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("FindByID() error = %v, want ErrUserNotFound", err)
+		}
+		if _, err := repo.FindByEmail(ctx, "missing@example.com"); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("FindByEmail() error = %v, want ErrUserNotFound", err)
+		}
+		if err := repo.Update(ctx, newUser(0)); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Update() error = %v, want ErrUserNotFound", err)
+		}
+		if err := repo.Delete(ctx, id, 1); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Delete() error = %v, want ErrUserNotFound", err)
+		}
+	})
+
+	t.Run("CreateFind", func(t *testing.T) {
+		repo := newRepo(t)
+		user := newUser(0)
+		user.Disabled = true
+		if err := repo.Create(ctx, user); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if user.Version != 1 {
+			t.Errorf("Create() version = %d, want 1", user.Version)
+		}
+
+		byID, err := repo.FindByID(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("FindByID() error = %v", err)
+		}
+		assertUser(t, byID, user)
+
+		byEmail, err := repo.FindByEmail(ctx, user.Email)
+		if err != nil {
+			t.Fatalf("FindByEmail() error = %v", err)
+		}
+		assertUser(t, byEmail, user)
+	})
+
+	t.Run("OrderAndPagination", func(t *testing.T) {
+		repo := newRepo(t)
+		users := make([]*domain.User, 4)
+		for _, i := range []int{2, 0, 3, 1} {
+			users[i] = newUser(i)
+			if err := repo.Create(ctx, users[i]); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+		}
+
+		pages := []struct {
+			limit, offset int
+			want          []int
+		}{
+			{10, 0, []int{3, 2, 1, 0}},
+			{3, 0, []int{3, 2, 1}},
+			{3, 3, []int{0}},
+			{3, 4, nil},
+		}
+		for _, p := range pages {
+			got, err := repo.FindAll(ctx, p.limit, p.offset)
+			if err != nil {
+				t.Fatalf("FindAll(%d, %d) error = %v", p.limit, p.offset, err)
+			}
+			if len(got) != len(p.want) {
+				t.Errorf("FindAll(%d, %d) returned %d users, want %d", p.limit, p.offset, len(got), len(p.want))
+				continue
+			}
+			for i, want := range p.want {
+				if got[i].ID != users[want].ID {
+					t.Errorf("FindAll(%d, %d)[%d] = %s, want user %d", p.limit, p.offset, i, got[i].Email, want)
+				}
+				if got[i].Password != "" {
+					t.Errorf("FindAll(%d, %d)[%d] includes the password", p.limit, p.offset, i)
+				}
+			}
+		}
+	})
+
+	t.Run("DuplicateEmail", func(t *testing.T) {
+		repo := newRepo(t)
+		first, second := newUser(0), newUser(1)
+		for _, user := range []*domain.User{first, second} {
+			if err := repo.Create(ctx, user); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+		}
+
+		duplicate := newUser(2)
+		duplicate.Email = first.Email
+		if err := repo.Create(ctx, duplicate); !errors.Is(err, domain.ErrEmailTaken) {
+			t.Errorf("Create() with a taken email error = %v, want ErrEmailTaken", err)
+		}
+		if _, err := repo.FindByID(ctx, duplicate.ID); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("FindByID() of the rejected user error = %v, want ErrUserNotFound", err)
+		}
+
+		second.Email = first.Email
+		if err := repo.Update(ctx, second); !errors.Is(err, domain.ErrEmailTaken) {
+			t.Errorf("Update() with a taken email error = %v, want ErrEmailTaken", err)
+		}
+		if !errors.Is(domain.ErrEmailTaken, domain.ErrInvalidInput) {
+			t.Error("ErrEmailTaken does not wrap ErrInvalidInput")
+		}
+	})
+
+	t.Run("Versioning", func(t *testing.T) {
+		repo := newRepo(t)
+		user := newUser(0)
+		if err := repo.Create(ctx, user); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+
+		stale := *user
+		user.Name = "Nome Novo"
+		user.Disabled = true
+		user.UpdatedAt = base.Add(time.Hour)
+		if err := repo.Update(ctx, user); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if user.Version != 2 {
+			t.Errorf("Update() version = %d, want 2", user.Version)
+		}
+		got, err := repo.FindByID(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("FindByID() error = %v", err)
+		}
+		assertUser(t, got, user)
+
+		if err := repo.Update(ctx, &stale); !errors.Is(err, domain.ErrVersionConflict) {
+			t.Errorf("Update() with a stale version error = %v, want ErrVersionConflict", err)
+		}
+		if err := repo.Delete(ctx, user.ID, 1); !errors.Is(err, domain.ErrVersionConflict) {
+			t.Errorf("Delete() with a stale version error = %v, want ErrVersionConflict", err)
+		}
+		if err := repo.Delete(ctx, user.ID, user.Version); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := repo.FindByEmail(ctx, user.Email); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("FindByEmail() after Delete error = %v, want ErrUserNotFound", err)
+		}
+	})
+}
+
+// TxRepos são os repositórios de um banco e o TxManager que os coordena
+type TxRepos struct {
+	Tx    repository.TxManager
+	Books repository.BookRepository
+	Users repository.UserRepository
+	// Sem isolamento as escritas aparecem fora da transação antes do commit,
+	// como nos repositórios em memória
+	NoIsolation bool
+}
+
+// RunTx executa os testes do contrato de TxManager. newRepos é chamada por
+// subteste e deve devolver repositórios vazios.
+func RunTx(t *testing.T, newRepos func(t *testing.T) TxRepos) {
+	ctx := context.Background()
+	errAbort := errors.New("abort")
+
+	// assertStored confere se o livro e o usuário foram ou não gravados
+	assertStored := func(t *testing.T, repos TxRepos, book *domain.Book, user *domain.User, want bool) {
+		t.Helper()
+		_, bookErr := repos.Books.FindByID(ctx, book.ID)
+		_, userErr := repos.Users.FindByID(ctx, user.ID)
+		if want && (bookErr != nil || userErr != nil) {
+			t.Errorf("FindByID() errors = %v, %v; want both records stored", bookErr, userErr)
+		}
+		if !want && (!errors.Is(bookErr, domain.ErrBookNotFound) || !errors.Is(userErr, domain.ErrUserNotFound)) {
+			t.Errorf("FindByID() errors = %v, %v; want both records rolled back", bookErr, userErr)
+		}
+	}
+
+	t.Run("Commit", func(t *testing.T) {
+		repos := newRepos(t)
+		book, user := newBook(0), newUser(0)
+
+		err := repos.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			if err := repos.Books.Create(ctx, book); err != nil {
+				return err
+			}
+			if err := repos.Users.Create(ctx, user); err != nil {
+				return err
+			}
+			// Fora da transação as escritas ainda não aparecem
+			if _, err := repos.Books.FindByID(context.Background(), book.ID); !repos.NoIsolation && !errors.Is(err, domain.ErrBookNotFound) {
+				t.Errorf("FindByID() outside the transaction error = %v, want ErrBookNotFound", err)
+			}
+			_, err := repos.Books.FindByID(ctx, book.ID)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("WithinTx() error = %v", err)
+		}
+		assertStored(t, repos, book, user, true)
+	})
+
+	t.Run("RollbackOnError", func(t *testing.T) {
+		repos := newRepos(t)
+		book, user := newBook(0), newUser(0)
+
+		err := repos.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			if err := repos.Books.Create(ctx, book); err != nil {
+				return err
+			}
+			if err := repos.Users.Create(ctx, user); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Fatalf("WithinTx() error = %v, want the error returned by fn", err)
+		}
+		assertStored(t, repos, book, user, false)
+	})
+
+	t.Run("RollbackOnPanic", func(t *testing.T) {
+		repos := newRepos(t)
+		book, user := newBook(0), newUser(0)
+
+		func() {
+			defer func() {
+				if p := recover(); p != errAbort {
+					t.Errorf("recover() = %v, want the panic of fn", p)
+				}
+			}()
+			repos.Tx.WithinTx(ctx, func(ctx context.Context) error {
+				if err := repos.Books.Create(ctx, book); err != nil {
+					return err
+				}
+				if err := repos.Users.Create(ctx, user); err != nil {
+					return err
+				}
+				panic(errAbort)
+			})
+		}()
+		assertStored(t, repos, book, user, false)
+	})
+
+	t.Run("AfterCommit", func(t *testing.T) {
+		repos := newRepos(t)
+
+		var committed []string
+		for _, result := range []error{nil, errAbort} {
+			repos.Tx.WithinTx(ctx, func(ctx context.Context) error {
+				if !repository.InTx(ctx) {
+					t.Error("InTx() = false inside WithinTx")
+				}
+				before := len(committed)
+				repository.AfterCommit(ctx, func() { committed = append(committed, fmt.Sprint(result)) })
+				if len(committed) != before {
+					t.Error("AfterCommit() ran fn before the commit")
+				}
+				return result
+			})
+		}
+		if want := []string{"<nil>"}; !slices.Equal(committed, want) {
+			t.Errorf("AfterCommit() ran for %q, want only the committed transaction", committed)
+		}
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		repos := newRepos(t)
+		book, user := newBook(0), newUser(0)
+
+		err := repos.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			err := repos.Tx.WithinTx(ctx, func(ctx context.Context) error {
+				return repos.Users.Create(ctx, user)
+			})
+			if err != nil {
+				return err
+			}
+			if err := repos.Books.Create(ctx, book); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Fatalf("WithinTx() error = %v, want the error returned by fn", err)
+		}
+		assertStored(t, repos, book, user, false)
+	})
+}
+
+// RunIdempotency executa os testes do contrato de IdempotencyRepository.
+// newRepo é chamada por subteste e deve devolver um repositório vazio.
+func RunIdempotency(t *testing.T, newRepo func(t *testing.T) repository.IdempotencyRepository) {
+	ctx := context.Background()
+
+	// acquire reserva key com fingerprint i minutos depois de base
+	acquire := func(t *testing.T, repo repository.IdempotencyRepository, key, fingerprint string, i int) *domain.IdempotencyRecord {
+		t.Helper()
+		existing, err := repo.Acquire(ctx, newIdempotencyRecord(key, fingerprint, i))
+		if err != nil {
+			t.Fatalf("Acquire() error = %v", err)
+		}
+		return existing
+	}
+
+	t.Run("InProgress", func(t *testing.T) {
+		repo := newRepo(t)
+		key := uuid.NewString()
+		if existing := acquire(t, repo, key, "a", 0); existing != nil {
+			t.Fatalf("Acquire() of a new key = %+v, want nil", existing)
+		}
+
+		existing := acquire(t, repo, key, "a", 0)
+		if existing == nil || existing.Completed || existing.Fingerprint != "a" {
+			t.Fatalf("Acquire() of a reserved key = %+v, want the unfinished record", existing)
+		}
+		if want := newIdempotencyRecord(key, "a", 0).LockedUntil; !existing.LockedUntil.Equal(want) {
+			t.Errorf("LockedUntil = %v, want %v", existing.LockedUntil, want)
+		}
+	})
+
+	t.Run("CompleteReplay", func(t *testing.T) {
+		repo := newRepo(t)
+		record := newIdempotencyRecord(uuid.NewString(), "a", 0)
+		acquire(t, repo, record.Key, "a", 0)
+
+		record.StatusCode = 201
+		record.Headers = map[string]string{"Location": "/api/books/1"}
+		record.Body = []byte(`{"id":"1"}`)
+		if err := repo.Complete(ctx, record); err != nil {
+			t.Fatalf("Complete() error = %v", err)
+		}
+
+		// A resposta guardada não é substituída depois da reserva vencer
+		existing := acquire(t, repo, record.Key, "a", 5)
+		if existing == nil || !existing.Completed || existing.StatusCode != 201 ||
+			existing.Headers["Location"] != "/api/books/1" || string(existing.Body) != `{"id":"1"}` {
+			t.Errorf("Acquire() of a completed key = %+v, want the stored response", existing)
+		}
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		repo := newRepo(t)
+		record := newIdempotencyRecord(uuid.NewString(), "a", 0)
+		acquire(t, repo, record.Key, "a", 0)
+		if err := repo.Release(ctx, record); err != nil {
+			t.Fatalf("Release() error = %v", err)
+		}
+		if existing := acquire(t, repo, record.Key, "b", 0); existing != nil {
+			t.Errorf("Acquire() of a released key = %+v, want nil", existing)
+		}
+	})
+
+	t.Run("ExpiredLock", func(t *testing.T) {
+		repo := newRepo(t)
+		key := uuid.NewString()
+		acquire(t, repo, key, "a", 0)
+
+		// Outro corpo não assume a chave, mesmo com a reserva vencida
+		if existing := acquire(t, repo, key, "b", 5); existing == nil || existing.Fingerprint != "a" {
+			t.Errorf("Acquire() with another fingerprint = %+v, want the existing record", existing)
+		}
+		if existing := acquire(t, repo, key, "a", 5); existing != nil {
+			t.Fatalf("Acquire() after the lock expired = %+v, want nil", existing)
+		}
+		// A nova tentativa agora detém a chave
+		if existing := acquire(t, repo, key, "a", 6); existing == nil || !existing.LockedUntil.Equal(base.Add(6*time.Minute)) {
+			t.Errorf("Acquire() after the takeover = %+v, want the renewed lock", existing)
+		}
+	})
+
+	t.Run("ExpiredHolder", func(t *testing.T) {
+		repo := newRepo(t)
+		stale := newIdempotencyRecord(uuid.NewString(), "a", 0)
+		acquire(t, repo, stale.Key, "a", 0)
+		acquire(t, repo, stale.Key, "a", 5)
+
+		// A requisição que perdeu a reserva não libera nem conclui a da nova
+		if err := repo.Release(ctx, stale); err != nil {
+			t.Fatalf("Release() error = %v", err)
+		}
+		stale.StatusCode = 201
+		stale.Body = []byte(`{"id":"stale"}`)
+		if err := repo.Complete(ctx, stale); err != nil {
+			t.Fatalf("Complete() error = %v", err)
+		}
+		existing := acquire(t, repo, stale.Key, "a", 5)
+		if existing == nil || existing.Completed || !existing.LockedUntil.Equal(newIdempotencyRecord(stale.Key, "a", 5).LockedUntil) {
+			t.Fatalf("Acquire() after the stale Release() and Complete() = %+v, want the new lease", existing)
+		}
+
+		current := newIdempotencyRecord(stale.Key, "a", 5)
+		current.StatusCode = 201
+		current.Body = []byte(`{"id":"current"}`)
+		if err := repo.Complete(ctx, current); err != nil {
+			t.Fatalf("Complete() error = %v", err)
+		}
+		if existing := acquire(t, repo, stale.Key, "a", 6); existing == nil || string(existing.Body) != `{"id":"current"}` {
+			t.Errorf("Acquire() after the new holder's Complete() = %+v, want its response", existing)
+		}
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		repo := newRepo(t)
+		record := newIdempotencyRecord(uuid.NewString(), "a", 0)
+		acquire(t, repo, record.Key, "a", 0)
+		record.StatusCode = 201
+		if err := repo.Complete(ctx, record); err != nil {
+			t.Fatalf("Complete() error = %v", err)
+		}
+
+		if existing := acquire(t, repo, record.Key, "b", 25*60); existing != nil {
+			t.Errorf("Acquire() of an expired key = %+v, want nil", existing)
+		}
+	})
+}
+
+// newBook cria um livro criado i minutos depois de base
+func newBook(i int) *domain.Book {
+	createdAt := base.Add(time.Duration(i) * time.Minute)
+	return &domain.Book{
+		ID:              uuid.NewString(),
+		Title:           fmt.Sprintf("Livro %d", i),
+		Author:          "Autor",
+		Description:     "Descrição",
+		Publisher:       "Editora",
+		PublicationYear: 2000 + i,
+		Subjects:        domain.StringList{"Fantasia", "Aventura"},
+		SubjectCodes:    domain.StringList{"", "v"},
+		Status:          domain.StatusAvailable,
+		CreatedAt:       createdAt,
+		UpdatedAt:       createdAt,
+	}
+}
+
+// newUser cria um usuário criado i minutos depois de base
+func newUser(i int) *domain.User {
+	createdAt := base.Add(time.Duration(i) * time.Minute)
+	return &domain.User{
+		ID:        uuid.NewString(),
+		Name:      fmt.Sprintf("Usuário %d", i),
+		Email:     fmt.Sprintf("user%d@example.com", i),
+		Password:  "$2a$10$hash",
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+}
+
+func assertBook(t *testing.T, got, want *domain.Book) {
+	t.Helper()
+	if got.ID != want.ID || got.Title != want.Title || got.Author != want.Author || got.ISBN != want.ISBN ||
+		got.Description != want.Description || got.CoverURL != want.CoverURL || got.Publisher != want.Publisher ||
+		got.PublicationYear != want.PublicationYear || fmt.Sprint(got.Subjects) != fmt.Sprint(want.Subjects) ||
+		fmt.Sprint(got.SubjectCodes) != fmt.Sprint(want.SubjectCodes) || got.Status != want.Status || got.Version != want.Version ||
+		!got.CreatedAt.Equal(want.CreatedAt) || !got.UpdatedAt.Equal(want.UpdatedAt) {
+		t.Errorf("book = %+v, want %+v", got, want)
+	}
+}
+
+func assertUser(t *testing.T, got, want *domain.User) {
+	t.Helper()
+	if got.ID != want.ID || got.Name != want.Name || got.Email != want.Email || got.Password != want.Password ||
+		got.Version != want.Version || got.Disabled != want.Disabled ||
+		!got.CreatedAt.Equal(want.CreatedAt) || !got.UpdatedAt.Equal(want.UpdatedAt) {
+		t.Errorf("user = %+v, want %+v", got, want)
+	}
+}
+
+// assertOrder confere que got são os livros de books nas posições want
+func assertOrder(t *testing.T, call string, got, books []*domain.Book, want []int) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s returned %d books, want %d", call, len(got), len(want))
+		return
+	}
+	for i, index := range want {
+		if got[i].ID != books[index].ID {
+			t.Errorf("%s[%d] = %q, want %q", call, i, got[i].Title, books[index].Title)
+		}
+	}
+}
+
+// newIdempotencyRecord cria uma reserva de key feita i minutos depois de base,
+// que vale por um minuto e expira em um dia
+func newIdempotencyRecord(key, fingerprint string, i int) *domain.IdempotencyRecord {
+	createdAt := base.Add(time.Duration(i) * time.Minute)
+	return &domain.IdempotencyRecord{
+		Key:         key,
+		Fingerprint: fingerprint,
+		CreatedAt:   createdAt,
+		LockedUntil: createdAt.Add(time.Minute),
+		ExpiresAt:   createdAt.Add(24 * time.Hour),
+	}
+}
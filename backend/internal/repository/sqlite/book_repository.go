@@ -0,0 +1,273 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
+)
+
+// Quantidade máxima de livros por INSERT em CreateMany; com 14 colunas fica
+// bem abaixo do limite de 32766 parâmetros do SQLite
+const createManyChunkSize = 500
+
+type bookRepository struct {
+	db dbtx
+}
+
+func NewBookRepository(db *sqlx.DB) repository.BookRepository {
+	return &bookRepository{
+		db: db,
+	}
+}
+
+const bookColumns = `id, title, author, isbn, description, cover_url, cover_variants, publisher,
+                    publication_year, subjects, subject_codes, status, version, created_at, updated_at`
+
+func (r *bookRepository) FindByID(ctx context.Context, id string) (*domain.Book, error) {
+	const query = `SELECT ` + bookColumns + ` FROM books WHERE id = ?`
+
+	var book domain.Book
+	err := conn(ctx, r.db).GetContext(ctx, &book, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrBookNotFound
+		}
+		return nil, err
+	}
+
+	return &book, nil
+}
+
+func (r *bookRepository) FindAll(ctx context.Context, limit, offset int) ([]*domain.Book, error) {
+	const query = `SELECT ` + bookColumns + ` FROM books ORDER BY created_at DESC LIMIT ? OFFSET ?`
+
+	var books []*domain.Book
+	err := conn(ctx, r.db).SelectContext(ctx, &books, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+func (r *bookRepository) Iterate(ctx context.Context, limit, offset int, fn func(book *domain.Book) error) error {
+	const query = `SELECT ` + bookColumns + ` FROM books ORDER BY created_at DESC LIMIT ? OFFSET ?`
+
+	// LIMIT -1 equivale a sem limite
+	if limit <= 0 {
+		limit = -1
+	}
+
+	rows, err := conn(ctx, r.db).QueryxContext(ctx, query, limit, offset)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var book domain.Book
+		if err := rows.StructScan(&book); err != nil {
+			return err
+		}
+		if err := fn(&book); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *bookRepository) FindByISBN(ctx context.Context, isbn domain.ISBN) ([]*domain.Book, error) {
+	const query = `SELECT ` + bookColumns + ` FROM books
+                  WHERE isbn_digits(isbn) IN (?, ?) ORDER BY created_at DESC`
+
+	// Sem forma ISBN-10 (prefixo 979) a comparação repete o ISBN-13
+	isbn10, ok := isbn.ISBN10()
+	if !ok {
+		isbn10 = isbn.String()
+	}
+
+	books := []*domain.Book{}
+	err := conn(ctx, r.db).SelectContext(ctx, &books, query, isbn.String(), isbn10)
+	if err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+func (r *bookRepository) ExistingISBNs(ctx context.Context, isbns []string) ([]string, error) {
+	const query = `SELECT DISTINCT isbn_digits(isbn) FROM books WHERE isbn_digits(isbn) IN (?)`
+
+	existing := []string{}
+	if len(isbns) == 0 {
+		return existing, nil
+	}
+
+	// Procura cada ISBN-13 e a sua forma ISBN-10, quando houver
+	forms := make([]string, 0, 2*len(isbns))
+	for _, isbn := range isbns {
+		forms = append(forms, isbn)
+		if isbn10, ok := domain.ISBN(isbn).ISBN10(); ok {
+			forms = append(forms, isbn10)
+		}
+	}
+
+	inQuery, args, err := sqlx.In(query, forms)
+	if err != nil {
+		return nil, err
+	}
+	var found []string
+	if err := conn(ctx, r.db).SelectContext(ctx, &found, inQuery, args...); err != nil {
+		return nil, err
+	}
+
+	stored := make(map[string]bool, len(found))
+	for _, digits := range found {
+		stored[digits] = true
+	}
+	seen := make(map[string]bool, len(isbns))
+	for _, isbn := range isbns {
+		isbn10, ok := domain.ISBN(isbn).ISBN10()
+		if !seen[isbn] && (stored[isbn] || ok && stored[isbn10]) {
+			existing = append(existing, isbn)
+		}
+		seen[isbn] = true
+	}
+
+	return existing, nil
+}
+
+func (r *bookRepository) Create(ctx context.Context, book *domain.Book) error {
+	return r.CreateMany(ctx, []*domain.Book{book})
+}
+
+func (r *bookRepository) CreateMany(ctx context.Context, books []*domain.Book) error {
+	const prefix = `INSERT INTO books (id, title, author, isbn, description, cover_url, publisher,
+                    publication_year, subjects, subject_codes, status, version, created_at, updated_at) VALUES `
+	const row = `(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	const columns = 14
+
+	for start := 0; start < len(books); start += createManyChunkSize {
+		end := min(start+createManyChunkSize, len(books))
+		chunk := books[start:end]
+
+		var query strings.Builder
+		query.WriteString(prefix)
+		args := make([]interface{}, 0, len(chunk)*columns)
+		for i, book := range chunk {
+			if book.Version == 0 {
+				book.Version = 1
+			}
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			query.WriteString(row)
+			args = append(args, book.ID, book.Title, book.Author, book.ISBN, book.Description,
+				book.CoverURL, book.Publisher, book.PublicationYear, book.Subjects, book.SubjectCodes,
+				book.Status, book.Version, book.CreatedAt.UTC(), book.UpdatedAt.UTC())
+		}
+
+		if _, err := conn(ctx, r.db).ExecContext(ctx, query.String(), args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *bookRepository) Update(ctx context.Context, book *domain.Book) error {
+	const query = `UPDATE books SET title = ?, author = ?, isbn = ?, description = ?,
+                  cover_url = ?, publisher = ?, publication_year = ?, subjects = ?,
+                  subject_codes = ?, status = ?, updated_at = ?, version = version + 1
+                  WHERE id = ? AND version = ? RETURNING version`
+
+	var version int64
+	err := conn(ctx, r.db).GetContext(ctx, &version, query, book.Title, book.Author, book.ISBN,
+		book.Description, book.CoverURL, book.Publisher, book.PublicationYear, book.Subjects,
+		book.SubjectCodes, book.Status, book.UpdatedAt.UTC(), book.ID, book.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return r.missingOrConflict(ctx, book.ID)
+		}
+		return err
+	}
+
+	book.Version = version
+	return nil
+}
+
+func (r *bookRepository) Delete(ctx context.Context, id string, version int64) error {
+	const query = `DELETE FROM books WHERE id = ? AND version = ?`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, version)
+	if err != nil {
+		return err
+	}
+
+	return r.checkAffected(ctx, result, id)
+}
+
+func (r *bookRepository) UpdateCoverVariants(ctx context.Context, id string, variants domain.CoverVariants) error {
+	const query = `UPDATE books SET cover_variants = ?, version = version + 1
+                  WHERE id = ? AND COALESCE(cover_url, '') = ?`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, variants, id, variants.Source)
+	if err != nil {
+		return err
+	}
+
+	return r.checkAffected(ctx, result, id)
+}
+
+func (r *bookRepository) FindStaleCovers(ctx context.Context, limit int) ([]string, error) {
+	const query = `SELECT id FROM books
+                  WHERE COALESCE(cover_url, '') <> COALESCE(json_extract(cover_variants, '$.source'), '')
+                  ORDER BY updated_at LIMIT ?`
+
+	ids := []string{}
+	err := conn(ctx, r.db).SelectContext(ctx, &ids, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// checkAffected converte uma escrita condicional sem efeito no erro de
+// missingOrConflict
+func (r *bookRepository) checkAffected(ctx context.Context, result sql.Result, id string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return r.missingOrConflict(ctx, id)
+	}
+
+	return nil
+}
+
+// missingOrConflict distingue, após uma escrita condicional sem efeito, se o
+// livro não existe ou se a versão informada está desatualizada
+func (r *bookRepository) missingOrConflict(ctx context.Context, id string) error {
+	const query = `SELECT EXISTS (SELECT 1 FROM books WHERE id = ?)`
+
+	var exists bool
+	if err := conn(ctx, r.db).GetContext(ctx, &exists, query, id); err != nil {
+		return err
+	}
+
+	if !exists {
+		return domain.ErrBookNotFound
+	}
+
+	return domain.ErrVersionConflict
+}
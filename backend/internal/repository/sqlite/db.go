@@ -0,0 +1,46 @@
+// Package sqlite implementa os repositórios sobre um banco SQLite, para
+// instalações em uma única máquina
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	sqlitedriver "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// dbtx é o subconjunto comum de *sqlx.DB e *sqlx.Tx usado pelos repositórios
+type dbtx interface {
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error)
+}
+
+func init() {
+	// isbn_digits normaliza a coluna isbn como o regexp_replace das consultas
+	// do Postgres: maiúsculas, somente dígitos e X
+	sqlitedriver.MustRegisterDeterministicScalarFunction("isbn_digits", 1,
+		func(ctx *sqlitedriver.FunctionContext, args []driver.Value) (driver.Value, error) {
+			isbn, _ := args[0].(string)
+			return strings.Map(func(r rune) rune {
+				if r >= '0' && r <= '9' || r == 'X' {
+					return r
+				}
+				return -1
+			}, strings.ToUpper(isbn)), nil
+		})
+}
+
+// uniqueViolation informa se err é a violação da unicidade de column
+// (no formato tabela.coluna)
+func uniqueViolation(err error, column string) bool {
+	var sqliteErr *sqlitedriver.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE &&
+		strings.Contains(sqliteErr.Error(), column)
+}
@@ -0,0 +1,128 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
+)
+
+type idempotencyRepository struct {
+	db *sqlx.DB
+}
+
+type idempotencyRow struct {
+	Key             string    `db:"key"`
+	Fingerprint     string    `db:"fingerprint"`
+	Completed       bool      `db:"completed"`
+	StatusCode      int       `db:"status_code"`
+	ResponseHeaders string    `db:"response_headers"`
+	ResponseBody    []byte    `db:"response_body"`
+	CreatedAt       time.Time `db:"created_at"`
+	ExpiresAt       time.Time `db:"expires_at"`
+	LockedUntil     time.Time `db:"locked_until"`
+}
+
+func NewIdempotencyRepository(db *sqlx.DB) repository.IdempotencyRepository {
+	return &idempotencyRepository{
+		db: db,
+	}
+}
+
+func (r *idempotencyRepository) Acquire(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, error) {
+	const cleanup = `DELETE FROM idempotency_keys WHERE expires_at < ?`
+	const insert = `INSERT INTO idempotency_keys (key, fingerprint, created_at, expires_at, locked_until)
+                   VALUES (?, ?, ?, ?, ?) ON CONFLICT (key) DO NOTHING`
+	// Uma requisição que não terminou dentro do prazo (o processo caiu, por
+	// exemplo) perde a chave para uma nova tentativa com o mesmo corpo
+	const takeOver = `UPDATE idempotency_keys SET created_at = ?, expires_at = ?, locked_until = ?
+                     WHERE key = ? AND fingerprint = ? AND completed = FALSE AND locked_until < ?`
+	const query = `SELECT key, fingerprint, completed, status_code, response_headers, response_body,
+                  created_at, expires_at, locked_until FROM idempotency_keys WHERE key = ?`
+
+	if _, err := conn(ctx, r.db).ExecContext(ctx, cleanup, record.CreatedAt.UTC()); err != nil {
+		return nil, err
+	}
+
+	acquired, err := r.exec(ctx, insert, record.Key, record.Fingerprint,
+		record.CreatedAt.UTC(), record.ExpiresAt.UTC(), record.LockedUntil.UTC())
+	if err != nil || acquired {
+		return nil, err
+	}
+
+	acquired, err = r.exec(ctx, takeOver, record.CreatedAt.UTC(), record.ExpiresAt.UTC(), record.LockedUntil.UTC(),
+		record.Key, record.Fingerprint, record.CreatedAt.UTC())
+	if err != nil || acquired {
+		return nil, err
+	}
+
+	var row idempotencyRow
+	if err := conn(ctx, r.db).GetContext(ctx, &row, query, record.Key); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// A requisição concorrente liberou a chave entre o INSERT e o SELECT
+			return nil, domain.ErrIdempotencyKeyInProgress
+		}
+		return nil, err
+	}
+
+	existing := &domain.IdempotencyRecord{
+		Key:         row.Key,
+		Fingerprint: row.Fingerprint,
+		Completed:   row.Completed,
+		StatusCode:  row.StatusCode,
+		Body:        row.ResponseBody,
+		CreatedAt:   row.CreatedAt,
+		ExpiresAt:   row.ExpiresAt,
+		LockedUntil: row.LockedUntil,
+	}
+	if err := json.Unmarshal([]byte(row.ResponseHeaders), &existing.Headers); err != nil {
+		return nil, err
+	}
+
+	return existing, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	const query = `UPDATE idempotency_keys SET completed = TRUE, status_code = ?,
+                  response_headers = ?, response_body = ?
+                  WHERE key = ? AND completed = FALSE AND locked_until = ?`
+
+	headers, err := json.Marshal(record.Headers)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn(ctx, r.db).ExecContext(ctx, query, record.StatusCode, string(headers), record.Body,
+		record.Key, record.LockedUntil.UTC())
+
+	return err
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, record *domain.IdempotencyRecord) error {
+	const query = `DELETE FROM idempotency_keys WHERE key = ? AND completed = FALSE AND locked_until = ?`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, record.Key, record.LockedUntil.UTC())
+
+	return err
+}
+
+// exec executa um INSERT ou UPDATE de uma chave e informa se ela foi gravada
+func (r *idempotencyRepository) exec(ctx context.Context, query string, args ...interface{}) (bool, error) {
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/config"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/database"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/infra/migrate"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository/repotest"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/migrations"
+)
+
+// testDB cria um banco novo, com as migrações aplicadas, em um diretório temporário
+func testDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+	db, err := database.NewSQLiteConnection(context.Background(), config.DatabaseConfig{
+		SQLitePath:   filepath.Join(t.TempDir(), "bookflow.db"),
+		MaxOpenConns: 4,
+		MaxIdleConns: 4,
+	})
+	if err != nil {
+		t.Fatalf("NewSQLiteConnection() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	m, err := migrate.New(db, migrations.SQLite)
+	if err != nil {
+		t.Fatalf("migrate.New() error = %v", err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatalf("migrate Up() error = %v", err)
+	}
+	return db
+}
+
+func TestBookRepository(t *testing.T) {
+	repotest.RunBooks(t, func(t *testing.T) repository.BookRepository {
+		return NewBookRepository(testDB(t))
+	})
+}
+
+func TestUserRepository(t *testing.T) {
+	repotest.RunUsers(t, func(t *testing.T) repository.UserRepository {
+		return NewUserRepository(testDB(t))
+	})
+}
+
+func TestIdempotencyRepository(t *testing.T) {
+	repotest.RunIdempotency(t, func(t *testing.T) repository.IdempotencyRepository {
+		return NewIdempotencyRepository(testDB(t))
+	})
+}
+
+func TestTxManager(t *testing.T) {
+	repotest.RunTx(t, func(t *testing.T) repotest.TxRepos {
+		db := testDB(t)
+		return repotest.TxRepos{
+			Tx:    NewTxManager(db),
+			Books: NewBookRepository(db),
+			Users: NewUserRepository(db),
+		}
+	})
+}
+
+// As datas são comparadas como texto, então precisam ser gravadas em UTC: no
+// fim do horário de verão, 01:10 EST vem depois de 01:50 EDT
+func TestTimesAcrossOffsets(t *testing.T) {
+	ctx := context.Background()
+	db := testDB(t)
+	edt := time.FixedZone("EDT", -4*60*60)
+	est := time.FixedZone("EST", -5*60*60)
+	before := time.Date(2026, 11, 1, 1, 30, 0, 0, edt)
+	after := time.Date(2026, 11, 1, 1, 10, 0, 0, est)
+
+	idempotency := NewIdempotencyRepository(db)
+	lease := &domain.IdempotencyRecord{Key: "key", Fingerprint: "a", CreatedAt: before,
+		LockedUntil: before.Add(20 * time.Minute), ExpiresAt: before.Add(time.Hour)}
+	if _, err := idempotency.Acquire(ctx, lease); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	retry := &domain.IdempotencyRecord{Key: "key", Fingerprint: "a", CreatedAt: after,
+		LockedUntil: after.Add(20 * time.Minute), ExpiresAt: after.Add(time.Hour)}
+	if existing, err := idempotency.Acquire(ctx, retry); err != nil || existing != nil {
+		t.Errorf("Acquire() after the lock expired = %+v, %v; want the key taken over", existing, err)
+	}
+
+	books := NewBookRepository(db)
+	first := &domain.Book{ID: "first", Title: "Primeiro", Author: "Autor", Status: domain.StatusAvailable, CreatedAt: before, UpdatedAt: before}
+	second := &domain.Book{ID: "second", Title: "Segundo", Author: "Autor", Status: domain.StatusAvailable, CreatedAt: after, UpdatedAt: after}
+	if err := books.CreateMany(ctx, []*domain.Book{first, second}); err != nil {
+		t.Fatalf("CreateMany() error = %v", err)
+	}
+	got, err := books.FindAll(ctx, 10, 0)
+	if err != nil || len(got) != 2 || got[0].ID != "second" || !got[0].CreatedAt.Equal(after) {
+		t.Errorf("FindAll() = %v, %v; want the later book first", got, err)
+	}
+}
@@ -0,0 +1,52 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	sqlitedriver "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
+)
+
+// txKey guarda no contexto a transação aberta pelo TxManager
+type txKey struct{}
+
+// conn devolve a conexão de uma operação: a transação aberta pelo TxManager
+// em ctx ou, fora dela, db
+func conn(ctx context.Context, db dbtx) dbtx {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// NewTxManager cria um repository.TxManager. As transações do SQLite já são
+// serializáveis; a transação é repetida quando o banco continua ocupado
+// depois do busy_timeout.
+func NewTxManager(db *sqlx.DB) repository.TxManager {
+	return repository.NewTxManager(func(ctx context.Context) (context.Context, repository.Tx, error) {
+		tx, err := db.BeginTxx(ctx, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		return context.WithValue(ctx, txKey{}, tx), tx, nil
+	}, retryable)
+}
+
+// retryable informa se err indica que o banco estava ocupado ou travado por
+// outra conexão
+func retryable(err error) bool {
+	var sqliteErr *sqlitedriver.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	// Os códigos estendidos guardam o código primário no byte menos significativo
+	switch sqliteErr.Code() & 0xff {
+	case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
+)
+
+type userRepository struct {
+	db *sqlx.DB
+}
+
+func NewUserRepository(db *sqlx.DB) repository.UserRepository {
+	return &userRepository{
+		db: db,
+	}
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	const query = `SELECT id, name, email, password, version, disabled, created_at, updated_at FROM users WHERE id = ?`
+
+	var user domain.User
+	err := conn(ctx, r.db).GetContext(ctx, &user, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	const query = `SELECT id, name, email, password, version, disabled, created_at, updated_at FROM users WHERE email = ?`
+
+	var user domain.User
+	err := conn(ctx, r.db).GetContext(ctx, &user, query, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *userRepository) FindAll(ctx context.Context, limit, offset int) ([]*domain.User, error) {
+	const query = `SELECT id, name, email, version, disabled, created_at, updated_at FROM users ORDER BY created_at DESC LIMIT ? OFFSET ?`
+
+	var users []*domain.User
+	err := conn(ctx, r.db).SelectContext(ctx, &users, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
+	const query = `INSERT INTO users (id, name, email, password, version, disabled, created_at, updated_at)
+                  VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	if user.Version == 0 {
+		user.Version = 1
+	}
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, user.ID, user.Name, user.Email,
+		user.Password, user.Version, user.Disabled, user.CreatedAt.UTC(), user.UpdatedAt.UTC())
+
+	return emailTaken(err)
+}
+
+func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
+	const query = `UPDATE users SET name = ?, email = ?, password = ?, disabled = ?,
+                  updated_at = ?, version = version + 1
+                  WHERE id = ? AND version = ? RETURNING version`
+
+	var version int64
+	err := conn(ctx, r.db).GetContext(ctx, &version, query, user.Name, user.Email,
+		user.Password, user.Disabled, user.UpdatedAt.UTC(), user.ID, user.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return r.missingOrConflict(ctx, user.ID)
+		}
+		return emailTaken(err)
+	}
+
+	user.Version = version
+	return nil
+}
+
+func (r *userRepository) Delete(ctx context.Context, id string, version int64) error {
+	const query = `DELETE FROM users WHERE id = ? AND version = ?`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, version)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return r.missingOrConflict(ctx, id)
+	}
+
+	return nil
+}
+
+// missingOrConflict distingue, após uma escrita condicional sem efeito, se o
+// usuário não existe ou se a versão informada está desatualizada
+func (r *userRepository) missingOrConflict(ctx context.Context, id string) error {
+	const query = `SELECT EXISTS (SELECT 1 FROM users WHERE id = ?)`
+
+	var exists bool
+	if err := conn(ctx, r.db).GetContext(ctx, &exists, query, id); err != nil {
+		return err
+	}
+
+	if !exists {
+		return domain.ErrUserNotFound
+	}
+
+	return domain.ErrVersionConflict
+}
+
+// emailTaken traduz a violação da unicidade do email para domain.ErrEmailTaken
+func emailTaken(err error) error {
+	if uniqueViolation(err, "users.email") {
+		return domain.ErrEmailTaken
+	}
+	return err
+}
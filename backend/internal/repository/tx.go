@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// MaxTxAttempts é o número de tentativas de uma transação que o banco abortou
+// por conflito com outra transação
+const MaxTxAttempts = 3
+
+// Tx é uma transação aberta por BeginTxFunc
+type Tx interface {
+	Commit() error
+	Rollback() error
+}
+
+// BeginTxFunc abre uma transação e devolve o contexto que a leva até os
+// repositórios do mesmo banco
+type BeginTxFunc func(ctx context.Context) (context.Context, Tx, error)
+
+type txManager struct {
+	begin     BeginTxFunc
+	retryable func(err error) bool
+}
+
+// NewTxManager cria um TxManager sobre as transações abertas por begin. A
+// parte que depende do banco é só begin e retryable, que informa se a
+// transação foi abortada por conflito com outra e pode ser repetida.
+func NewTxManager(begin BeginTxFunc, retryable func(err error) bool) TxManager {
+	return &txManager{
+		begin:     begin,
+		retryable: retryable,
+	}
+}
+
+func (m *txManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if InTx(ctx) {
+		// Já está dentro de uma transação
+		return fn(ctx)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := m.run(ctx, fn)
+		if err == nil || attempt == MaxTxAttempts || !m.retryable(err) {
+			return err
+		}
+
+		// Espera um pouco, com variação, para que as transações em conflito
+		// não colidam de novo
+		delay := time.Duration(attempt)*10*time.Millisecond + rand.N(10*time.Millisecond)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// run executa fn em uma única transação
+func (m *txManager) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	txCtx, tx, err := m.begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	txCtx, hooks := withTxHooks(txCtx)
+	if err := fn(txCtx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	hooks.run()
+	return nil
+}
+
+type txHooksKey struct{}
+
+// txHooks guarda as funções a executar depois que a transação é confirmada
+type txHooks struct {
+	mu          sync.Mutex
+	afterCommit []func()
+}
+
+// withTxHooks marca ctx como dentro de uma transação
+func withTxHooks(ctx context.Context) (context.Context, *txHooks) {
+	hooks := &txHooks{}
+	return context.WithValue(ctx, txHooksKey{}, hooks), hooks
+}
+
+// run executa as funções registradas por AfterCommit, na ordem em que foram
+// registradas
+func (h *txHooks) run() {
+	h.mu.Lock()
+	fns := h.afterCommit
+	h.afterCommit = nil
+	h.mu.Unlock()
+
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// InTx informa se ctx está dentro de uma transação de TxManager
+func InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txHooksKey{}).(*txHooks)
+	return ok
+}
+
+// AfterCommit executa fn depois do commit da transação de ctx ou, fora de uma
+// transação, imediatamente. Se a transação for desfeita, fn não é executada.
+func AfterCommit(ctx context.Context, fn func()) {
+	hooks, ok := ctx.Value(txHooksKey{}).(*txHooks)
+	if !ok {
+		fn()
+		return
+	}
+
+	hooks.mu.Lock()
+	defer hooks.mu.Unlock()
+	hooks.afterCommit = append(hooks.afterCommit, fn)
+}
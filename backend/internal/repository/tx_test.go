@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// fakeTx registra como a transação terminou
+type fakeTx struct {
+	committed, rolledBack int
+}
+
+func (t *fakeTx) Commit() error {
+	t.committed++
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.rolledBack++
+	return nil
+}
+
+func TestTxManagerRetry(t *testing.T) {
+	ctx := context.Background()
+	errConflict := errors.New("conflict")
+	errOther := errors.New("other")
+
+	tests := []struct {
+		name         string
+		fails        int
+		err          error
+		wantAttempts int
+		wantErr      error
+	}{
+		{name: "commit", wantAttempts: 1},
+		{name: "retried conflict", fails: 1, err: errConflict, wantAttempts: 2},
+		{name: "persistent conflict", fails: MaxTxAttempts, err: errConflict, wantAttempts: MaxTxAttempts, wantErr: errConflict},
+		{name: "other error", fails: 1, err: errOther, wantAttempts: 1, wantErr: errOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &fakeTx{}
+			manager := NewTxManager(func(ctx context.Context) (context.Context, Tx, error) {
+				return ctx, tx, nil
+			}, func(err error) bool { return errors.Is(err, errConflict) })
+
+			attempts := 0
+			err := manager.WithinTx(ctx, func(ctx context.Context) error {
+				attempts++
+				if attempts <= tt.fails {
+					return tt.err
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.wantErr) || attempts != tt.wantAttempts {
+				t.Errorf("WithinTx() = %v after %d attempts, want %v after %d", err, attempts, tt.wantErr, tt.wantAttempts)
+			}
+			if want := min(tt.fails, tt.wantAttempts); tx.rolledBack != want {
+				t.Errorf("rolled back %d times, want %d", tx.rolledBack, want)
+			}
+			if want := tt.wantAttempts - tx.rolledBack; tx.committed != want {
+				t.Errorf("committed %d times, want %d", tx.committed, want)
+			}
+		})
+	}
+}
+
+func TestTxManagerPanicAndNesting(t *testing.T) {
+	ctx := context.Background()
+	begins := 0
+	tx := &fakeTx{}
+	manager := NewTxManager(func(ctx context.Context) (context.Context, Tx, error) {
+		begins++
+		return ctx, tx, nil
+	}, func(error) bool { return true })
+
+	var ran bool
+	err := manager.WithinTx(ctx, func(ctx context.Context) error {
+		AfterCommit(ctx, func() { ran = true })
+		return manager.WithinTx(ctx, func(ctx context.Context) error {
+			if ran {
+				t.Error("AfterCommit() ran fn before the commit")
+			}
+			return nil
+		})
+	})
+	if err != nil || begins != 1 || !ran {
+		t.Errorf("nested WithinTx() = %v with %d transactions, AfterCommit ran = %v; want one committed transaction", err, begins, ran)
+	}
+
+	defer func() {
+		if p := recover(); p != "boom" || tx.rolledBack != 1 {
+			t.Errorf("recover() = %v with %d rollbacks, want the panic after a rollback", p, tx.rolledBack)
+		}
+	}()
+	manager.WithinTx(ctx, func(ctx context.Context) error {
+		panic("boom")
+	})
+}
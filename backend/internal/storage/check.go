@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+// Chave que nunca é gravada, lida pela verificação de disponibilidade
+const checkKey = "health/probe"
+
+// Check confirma que o armazenamento responde, lendo uma chave inexistente:
+// domain.ErrBlobNotFound indica que o backend está acessível
+func Check(ctx context.Context, store BlobStore) error {
+	blob, err := store.Get(ctx, checkKey)
+	if errors.Is(err, domain.ErrBlobNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return blob.Body.Close()
+}
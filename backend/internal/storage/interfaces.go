@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// BlobStore armazena arquivos binários (ex.: capas de livros) por chave.
+// Chaves usam "/" como separador, independente do backend.
+type BlobStore interface {
+	// Put grava o conteúdo na chave, substituindo o anterior. size é o
+	// tamanho exato de body.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Get abre o conteúdo da chave; domain.ErrBlobNotFound se não existir.
+	// O chamador deve fechar Blob.Body.
+	Get(ctx context.Context, key string) (*Blob, error)
+	// Delete remove a chave; remover uma chave inexistente não é erro
+	Delete(ctx context.Context, key string) error
+}
+
+// Blob é o conteúdo armazenado em uma chave
+type Blob struct {
+	Body        io.ReadCloser
+	ContentType string
+	Size        int64
+	ModTime     time.Time
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+// ValidateKey aceita chaves relativas formadas por letras, dígitos, ".", "-",
+// "_" e "/", sem segmentos vazios, "." ou "..", para que uma chave nunca
+// escape do diretório ou bucket do backend
+func ValidateKey(key string) error {
+	if key == "" || len(key) > 1024 {
+		return fmt.Errorf("%w: invalid blob key", domain.ErrInvalidInput)
+	}
+
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("%w: invalid blob key %q", domain.ErrInvalidInput, key)
+		}
+	}
+
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '.', r == '-', r == '_', r == '/':
+		default:
+			return fmt.Errorf("%w: invalid blob key %q", domain.ErrInvalidInput, key)
+		}
+	}
+
+	return nil
+}
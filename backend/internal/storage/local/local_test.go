@@ -0,0 +1,16 @@
+package local
+
+import (
+	"testing"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/storage/storagetest"
+)
+
+func TestBlobStore(t *testing.T) {
+	store, err := NewBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewBlobStore() error = %v", err)
+	}
+
+	storagetest.Run(t, store)
+}
@@ -0,0 +1,102 @@
+// Package s3 armazena os blobs em um bucket compatível com a API do Amazon
+// S3 (AWS, MinIO, Cloudflare R2, etc.)
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/storage"
+)
+
+type Config struct {
+	// Endereço do serviço, sem esquema (ex.: s3.amazonaws.com, localhost:9000)
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+	Bucket    string
+	Region    string
+	UseSSL    bool
+}
+
+type blobStore struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewBlobStore conecta ao serviço e cria o bucket, se ainda não existir
+func NewBlobStore(ctx context.Context, cfg Config) (storage.BlobStore, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("check bucket %s: %w", cfg.Bucket, err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("create bucket %s: %w", cfg.Bucket, err)
+		}
+	}
+
+	return &blobStore{client: client, bucket: cfg.Bucket}, nil
+}
+
+func (s *blobStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	if err := storage.ValidateKey(key); err != nil {
+		return err
+	}
+
+	_, err := s.client.PutObject(ctx, s.bucket, key, body, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	return err
+}
+
+func (s *blobStore) Get(ctx context.Context, key string) (*storage.Blob, error) {
+	if err := storage.ValidateKey(key); err != nil {
+		return nil, err
+	}
+
+	// GetObject não faz a requisição; Stat confirma que o objeto existe
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := object.Stat()
+	if err != nil {
+		object.Close()
+		if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+			return nil, domain.ErrBlobNotFound
+		}
+		return nil, err
+	}
+
+	return &storage.Blob{
+		Body:        object,
+		ContentType: info.ContentType,
+		Size:        info.Size,
+		ModTime:     info.LastModified,
+	}, nil
+}
+
+func (s *blobStore) Delete(ctx context.Context, key string) error {
+	if err := storage.ValidateKey(key); err != nil {
+		return err
+	}
+
+	// O S3 responde com sucesso mesmo quando a chave não existe
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
@@ -0,0 +1,52 @@
+package s3
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/storage/storagetest"
+)
+
+// TestBlobStore roda contra um MinIO (ou outro serviço S3) indicado em
+// S3_TEST_ENDPOINT, por exemplo o do docker-compose.dev.yml:
+//
+//	S3_TEST_ENDPOINT=localhost:9000 go test ./internal/storage/s3/
+func TestBlobStore(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT not set")
+	}
+
+	cfg := Config{
+		Endpoint:  endpoint,
+		AccessKey: envOr("S3_TEST_ACCESS_KEY", "minioadmin"),
+		SecretKey: envOr("S3_TEST_SECRET_KEY", "minioadmin"),
+		Bucket:    "bookflow-test-" + strconv.FormatInt(time.Now().UnixNano(), 36),
+		Region:    "us-east-1",
+	}
+
+	ctx := context.Background()
+	store, err := NewBlobStore(ctx, cfg)
+	if err != nil {
+		t.Fatalf("NewBlobStore() error = %v", err)
+	}
+	t.Cleanup(func() {
+		client := store.(*blobStore).client
+		for _, key := range []string{"covers/a/one.png", "covers/overwrite.jpg"} {
+			store.Delete(ctx, key)
+		}
+		client.RemoveBucket(ctx, cfg.Bucket)
+	})
+
+	storagetest.Run(t, store)
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,100 @@
+// Package storagetest verifica que uma implementação de storage.BlobStore
+// segue o contrato da interface
+package storagetest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/storage"
+)
+
+// Run executa os testes do contrato contra store, que deve estar vazio
+func Run(t *testing.T, store storage.BlobStore) {
+	ctx := context.Background()
+
+	t.Run("PutGet", func(t *testing.T) {
+		content := []byte("\x89PNG\r\n\x1a\nconteúdo")
+		if err := store.Put(ctx, "covers/a/one.png", bytes.NewReader(content), int64(len(content)), "image/png"); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+
+		blob, err := store.Get(ctx, "covers/a/one.png")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		defer blob.Body.Close()
+
+		data, err := io.ReadAll(blob.Body)
+		if err != nil {
+			t.Fatalf("read blob: %v", err)
+		}
+		if !bytes.Equal(data, content) {
+			t.Errorf("Get() content = %q, want %q", data, content)
+		}
+		if blob.Size != int64(len(content)) || blob.ContentType != "image/png" || blob.ModTime.IsZero() {
+			t.Errorf("Get() = size %d, type %q, modified %v", blob.Size, blob.ContentType, blob.ModTime)
+		}
+	})
+
+	t.Run("Overwrite", func(t *testing.T) {
+		for _, content := range []string{"primeiro", "segundo"} {
+			if err := store.Put(ctx, "covers/overwrite.jpg", bytes.NewReader([]byte(content)), int64(len(content)), "image/jpeg"); err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+		}
+
+		blob, err := store.Get(ctx, "covers/overwrite.jpg")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		defer blob.Body.Close()
+
+		if data, _ := io.ReadAll(blob.Body); string(data) != "segundo" {
+			t.Errorf("Get() content = %q, want the last write", data)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		if _, err := store.Get(ctx, "covers/missing.jpg"); !errors.Is(err, domain.ErrBlobNotFound) {
+			t.Errorf("Get() error = %v, want ErrBlobNotFound", err)
+		}
+	})
+
+	t.Run("Check", func(t *testing.T) {
+		if err := storage.Check(ctx, store); err != nil {
+			t.Errorf("Check() error = %v", err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if err := store.Put(ctx, "covers/delete.gif", bytes.NewReader([]byte("GIF89a")), 6, "image/gif"); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+
+		for i := 0; i < 2; i++ {
+			if err := store.Delete(ctx, "covers/delete.gif"); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+		}
+
+		if _, err := store.Get(ctx, "covers/delete.gif"); !errors.Is(err, domain.ErrBlobNotFound) {
+			t.Errorf("Get() after Delete() error = %v, want ErrBlobNotFound", err)
+		}
+	})
+
+	t.Run("InvalidKey", func(t *testing.T) {
+		for _, key := range []string{"", "../escape.jpg", "covers/../../escape.jpg", "/absolute.jpg", "covers//x.jpg", `covers\x.jpg`} {
+			if err := store.Put(ctx, key, bytes.NewReader(nil), 0, "image/jpeg"); !errors.Is(err, domain.ErrInvalidInput) {
+				t.Errorf("Put(%q) error = %v, want ErrInvalidInput", key, err)
+			}
+			if _, err := store.Get(ctx, key); !errors.Is(err, domain.ErrInvalidInput) {
+				t.Errorf("Get(%q) error = %v, want ErrInvalidInput", key, err)
+			}
+		}
+	})
+}
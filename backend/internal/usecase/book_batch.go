@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+// Operações aceitas em um lote de livros
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// MaxBatchSize é a quantidade máxima de operações em um único lote
+const MaxBatchSize = 1000
+
+// ErrBatchAborted é atribuído às operações desfeitas porque outra operação do
+// mesmo lote atômico falhou
+var ErrBatchAborted = &domain.Error{Code: domain.CodeBatchAborted, Message: "operation rolled back because another operation in the batch failed"}
+
+// BookBatchOperation descreve uma operação de um lote. Update e delete exigem
+// a versão atual do livro.
+type BookBatchOperation struct {
+	Op      string
+	ID      string
+	Version int64
+	Book    *domain.Book
+}
+
+// BookBatchResult é o resultado de uma operação do lote, na mesma posição
+type BookBatchResult struct {
+	Op   string
+	ID   string
+	Book *domain.Book
+	Err  error
+}
+
+// ExecuteBatch executa um lote de operações. Em modo atômico todas as operações
+// rodam em uma única transação e qualquer falha desfaz o lote inteiro; caso
+// contrário cada operação é aplicada de forma independente. Erros por operação
+// são devolvidos nos resultados; o erro retornado indica falha do lote como um
+// todo.
+func (s *BookService) ExecuteBatch(ctx context.Context, ops []BookBatchOperation, atomic bool) (_ []BookBatchResult, err error) {
+	ctx, span := startSpan(ctx, "BookService.ExecuteBatch")
+	defer func() { endSpan(span, err) }()
+
+	if len(ops) == 0 || len(ops) > MaxBatchSize {
+		return nil, domain.NewValidationError("operations", fmt.Sprintf("a batch must have between 1 and %d operations", MaxBatchSize))
+	}
+
+	results := make([]BookBatchResult, len(ops))
+	now := time.Now()
+	valid := true
+	for i, op := range ops {
+		results[i] = BookBatchResult{Op: op.Op, ID: op.ID}
+		if err := prepareBatchOperation(&ops[i], now); err != nil {
+			results[i].Err = err
+			valid = false
+			continue
+		}
+		results[i].ID = ops[i].ID
+	}
+
+	if !atomic {
+		s.applyBatch(ctx, ops, results, false)
+		s.enqueueBatchCovers(results)
+		return results, nil
+	}
+
+	if !valid {
+		err := firstBatchError(results)
+		abortBatch(results)
+		return results, err
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Uma transação repetida depois de um conflito recomeça do zero
+		for i := range results {
+			results[i].Book, results[i].Err = nil, nil
+		}
+		if failed := s.applyBatch(ctx, ops, results, true); failed >= 0 {
+			return results[failed].Err
+		}
+		return nil
+	})
+	if err != nil {
+		abortBatch(results)
+		return results, err
+	}
+
+	s.enqueueBatchCovers(results)
+	return results, nil
+}
+
+// prepareBatchOperation valida a operação antes de tocar no banco
+func prepareBatchOperation(op *BookBatchOperation, now time.Time) error {
+	switch op.Op {
+	case BatchCreate:
+		if op.Book == nil {
+			return domain.NewValidationError("book", "book is required")
+		}
+		return prepareNewBook(op.Book, now)
+	case BatchUpdate:
+		if op.ID == "" {
+			return domain.NewValidationError("id", "id is required")
+		}
+		if op.Book == nil {
+			return domain.NewValidationError("book", "book is required")
+		}
+		if op.Version < 1 {
+			return domain.NewValidationError("version", "version is required")
+		}
+		op.Book.Version = op.Version
+		return nil
+	case BatchDelete:
+		if op.ID == "" {
+			return domain.NewValidationError("id", "id is required")
+		}
+		if op.Version < 1 {
+			return domain.NewValidationError("version", "version is required")
+		}
+		return nil
+	default:
+		return domain.NewValidationError("op", fmt.Sprintf("unknown operation %q", op.Op))
+	}
+}
+
+// applyBatch aplica as operações válidas em ordem, agrupando criações
+// consecutivas em um único CreateMany. Com stopOnError interrompe na primeira
+// falha e devolve seu índice; caso contrário devolve -1.
+func (s *BookService) applyBatch(ctx context.Context, ops []BookBatchOperation, results []BookBatchResult, stopOnError bool) int {
+	var pending []int
+
+	flush := func() int {
+		if len(pending) == 0 {
+			return -1
+		}
+		defer func() { pending = pending[:0] }()
+
+		books := make([]*domain.Book, len(pending))
+		for i, idx := range pending {
+			books[i] = ops[idx].Book
+		}
+
+		// O grupo é inserido em uma transação própria (ou na do lote atômico),
+		// para que uma falha não deixe parte dele gravada
+		err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			return s.bookRepo.CreateMany(ctx, books)
+		})
+		if err == nil {
+			for _, idx := range pending {
+				results[idx].Book = ops[idx].Book
+			}
+			return -1
+		}
+
+		if stopOnError {
+			results[pending[0]].Err = err
+			return pending[0]
+		}
+
+		// Fora de uma transação, identifica individualmente quais livros falharam
+		for _, idx := range pending {
+			if err := s.bookRepo.Create(ctx, ops[idx].Book); err != nil {
+				results[idx].Err = err
+				continue
+			}
+			results[idx].Book = ops[idx].Book
+		}
+		return -1
+	}
+
+	for i, op := range ops {
+		if results[i].Err != nil {
+			continue
+		}
+
+		if op.Op == BatchCreate {
+			pending = append(pending, i)
+			continue
+		}
+
+		if failed := flush(); failed >= 0 {
+			return failed
+		}
+
+		switch op.Op {
+		case BatchUpdate:
+			results[i].Book, results[i].Err = updateBook(ctx, s.bookRepo, op.ID, op.Book)
+		case BatchDelete:
+			results[i].Err = deleteBook(ctx, s.bookRepo, op.ID, op.Version)
+		}
+
+		if stopOnError && results[i].Err != nil {
+			return i
+		}
+	}
+
+	return flush()
+}
+
+// enqueueBatchCovers agenda as capas dos livros criados ou alterados no lote
+func (s *BookService) enqueueBatchCovers(results []BookBatchResult) {
+	for _, result := range results {
+		if result.Err == nil && result.Book != nil {
+			s.enqueueCover(result.Book)
+		}
+	}
+}
+
+// abortBatch marca como desfeitas as operações de um lote atômico que falhou
+func abortBatch(results []BookBatchResult) {
+	for i := range results {
+		results[i].Book = nil
+		if results[i].Err == nil {
+			results[i].Err = ErrBatchAborted
+		}
+	}
+}
+
+func firstBatchError(results []BookBatchResult) error {
+	for _, result := range results {
+		if result.Err != nil {
+			return result.Err
+		}
+	}
+	return nil
+}
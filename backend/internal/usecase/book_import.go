@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+)
+
+const (
+	// Livros gravados por vez durante uma importação
+	importBatchSize = 500
+	// Quantidade máxima de erros detalhados no relatório de importação
+	maxImportErrors = 1000
+)
+
+// BookReader é uma origem de livros para importação (CSV, MARC...)
+type BookReader interface {
+	// Next devolve a posição do registro na origem e o livro lido. io.EOF
+	// encerra a leitura; um *domain.RecordError descreve um registro ilegível
+	// e a leitura pode continuar.
+	Next() (int, *domain.Book, error)
+}
+
+// BookWriter é um destino de livros exportados
+type BookWriter interface {
+	Write(book *domain.Book) error
+	Flush() error
+}
+
+type ImportOptions struct {
+	// DryRun apenas valida o arquivo, sem gravar nada
+	DryRun bool
+}
+
+// ImportReport resume uma importação. Registros inválidos ou com ISBN
+// duplicado (no arquivo ou no catálogo) não são importados e aparecem em
+// Errors.
+type ImportReport struct {
+	DryRun          bool                 `json:"dry_run" example:"false"`
+	Total           int                  `json:"total" example:"300"`
+	Valid           int                  `json:"valid" example:"298"`
+	Imported        int                  `json:"imported" example:"298"`
+	Duplicates      int                  `json:"duplicates" example:"1"`
+	Invalid         int                  `json:"invalid" example:"1"`
+	Errors          []domain.RecordError `json:"errors"`
+	ErrorsTruncated bool                 `json:"errors_truncated" example:"false"`
+}
+
+func (r *ImportReport) addError(recordErr domain.RecordError) {
+	if len(r.Errors) >= maxImportErrors {
+		r.ErrorsTruncated = true
+		return
+	}
+	r.Errors = append(r.Errors, recordErr)
+}
+
+type importCandidate struct {
+	position int
+	book     *domain.Book
+}
+
+// ImportBooks lê todos os registros da origem, valida cada um com as mesmas
+// regras de CreateBook, descarta ISBNs duplicados e grava os válidos em lotes.
+// A origem é consumida em fluxo, sem carregar o arquivo inteiro em memória.
+// Cada lote é gravado em uma transação própria: em caso de erro, os lotes
+// anteriores permanecem e o relatório devolvido junto com o erro os descreve.
+func (s *BookService) ImportBooks(ctx context.Context, reader BookReader, opts ImportOptions) (_ *ImportReport, err error) {
+	ctx, span := startSpan(ctx, "BookService.ImportBooks")
+	defer func() { endSpan(span, err) }()
+
+	report := &ImportReport{DryRun: opts.DryRun, Errors: []domain.RecordError{}}
+	// Duplicatas do catálogo só são detectadas no fim de cada lote
+	defer func() {
+		sort.SliceStable(report.Errors, func(i, j int) bool {
+			return report.Errors[i].Position < report.Errors[j].Position
+		})
+	}()
+	seen := make(map[string]int)
+	batch := make([]importCandidate, 0, importBatchSize)
+
+	for {
+		position, book, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var recordErr *domain.RecordError
+		if errors.As(err, &recordErr) {
+			report.Total++
+			report.Invalid++
+			report.addError(*recordErr)
+			continue
+		}
+		if err != nil {
+			return report, err
+		}
+
+		report.Total++
+
+		if err := prepareNewBook(book, time.Now()); err != nil {
+			var validation *domain.ValidationError
+			if !errors.As(err, &validation) {
+				return report, err
+			}
+			report.Invalid++
+			for _, field := range validation.Fields {
+				report.addError(domain.RecordError{Position: position, Field: field.Field, Message: field.Message})
+			}
+			continue
+		}
+
+		if book.ISBN != "" {
+			if first, ok := seen[book.ISBN]; ok {
+				report.Duplicates++
+				report.addError(domain.RecordError{Position: position, Field: "isbn",
+					Message: fmt.Sprintf("duplicate ISBN %s in file, first seen at record %d", book.ISBN, first)})
+				continue
+			}
+			seen[book.ISBN] = position
+		}
+
+		batch = append(batch, importCandidate{position: position, book: book})
+		if len(batch) == importBatchSize {
+			if err := s.importBatch(ctx, batch, report, opts); err != nil {
+				return report, err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if err := s.importBatch(ctx, batch, report, opts); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// importBatch descarta os ISBNs já cadastrados e grava o restante do lote
+func (s *BookService) importBatch(ctx context.Context, batch []importCandidate, report *ImportReport, opts ImportOptions) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	isbns := make([]string, 0, len(batch))
+	for _, candidate := range batch {
+		if candidate.book.ISBN != "" {
+			isbns = append(isbns, candidate.book.ISBN)
+		}
+	}
+
+	existing, err := s.bookRepo.ExistingISBNs(ctx, isbns)
+	if err != nil {
+		return err
+	}
+	inCatalog := make(map[string]bool, len(existing))
+	for _, isbn := range existing {
+		inCatalog[isbn] = true
+	}
+
+	books := make([]*domain.Book, 0, len(batch))
+	for _, candidate := range batch {
+		if inCatalog[candidate.book.ISBN] {
+			report.Duplicates++
+			report.addError(domain.RecordError{Position: candidate.position, Field: "isbn",
+				Message: fmt.Sprintf("ISBN %s is already in the catalog", candidate.book.ISBN)})
+			continue
+		}
+		books = append(books, candidate.book)
+	}
+
+	report.Valid += len(books)
+	if opts.DryRun || len(books) == 0 {
+		return nil
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		return s.bookRepo.CreateMany(ctx, books)
+	})
+	if err != nil {
+		return err
+	}
+
+	report.Imported += len(books)
+	for _, book := range books {
+		s.enqueueCover(book)
+	}
+	return nil
+}
+
+// ExportBooks escreve os livros na mesma ordem e paginação de ListBooks. Com
+// page zero todo o catálogo é exportado.
+func (s *BookService) ExportBooks(ctx context.Context, writer BookWriter, page, pageSize int) (err error) {
+	ctx, span := startSpan(ctx, "BookService.ExportBooks")
+	defer func() { endSpan(span, err) }()
+
+	limit, offset := 0, 0
+	if page > 0 {
+		if pageSize < 1 || pageSize > 100 {
+			pageSize = 10
+		}
+		limit = pageSize
+		offset = (page - 1) * pageSize
+	}
+
+	if err := s.bookRepo.Iterate(ctx, limit, offset, writer.Write); err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository/memory"
+)
+
+// sliceReader devolve os livros em ordem, com posições a partir de 2 como as
+// linhas de um CSV com cabeçalho
+type sliceReader struct {
+	books []*domain.Book
+	next  int
+}
+
+func (r *sliceReader) Next() (int, *domain.Book, error) {
+	if r.next == len(r.books) {
+		return 0, nil, io.EOF
+	}
+	r.next++
+	return r.next + 1, r.books[r.next-1], nil
+}
+
+func TestImportBooksErrorOrder(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewBookRepository()
+	service := NewBookService(repo, memory.NewTxManager(repo), nil)
+
+	existing := &domain.Book{Title: "O Hobbit", Author: "J.R.R. Tolkien", ISBN: "9788595084742"}
+	if err := service.CreateBook(ctx, existing); err != nil {
+		t.Fatalf("CreateBook() error = %v", err)
+	}
+
+	// A duplicata do catálogo só é detectada no fim do lote, depois do
+	// registro inválido que vem depois dela no arquivo
+	reader := &sliceReader{books: []*domain.Book{
+		{Title: "Dom Casmurro", Author: "Machado de Assis"},
+		{Title: "O Hobbit", Author: "J.R.R. Tolkien", ISBN: "978-85-9508-474-2"},
+		{Title: "Sem autor"},
+	}}
+	report, err := service.ImportBooks(ctx, reader, ImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportBooks() error = %v", err)
+	}
+
+	if report.Imported != 1 || report.Duplicates != 1 || report.Invalid != 1 {
+		t.Errorf("report = %+v, want 1 imported, 1 duplicate and 1 invalid", report)
+	}
+	if len(report.Errors) != 2 || report.Errors[0].Position != 3 || report.Errors[1].Position != 4 {
+		t.Errorf("errors = %+v, want positions 3 and 4 in order", report.Errors)
+	}
+}
@@ -2,29 +2,50 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
 	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
 )
 
+// CoverQueue agenda a geração das versões redimensionadas da capa de um livro
+type CoverQueue interface {
+	Enqueue(bookID string)
+}
+
 type BookService struct {
 	bookRepo repository.BookRepository
+	tx       repository.TxManager
+	covers   CoverQueue
 }
 
-func NewBookService(bookRepo repository.BookRepository) *BookService {
+// NewBookService cria o serviço de livros. tx coordena as operações que
+// gravam vários livros de uma vez; covers recebe os livros cuja capa mudou e
+// pode ser nil.
+func NewBookService(bookRepo repository.BookRepository, tx repository.TxManager, covers CoverQueue) *BookService {
 	return &BookService{
 		bookRepo: bookRepo,
+		tx:       tx,
+		covers:   covers,
 	}
 }
 
-func (s *BookService) GetBook(ctx context.Context, id string) (*domain.Book, error) {
+func (s *BookService) GetBook(ctx context.Context, id string) (_ *domain.Book, err error) {
+	ctx, span := startSpan(ctx, "BookService.GetBook", attribute.String("book.id", id))
+	defer func() { endSpan(span, err) }()
+
 	return s.bookRepo.FindByID(ctx, id)
 }
 
-func (s *BookService) ListBooks(ctx context.Context, page, pageSize int) ([]*domain.Book, error) {
+func (s *BookService) ListBooks(ctx context.Context, page, pageSize int) (_ []*domain.Book, err error) {
+	ctx, span := startSpan(ctx, "BookService.ListBooks")
+	defer func() { endSpan(span, err) }()
+
 	if page < 1 {
 		page = 1
 	}
@@ -36,38 +57,192 @@ func (s *BookService) ListBooks(ctx context.Context, page, pageSize int) ([]*dom
 	return s.bookRepo.FindAll(ctx, pageSize, offset)
 }
 
-func (s *BookService) CreateBook(ctx context.Context, book *domain.Book) error {
-	if book.Title == "" || book.Author == "" {
-		return domain.ErrInvalidInput
+// FindBooksByISBN busca os livros pelo ISBN, aceito como ISBN-10 ou ISBN-13,
+// com ou sem hífens
+func (s *BookService) FindBooksByISBN(ctx context.Context, value string) (_ domain.ISBN, _ []*domain.Book, err error) {
+	ctx, span := startSpan(ctx, "BookService.FindBooksByISBN")
+	defer func() { endSpan(span, err) }()
+
+	isbn, err := domain.ParseISBN(value)
+	if err != nil {
+		return "", nil, err
 	}
 
-	book.ID = uuid.New().String()
-	now := time.Now()
-	book.CreatedAt = now
-	book.UpdatedAt = now
+	books, err := s.bookRepo.FindByISBN(ctx, isbn)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return isbn, books, nil
+}
+
+func (s *BookService) CreateBook(ctx context.Context, book *domain.Book) (err error) {
+	ctx, span := startSpan(ctx, "BookService.CreateBook")
+	defer func() { endSpan(span, err) }()
+
+	if err := prepareNewBook(book, time.Now()); err != nil {
+		return err
+	}
+
+	if err := s.bookRepo.Create(ctx, book); err != nil {
+		return err
+	}
+
+	s.enqueueCover(book)
+	return nil
+}
+
+// UpdateBook substitui os dados do livro. Capa, status, editora, ano e
+// assuntos vazios mantêm os valores atuais. book.Version deve conter a versão
+// conhecida pelo cliente; zero ignora a verificação.
+func (s *BookService) UpdateBook(ctx context.Context, id string, book *domain.Book) (err error) {
+	ctx, span := startSpan(ctx, "BookService.UpdateBook", attribute.String("book.id", id))
+	defer func() { endSpan(span, err) }()
+
+	updated, err := updateBook(ctx, s.bookRepo, id, book)
+	if err != nil {
+		return err
+	}
+
+	s.enqueueCover(updated)
+	return nil
+}
+
+// PatchBook aplica uma alteração parcial sobre o livro existente e valida o
+// resultado antes de persistir. version zero ignora a verificação de versão.
+func (s *BookService) PatchBook(ctx context.Context, id string, version int64, apply func(book *domain.Book) error) (_ *domain.Book, err error) {
+	ctx, span := startSpan(ctx, "BookService.PatchBook", attribute.String("book.id", id))
+	defer func() { endSpan(span, err) }()
+
+	book, err := s.bookRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != 0 && version != book.Version {
+		return nil, domain.ErrVersionConflict
+	}
+
+	current := book.Version
+	if err := apply(book); err != nil {
+		return nil, err
+	}
+
+	book.ID = id
+	book.Version = current
+	if err := validateBook(book); err != nil {
+		return nil, err
+	}
+
+	book.UpdatedAt = time.Now()
+
+	if err := s.bookRepo.Update(ctx, book); err != nil {
+		return nil, err
+	}
 
+	s.enqueueCover(book)
+	return book, nil
+}
+
+// DeleteBook remove o livro se version ainda for a versão atual; zero remove
+// a versão corrente
+func (s *BookService) DeleteBook(ctx context.Context, id string, version int64) (err error) {
+	ctx, span := startSpan(ctx, "BookService.DeleteBook", attribute.String("book.id", id))
+	defer func() { endSpan(span, err) }()
+
+	return deleteBook(ctx, s.bookRepo, id, version)
+}
+
+// enqueueCover agenda a geração das versões da capa se elas não
+// corresponderem mais à capa atual do livro
+func (s *BookService) enqueueCover(book *domain.Book) {
+	if s.covers != nil && book.Covers.Source != book.CoverURL {
+		s.covers.Enqueue(book.ID)
+	}
+}
+
+// prepareNewBook valida um livro novo e preenche ID, datas e status padrão
+func prepareNewBook(book *domain.Book, now time.Time) error {
 	if book.Status == "" {
 		book.Status = domain.StatusAvailable
 	}
 
-	return s.bookRepo.Create(ctx, book)
+	if err := validateBook(book); err != nil {
+		return err
+	}
+
+	book.ID = uuid.New().String()
+	book.Covers = domain.CoverVariants{}
+	book.CreatedAt = now
+	book.UpdatedAt = now
+	book.Version = 0
+
+	return nil
 }
 
-func (s *BookService) UpdateBook(ctx context.Context, id string, book *domain.Book) error {
-	if book.Title == "" || book.Author == "" {
-		return domain.ErrInvalidInput
+// normalizeISBN valida o ISBN do livro, se houver, e o grava como ISBN-13 sem hífens
+func normalizeISBN(book *domain.Book) error {
+	book.ISBN = strings.TrimSpace(book.ISBN)
+	if book.ISBN == "" {
+		return nil
 	}
 
-	existingBook, err := s.bookRepo.FindByID(ctx, id)
+	isbn, err := domain.ParseISBN(book.ISBN)
 	if err != nil {
 		return err
 	}
 
+	book.ISBN = isbn.String()
+	return nil
+}
+
+// validateBook normaliza o ISBN e valida o livro, reunindo em um
+// *domain.ValidationError os problemas de todos os campos
+func validateBook(book *domain.Book) error {
+	validation := &domain.ValidationError{}
+	if err := normalizeISBN(book); err != nil {
+		validation.Add("isbn", err.Error())
+	}
+
+	var bookErr *domain.ValidationError
+	if err := book.Validate(); errors.As(err, &bookErr) {
+		validation.Fields = append(validation.Fields, bookErr.Fields...)
+	} else if err != nil {
+		return err
+	}
+
+	return validation.Err()
+}
+
+func updateBook(ctx context.Context, bookRepo repository.BookRepository, id string, book *domain.Book) (*domain.Book, error) {
+	existingBook, err := bookRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if book.Version != 0 && book.Version != existingBook.Version {
+		return nil, domain.ErrVersionConflict
+	}
+
 	existingBook.Title = book.Title
 	existingBook.Author = book.Author
 	existingBook.ISBN = book.ISBN
 	existingBook.Description = book.Description
 
+	// Campos bibliográficos omitidos são mantidos, como a capa e o status;
+	// para limpá-los, use PatchBook
+	if book.Publisher != "" {
+		existingBook.Publisher = book.Publisher
+	}
+
+	if book.PublicationYear != 0 {
+		existingBook.PublicationYear = book.PublicationYear
+	}
+
+	if book.Subjects != nil {
+		existingBook.SetSubjects(book.Subjects)
+	}
+
 	if book.CoverURL != "" {
 		existingBook.CoverURL = book.CoverURL
 	}
@@ -76,11 +251,27 @@ func (s *BookService) UpdateBook(ctx context.Context, id string, book *domain.Bo
 		existingBook.Status = book.Status
 	}
 
+	if err := validateBook(existingBook); err != nil {
+		return nil, err
+	}
+
 	existingBook.UpdatedAt = time.Now()
 
-	return s.bookRepo.Update(ctx, existingBook)
+	if err := bookRepo.Update(ctx, existingBook); err != nil {
+		return nil, err
+	}
+
+	return existingBook, nil
 }
 
-func (s *BookService) DeleteBook(ctx context.Context, id string) error {
-	return s.bookRepo.Delete(ctx, id)
+func deleteBook(ctx context.Context, bookRepo repository.BookRepository, id string, version int64) error {
+	if version == 0 {
+		book, err := bookRepo.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		version = book.Version
+	}
+
+	return bookRepo.Delete(ctx, id, version)
 }
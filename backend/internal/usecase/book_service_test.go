@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository/memory"
+)
+
+func TestBookServiceUpdateBookKeepsOmittedFields(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewBookRepository()
+	service := NewBookService(repo, memory.NewTxManager(repo), nil)
+
+	book := &domain.Book{
+		Title:           "Dom Casmurro",
+		Author:          "Assis, Machado de",
+		Publisher:       "Garnier",
+		PublicationYear: 1899,
+		Subjects:        domain.StringList{"Ficção brasileira", "Rio de Janeiro (RJ) -- Ficção"},
+		SubjectCodes:    domain.StringList{"", "v"},
+	}
+	if err := service.CreateBook(ctx, book); err != nil {
+		t.Fatalf("CreateBook() error = %v", err)
+	}
+
+	if err := service.UpdateBook(ctx, book.ID, &domain.Book{Title: "Dom Casmurro", Author: "Machado de Assis"}); err != nil {
+		t.Fatalf("UpdateBook() error = %v", err)
+	}
+	got, _ := service.GetBook(ctx, book.ID)
+	if got.Author != "Machado de Assis" || got.Publisher != "Garnier" || got.PublicationYear != 1899 ||
+		fmt.Sprint(got.Subjects) != fmt.Sprint(book.Subjects) || fmt.Sprint(got.SubjectCodes) != fmt.Sprint(book.SubjectCodes) {
+		t.Errorf("UpdateBook() without publisher, year and subjects = %+v, want them kept", got)
+	}
+
+	update := &domain.Book{Title: "Dom Casmurro", Author: "Machado de Assis", Subjects: domain.StringList{"Rio de Janeiro (RJ) -- Ficção"}}
+	if err := service.UpdateBook(ctx, book.ID, update); err != nil {
+		t.Fatalf("UpdateBook() error = %v", err)
+	}
+	got, _ = service.GetBook(ctx, book.ID)
+	if fmt.Sprint(got.Subjects) != "[Rio de Janeiro (RJ) -- Ficção]" || fmt.Sprint(got.SubjectCodes) != "[v]" {
+		t.Errorf("UpdateBook() subjects = %v, codes = %v; want the remaining subject with its code", got.Subjects, got.SubjectCodes)
+	}
+}
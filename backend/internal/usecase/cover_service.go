@@ -0,0 +1,311 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/imaging"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/storage"
+)
+
+// Prefixo das chaves das capas no BlobStore
+const coverKeyPrefix = "covers/"
+
+// Caixas em que cada versão redimensionada da capa deve caber
+var (
+	thumbnailSize = imaging.Size{Width: 200, Height: 300}
+	mediumSize    = imaging.Size{Width: 400, Height: 600}
+	largeSize     = imaging.Size{Width: 800, Height: 1200}
+)
+
+// CoverFetcher baixa capas hospedadas fora da aplicação
+type CoverFetcher interface {
+	Fetch(ctx context.Context, url string, maxSize int64) ([]byte, error)
+}
+
+type CoverService struct {
+	bookRepo repository.BookRepository
+	store    storage.BlobStore
+	fetcher  CoverFetcher
+	covers   CoverQueue
+	maxSize  int64
+	baseURL  string
+}
+
+// NewCoverService cria o serviço de capas. baseURL é o endereço público da
+// rota que serve as capas (ex.: http://localhost:8080/api/covers) e é usado
+// para montar o cover_url dos livros. covers recebe os livros cuja capa foi
+// enviada e pode ser nil.
+func NewCoverService(bookRepo repository.BookRepository, store storage.BlobStore, fetcher CoverFetcher, covers CoverQueue, maxSize int64, baseURL string) *CoverService {
+	return &CoverService{
+		bookRepo: bookRepo,
+		store:    store,
+		fetcher:  fetcher,
+		covers:   covers,
+		maxSize:  maxSize,
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// UploadCover armazena a imagem e a torna a capa do livro. O tipo é
+// identificado pelo conteúdo, não pelo nome ou cabeçalho enviado. version
+// zero ignora a verificação de versão. A capa anterior, se também tiver sido
+// enviada por upload, é removida.
+func (s *CoverService) UploadCover(ctx context.Context, id string, version int64, image io.Reader) (_ *domain.Book, err error) {
+	ctx, span := startSpan(ctx, "CoverService.UploadCover", attribute.String("book.id", id))
+	defer func() { endSpan(span, err) }()
+
+	data, err := io.ReadAll(io.LimitReader(image, s.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.maxSize {
+		return nil, domain.ErrCoverTooLarge
+	}
+	if len(data) == 0 {
+		return nil, domain.NewValidationError("cover", "cover image is empty")
+	}
+
+	contentType := http.DetectContentType(data)
+	extension, ok := domain.CoverExtensions[contentType]
+	if !ok {
+		return nil, domain.ErrUnsupportedCover
+	}
+
+	book, err := s.bookRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != book.Version {
+		return nil, domain.ErrVersionConflict
+	}
+
+	// O nome muda junto com o conteúdo, então a imagem servida nunca muda
+	// e pode ficar em cache indefinidamente
+	sum := sha256.Sum256(data)
+	name := id + "-" + hex.EncodeToString(sum[:8]) + extension
+	existed, err := s.blobExists(ctx, coverKeyPrefix+name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Put(ctx, coverKeyPrefix+name, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return nil, err
+	}
+
+	previous := s.storedCover(book.CoverURL)
+	book.CoverURL = s.baseURL + "/" + name
+	book.UpdatedAt = time.Now()
+
+	if err := s.bookRepo.Update(ctx, book); err != nil {
+		if !existed {
+			s.discardCover(ctx, id, name)
+		}
+		return nil, err
+	}
+
+	if previous != "" && previous != name {
+		// Falhar aqui só deixa um arquivo órfão; a capa nova já foi salva
+		s.store.Delete(ctx, coverKeyPrefix+previous)
+	}
+
+	if s.covers != nil {
+		s.covers.Enqueue(book.ID)
+	}
+	return book, nil
+}
+
+// GetCover abre a imagem de uma capa enviada por upload
+func (s *CoverService) GetCover(ctx context.Context, name string) (_ *storage.Blob, err error) {
+	ctx, span := startSpan(ctx, "CoverService.GetCover")
+	defer func() { endSpan(span, err) }()
+
+	if name == "" || strings.Contains(name, "/") {
+		return nil, domain.ErrBlobNotFound
+	}
+
+	return s.store.Get(ctx, coverKeyPrefix+name)
+}
+
+// blobExists informa se a chave já existe no BlobStore
+func (s *CoverService) blobExists(ctx context.Context, key string) (bool, error) {
+	blob, err := s.store.Get(ctx, key)
+	if errors.Is(err, domain.ErrBlobNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	blob.Body.Close()
+	return true, nil
+}
+
+// discardCover remove a capa name, gravada por um upload que não conseguiu
+// atualizar o livro. Um upload simultâneo da mesma imagem grava o mesmo nome
+// e pode ter vencido a disputa pela versão, então a capa só é removida se o
+// livro não apontar para ela; na dúvida, o arquivo fica órfão.
+func (s *CoverService) discardCover(ctx context.Context, id, name string) {
+	book, err := s.bookRepo.FindByID(ctx, id)
+	if err != nil || s.storedCover(book.CoverURL) == name {
+		return
+	}
+	s.store.Delete(ctx, coverKeyPrefix+name)
+}
+
+// storedCover devolve o nome da capa armazenada a que coverURL aponta, ou ""
+// se for uma URL externa
+func (s *CoverService) storedCover(coverURL string) string {
+	name, ok := strings.CutPrefix(coverURL, s.baseURL+"/")
+	if !ok || name == "" || path.Base(name) != name {
+		return ""
+	}
+	return name
+}
+
+// GenerateVariants gera as versões redimensionadas da capa atual do livro,
+// se ainda não existirem, e remove as da capa anterior. Erros que não se
+// resolvem sem trocar a capa (imagem inválida, link quebrado) ficam
+// registrados no livro em vez de serem devolvidos, para não serem repetidos.
+func (s *CoverService) GenerateVariants(ctx context.Context, id string) (err error) {
+	ctx, span := startSpan(ctx, "CoverService.GenerateVariants", attribute.String("book.id", id))
+	defer func() { endSpan(span, err) }()
+
+	book, err := s.bookRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrBookNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if book.Covers.Source == book.CoverURL {
+		return nil
+	}
+
+	variants := domain.CoverVariants{Source: book.CoverURL}
+	if book.CoverURL != "" {
+		generated, err := s.resizeCover(ctx, book.ID, book.CoverURL)
+		switch {
+		case err == nil:
+			variants = *generated
+		case errors.Is(err, imaging.ErrUnsupportedImage), errors.Is(err, imaging.ErrInvalidSource),
+			errors.Is(err, domain.ErrBlobNotFound):
+			variants.Error = err.Error()
+		default:
+			return err
+		}
+	}
+
+	if err := s.bookRepo.UpdateCoverVariants(ctx, book.ID, variants); err != nil {
+		// A capa mudou ou o livro foi removido durante o processamento
+		s.deleteVariants(ctx, variants, book.Covers)
+		if errors.Is(err, domain.ErrVersionConflict) || errors.Is(err, domain.ErrBookNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	s.deleteVariants(ctx, book.Covers, variants)
+
+	// Uma capa enviada que deixou de ser usada (cover_url alterado) também
+	// é removida
+	if previous := s.storedCover(book.Covers.Source); previous != "" && book.Covers.Source != book.CoverURL {
+		s.store.Delete(ctx, coverKeyPrefix+previous)
+	}
+	return nil
+}
+
+// PendingCovers devolve os IDs de até limit livros cujas versões da capa
+// precisam ser geradas ou removidas
+func (s *CoverService) PendingCovers(ctx context.Context, limit int) (_ []string, err error) {
+	ctx, span := startSpan(ctx, "CoverService.PendingCovers")
+	defer func() { endSpan(span, err) }()
+
+	return s.bookRepo.FindStaleCovers(ctx, limit)
+}
+
+// resizeCover lê a capa e grava suas versões. Os nomes incluem um hash do
+// conteúdo original, como no upload, para que possam ficar em cache.
+func (s *CoverService) resizeCover(ctx context.Context, id, coverURL string) (*domain.CoverVariants, error) {
+	data, err := s.readCover(ctx, coverURL)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := imaging.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	prefix := id + "-" + hex.EncodeToString(sum[:8])
+
+	// Cada versão é reduzida a partir da anterior, maior, o que é bem mais
+	// rápido do que partir sempre do original
+	variants := &domain.CoverVariants{Source: coverURL}
+	for _, variant := range []struct {
+		name string
+		size imaging.Size
+		url  *string
+	}{
+		{"large", largeSize, &variants.Large},
+		{"medium", mediumSize, &variants.Medium},
+		{"thumbnail", thumbnailSize, &variants.Thumbnail},
+	} {
+		img = imaging.Resize(img, variant.size)
+		encoded, err := imaging.Encode(img)
+		if err != nil {
+			return nil, err
+		}
+
+		name := prefix + "-" + variant.name + encoded.Extension
+		if err := s.store.Put(ctx, coverKeyPrefix+name, bytes.NewReader(encoded.Data), int64(len(encoded.Data)), encoded.ContentType); err != nil {
+			return nil, err
+		}
+		*variant.url = s.baseURL + "/" + name
+	}
+
+	return variants, nil
+}
+
+// readCover lê do BlobStore as capas enviadas por upload e baixa as demais
+func (s *CoverService) readCover(ctx context.Context, coverURL string) ([]byte, error) {
+	name := s.storedCover(coverURL)
+	if name == "" {
+		return s.fetcher.Fetch(ctx, coverURL, s.maxSize)
+	}
+
+	blob, err := s.store.Get(ctx, coverKeyPrefix+name)
+	if err != nil {
+		return nil, err
+	}
+	defer blob.Body.Close()
+
+	return io.ReadAll(io.LimitReader(blob.Body, s.maxSize))
+}
+
+// deleteVariants remove os arquivos de old que não fazem parte de keep
+func (s *CoverService) deleteVariants(ctx context.Context, old, keep domain.CoverVariants) {
+	kept := make(map[string]bool)
+	for _, url := range keep.URLs() {
+		kept[url] = true
+	}
+
+	for _, url := range old.URLs() {
+		if name := s.storedCover(url); name != "" && !kept[url] {
+			// Falhar aqui só deixa um arquivo órfão
+			s.store.Delete(ctx, coverKeyPrefix+name)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository/memory"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/storage"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/storage/local"
+)
+
+const coverBaseURL = "http://localhost:8080/api/covers"
+
+// pngCover é suficiente para http.DetectContentType reconhecer um PNG
+var pngCover = "\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 32)
+
+// racingStore executa race uma única vez, logo depois da primeira consulta a
+// uma chave, como uma requisição concorrente que chega nesse momento
+type racingStore struct {
+	storage.BlobStore
+	race func()
+}
+
+func (s *racingStore) Get(ctx context.Context, key string) (*storage.Blob, error) {
+	blob, err := s.BlobStore.Get(ctx, key)
+	if race := s.race; race != nil {
+		s.race = nil
+		race()
+	}
+	return blob, err
+}
+
+func newCoverService(t *testing.T) (*CoverService, repository.BookRepository, *racingStore, *domain.Book) {
+	t.Helper()
+	dir, err := local.NewBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &racingStore{BlobStore: dir}
+	books := memory.NewBookRepository()
+	service := NewCoverService(books, store, nil, nil, 1<<20, coverBaseURL)
+
+	book := &domain.Book{ID: "book", Title: "Livro", Author: "Autor", Status: domain.StatusAvailable}
+	if err := books.Create(context.Background(), book); err != nil {
+		t.Fatal(err)
+	}
+	return service, books, store, book
+}
+
+func TestCoverServiceConcurrentSameUpload(t *testing.T) {
+	ctx := context.Background()
+	service, books, store, book := newCoverService(t)
+
+	// As duas requisições enviam a mesma imagem para a mesma versão do livro;
+	// a concorrente vence e a primeira recebe o conflito de versão
+	store.race = func() {
+		if _, err := service.UploadCover(ctx, book.ID, book.Version, strings.NewReader(pngCover)); err != nil {
+			t.Errorf("concurrent UploadCover() error = %v", err)
+		}
+	}
+	_, err := service.UploadCover(ctx, book.ID, book.Version, strings.NewReader(pngCover))
+	if !errors.Is(err, domain.ErrVersionConflict) {
+		t.Fatalf("UploadCover() error = %v, want ErrVersionConflict", err)
+	}
+
+	got, _ := books.FindByID(ctx, book.ID)
+	name := service.storedCover(got.CoverURL)
+	if name == "" {
+		t.Fatalf("cover_url = %q, want the uploaded cover", got.CoverURL)
+	}
+	blob, err := service.GetCover(ctx, name)
+	if err != nil {
+		t.Fatalf("GetCover() for the committed cover error = %v", err)
+	}
+	blob.Body.Close()
+}
+
+func TestCoverServiceDiscardsUnusedUpload(t *testing.T) {
+	ctx := context.Background()
+	service, books, store, book := newCoverService(t)
+
+	// Outra requisição altera o livro durante o upload
+	store.race = func() {
+		update := *book
+		update.Title = "Outro título"
+		if err := books.Update(ctx, &update); err != nil {
+			t.Errorf("Update() error = %v", err)
+		}
+	}
+	_, err := service.UploadCover(ctx, book.ID, book.Version, strings.NewReader(pngCover))
+	if !errors.Is(err, domain.ErrVersionConflict) {
+		t.Fatalf("UploadCover() error = %v, want ErrVersionConflict", err)
+	}
+
+	// A capa gravada por este upload e que nenhum livro usa é removida
+	sum := sha256.Sum256([]byte(pngCover))
+	name := book.ID + "-" + hex.EncodeToString(sum[:8]) + ".png"
+	if _, err := service.GetCover(ctx, name); !errors.Is(err, domain.ErrBlobNotFound) {
+		t.Errorf("GetCover() after a failed upload error = %v, want ErrBlobNotFound", err)
+	}
+}
+
+func TestCoverServiceReplacesPreviousUpload(t *testing.T) {
+	ctx := context.Background()
+	service, _, _, book := newCoverService(t)
+
+	first, err := service.UploadCover(ctx, book.ID, 0, strings.NewReader(pngCover))
+	if err != nil {
+		t.Fatalf("UploadCover() error = %v", err)
+	}
+	previous := service.storedCover(first.CoverURL)
+
+	second, err := service.UploadCover(ctx, book.ID, first.Version, strings.NewReader(pngCover+"\x01"))
+	if err != nil {
+		t.Fatalf("UploadCover() error = %v", err)
+	}
+	if second.CoverURL == first.CoverURL {
+		t.Fatalf("cover_url = %q, want a new name for new content", second.CoverURL)
+	}
+	if _, err := service.GetCover(ctx, previous); !errors.Is(err, domain.ErrBlobNotFound) {
+		t.Errorf("GetCover() of the replaced cover error = %v, want ErrBlobNotFound", err)
+	}
+}
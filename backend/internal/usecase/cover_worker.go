@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Quantidade de livros pendentes buscados por vez na varredura
+const coverSweepBatchSize = 100
+
+// CoverWorker gera as versões das capas em segundo plano, para que criar ou
+// alterar um livro não espere pelo download e redimensionamento da imagem.
+// Além da fila, uma varredura periódica encontra capas que ficaram para trás
+// (fila cheia, reinício do servidor, falhas temporárias).
+type CoverWorker struct {
+	queue    chan string
+	interval time.Duration
+}
+
+// NewCoverWorker cria o worker; as capas só são processadas após Run. O
+// worker é criado antes do CoverService, que também agenda capas nele.
+func NewCoverWorker(queueSize int, interval time.Duration) *CoverWorker {
+	return &CoverWorker{
+		queue:    make(chan string, queueSize),
+		interval: interval,
+	}
+}
+
+// Enqueue agenda o livro sem bloquear. Com a fila cheia o livro fica para a
+// próxima varredura.
+func (w *CoverWorker) Enqueue(bookID string) {
+	select {
+	case w.queue <- bookID:
+	default:
+	}
+}
+
+// Run processa a fila até ctx ser cancelado. As capas são processadas uma de
+// cada vez, para limitar o uso de CPU e memória do redimensionamento.
+func (w *CoverWorker) Run(ctx context.Context, coverService *CoverService) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.sweep(ctx, coverService)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-w.queue:
+			w.process(ctx, coverService, id)
+		case <-ticker.C:
+			w.sweep(ctx, coverService)
+		}
+	}
+}
+
+func (w *CoverWorker) process(ctx context.Context, coverService *CoverService, id string) bool {
+	if err := coverService.GenerateVariants(ctx, id); err != nil {
+		if ctx.Err() == nil {
+			slog.Error("Failed to generate cover variants", "book_id", id, "error", err)
+		}
+		return false
+	}
+	return true
+}
+
+// sweep processa os livros cujas versões da capa estão desatualizadas, até
+// não haver mais nenhum ou todos os restantes falharem
+func (w *CoverWorker) sweep(ctx context.Context, coverService *CoverService) {
+	for ctx.Err() == nil {
+		ids, err := coverService.PendingCovers(ctx, coverSweepBatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("Failed to find pending cover variants", "error", err)
+			}
+			return
+		}
+
+		processed := 0
+		for _, id := range ids {
+			if w.process(ctx, coverService, id) {
+				processed++
+			}
+		}
+
+		if len(ids) < coverSweepBatchSize || processed == 0 {
+			return
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
+)
+
+type IdempotencyService struct {
+	idempotencyRepo repository.IdempotencyRepository
+	ttl             time.Duration
+	lockTimeout     time.Duration
+}
+
+// NewIdempotencyService guarda as respostas por ttl. Uma requisição que não
+// termina em lockTimeout perde a chave para a próxima tentativa.
+func NewIdempotencyService(idempotencyRepo repository.IdempotencyRepository, ttl, lockTimeout time.Duration) *IdempotencyService {
+	return &IdempotencyService{
+		idempotencyRepo: idempotencyRepo,
+		ttl:             ttl,
+		lockTimeout:     lockTimeout,
+	}
+}
+
+// Begin reserva a chave para a requisição identificada por fingerprint. Retorna
+// o registro concluído a ser repetido para o cliente ou, quando a requisição
+// deve ser processada, a reserva feita (Completed false), que deve ser passada
+// a Complete ou Release.
+func (s *IdempotencyService) Begin(ctx context.Context, key, fingerprint string) (_ *domain.IdempotencyRecord, err error) {
+	ctx, span := startSpan(ctx, "IdempotencyService.Begin")
+	defer func() { endSpan(span, err) }()
+
+	now := time.Now()
+	record := &domain.IdempotencyRecord{
+		Key:         key,
+		Fingerprint: fingerprint,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.ttl),
+		LockedUntil: now.Add(s.lockTimeout),
+	}
+
+	existing, err := s.idempotencyRepo.Acquire(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing == nil {
+		return record, nil
+	}
+
+	if existing.Fingerprint != fingerprint {
+		return nil, domain.ErrIdempotencyKeyReused
+	}
+
+	if !existing.Completed {
+		return nil, domain.ErrIdempotencyKeyInProgress
+	}
+
+	return existing, nil
+}
+
+// Complete armazena a resposta da requisição para futuras repetições. Nada é
+// gravado se a reserva venceu e passou para outra tentativa.
+func (s *IdempotencyService) Complete(ctx context.Context, lease *domain.IdempotencyRecord, statusCode int, headers map[string]string, body []byte) (err error) {
+	ctx, span := startSpan(ctx, "IdempotencyService.Complete")
+	defer func() { endSpan(span, err) }()
+
+	return s.idempotencyRepo.Complete(ctx, &domain.IdempotencyRecord{
+		Key:         lease.Key,
+		Completed:   true,
+		StatusCode:  statusCode,
+		Headers:     headers,
+		Body:        body,
+		LockedUntil: lease.LockedUntil,
+	})
+}
+
+// Release libera a chave de uma requisição que falhou, permitindo nova
+// tentativa, se a reserva ainda for dela
+func (s *IdempotencyService) Release(ctx context.Context, lease *domain.IdempotencyRecord) (err error) {
+	ctx, span := startSpan(ctx, "IdempotencyService.Release")
+	defer func() { endSpan(span, err) }()
+
+	return s.idempotencyRepo.Release(ctx, lease)
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository/memory"
+)
+
+func TestIdempotencyServiceReplay(t *testing.T) {
+	ctx := context.Background()
+	service := NewIdempotencyService(memory.NewIdempotencyRepository(), time.Hour, time.Minute)
+
+	lease, err := service.Begin(ctx, "key", "a")
+	if err != nil || lease == nil || lease.Completed {
+		t.Fatalf("Begin() = %v, %v; want the key reserved", lease, err)
+	}
+	if _, err := service.Begin(ctx, "key", "a"); !errors.Is(err, domain.ErrIdempotencyKeyInProgress) {
+		t.Errorf("Begin() before Complete() error = %v, want ErrIdempotencyKeyInProgress", err)
+	}
+
+	headers := map[string]string{"Location": "/api/books/1"}
+	if err := service.Complete(ctx, lease, 201, headers, []byte(`{"id":"1"}`)); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	record, err := service.Begin(ctx, "key", "a")
+	if err != nil || record == nil || !record.Completed || record.StatusCode != 201 || record.Headers["Location"] != "/api/books/1" || string(record.Body) != `{"id":"1"}` {
+		t.Errorf("Begin() after Complete() = %+v, %v; want the stored response", record, err)
+	}
+	if _, err := service.Begin(ctx, "key", "b"); !errors.Is(err, domain.ErrIdempotencyKeyReused) {
+		t.Errorf("Begin() with another fingerprint error = %v, want ErrIdempotencyKeyReused", err)
+	}
+}
+
+func TestIdempotencyServiceRelease(t *testing.T) {
+	ctx := context.Background()
+	service := NewIdempotencyService(memory.NewIdempotencyRepository(), time.Hour, time.Minute)
+
+	lease, _ := service.Begin(ctx, "key", "a")
+	if _, err := service.Begin(ctx, "key", "b"); !errors.Is(err, domain.ErrIdempotencyKeyReused) {
+		t.Errorf("Begin() with another fingerprint error = %v, want ErrIdempotencyKeyReused", err)
+	}
+	if err := service.Release(ctx, lease); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if record, err := service.Begin(ctx, "key", "b"); record == nil || record.Completed || err != nil {
+		t.Errorf("Begin() after Release() = %v, %v; want the key reserved again", record, err)
+	}
+}
+
+func TestIdempotencyServiceConcurrentBegin(t *testing.T) {
+	ctx := context.Background()
+	service := NewIdempotencyService(memory.NewIdempotencyRepository(), time.Hour, time.Minute)
+
+	var wg sync.WaitGroup
+	var acquired, inProgress atomic.Int32
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			switch record, err := service.Begin(ctx, "key", "a"); {
+			case err == nil && !record.Completed:
+				acquired.Add(1)
+			case errors.Is(err, domain.ErrIdempotencyKeyInProgress):
+				inProgress.Add(1)
+			default:
+				t.Errorf("Begin() = %v, %v", record, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if acquired.Load() != 1 || inProgress.Load() != 19 {
+		t.Errorf("%d requests reserved the key and %d were told it is in progress, want 1 and 19", acquired.Load(), inProgress.Load())
+	}
+}
+
+func TestIdempotencyServiceLockTimeout(t *testing.T) {
+	ctx := context.Background()
+	service := NewIdempotencyService(memory.NewIdempotencyRepository(), time.Hour, time.Millisecond)
+
+	stale, _ := service.Begin(ctx, "key", "a")
+	time.Sleep(5 * time.Millisecond)
+
+	// A requisição original não terminou a tempo: a nova tentativa assume a chave
+	if _, err := service.Begin(ctx, "key", "b"); !errors.Is(err, domain.ErrIdempotencyKeyReused) {
+		t.Errorf("Begin() with another fingerprint error = %v, want ErrIdempotencyKeyReused", err)
+	}
+	lease, err := service.Begin(ctx, "key", "a")
+	if err != nil || lease.Completed {
+		t.Fatalf("Begin() after the lock timeout = %v, %v; want the key reserved", lease, err)
+	}
+
+	// A requisição original, ao terminar, não mexe na reserva da nova tentativa
+	if err := service.Complete(ctx, stale, 201, nil, []byte(`{"id":"stale"}`)); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if err := service.Release(ctx, stale); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if err := service.Complete(ctx, lease, 201, nil, []byte(`{"id":"1"}`)); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if record, err := service.Begin(ctx, "key", "a"); err != nil || !record.Completed || string(record.Body) != `{"id":"1"}` {
+		t.Errorf("Begin() after both requests finished = %+v, %v; want the new request's response", record, err)
+	}
+}
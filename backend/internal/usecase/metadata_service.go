@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/metadata"
+)
+
+type MetadataService struct {
+	provider metadata.MetadataProvider
+}
+
+func NewMetadataService(provider metadata.MetadataProvider) *MetadataService {
+	return &MetadataService{
+		provider: provider,
+	}
+}
+
+// EnrichBook busca os metadados do ISBN do livro nos catálogos externos e os
+// aplica ao livro, que não é gravado. Sem overwrite apenas os campos vazios
+// são preenchidos. Devolve os metadados encontrados e os campos alterados.
+func (s *MetadataService) EnrichBook(ctx context.Context, book *domain.Book, overwrite bool) (_ *domain.BookMetadata, _ []string, err error) {
+	ctx, span := startSpan(ctx, "MetadataService.EnrichBook")
+	defer func() { endSpan(span, err) }()
+
+	if err := normalizeISBN(book); err != nil {
+		return nil, nil, err
+	}
+	if book.ISBN == "" {
+		return nil, nil, domain.NewValidationError("isbn", "isbn is required")
+	}
+
+	result, err := s.provider.Lookup(ctx, domain.ISBN(book.ISBN))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changed := result.ApplyTo(book, overwrite)
+	return result, changed, nil
+}
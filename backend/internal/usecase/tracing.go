@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Sem um TracerProvider configurado os spans não são gravados
+var tracer = otel.Tracer("github.com/diogo-aparecido-smartfit/bookflow/backend/internal/usecase")
+
+// startSpan abre o span de um método dos serviços, filho do span da requisição
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan registra o erro devolvido pelo método, se houver, e fecha o span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
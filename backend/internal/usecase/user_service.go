@@ -5,12 +5,19 @@ import (
     "time"
 
     "github.com/google/uuid"
+    "go.opentelemetry.io/otel/attribute"
     "golang.org/x/crypto/bcrypt"
 
     "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
     "github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository"
 )
 
+// Tamanho mínimo das senhas e a mensagem de validação correspondente
+const (
+    minPasswordLength       = 6
+    passwordTooShortMessage = "password must have at least 6 characters"
+)
+
 type UserService struct {
     userRepo repository.UserRepository
 }
@@ -21,11 +28,25 @@ func NewUserService(userRepo repository.UserRepository) *UserService {
     }
 }
 
-func (s *UserService) GetUser(ctx context.Context, id string) (*domain.User, error) {
+func (s *UserService) GetUser(ctx context.Context, id string) (_ *domain.User, err error) {
+    ctx, span := startSpan(ctx, "UserService.GetUser", attribute.String("user.id", id))
+    defer func() { endSpan(span, err) }()
+
     return s.userRepo.FindByID(ctx, id)
 }
 
-func (s *UserService) ListUsers(ctx context.Context, page, pageSize int) ([]*domain.User, error) {
+// GetUserByEmail busca o usuário pelo email
+func (s *UserService) GetUserByEmail(ctx context.Context, email string) (_ *domain.User, err error) {
+    ctx, span := startSpan(ctx, "UserService.GetUserByEmail")
+    defer func() { endSpan(span, err) }()
+
+    return s.userRepo.FindByEmail(ctx, email)
+}
+
+func (s *UserService) ListUsers(ctx context.Context, page, pageSize int) (_ []*domain.User, err error) {
+    ctx, span := startSpan(ctx, "UserService.ListUsers")
+    defer func() { endSpan(span, err) }()
+
     if page < 1 {
         page = 1
     }
@@ -37,14 +58,27 @@ func (s *UserService) ListUsers(ctx context.Context, page, pageSize int) ([]*dom
     return s.userRepo.FindAll(ctx, pageSize, offset)
 }
 
-func (s *UserService) CreateUser(ctx context.Context, user *domain.User) error {
-    if user.Name == "" || user.Email == "" || user.Password == "" {
-        return domain.ErrInvalidInput
+func (s *UserService) CreateUser(ctx context.Context, user *domain.User) (err error) {
+    ctx, span := startSpan(ctx, "UserService.CreateUser")
+    defer func() { endSpan(span, err) }()
+
+    validation := &domain.ValidationError{}
+    if user.Name == "" {
+        validation.Add("name", "name is required")
+    }
+    if user.Email == "" {
+        validation.Add("email", "email is required")
+    }
+    if len(user.Password) < minPasswordLength {
+        validation.Add("password", passwordTooShortMessage)
+    }
+    if err := validation.Err(); err != nil {
+        return err
     }
     
     existingUser, err := s.userRepo.FindByEmail(ctx, user.Email)
     if err == nil && existingUser != nil {
-        return domain.ErrInvalidInput
+        return domain.ErrEmailTaken
     }
     
     hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
@@ -57,15 +91,25 @@ func (s *UserService) CreateUser(ctx context.Context, user *domain.User) error {
     user.CreatedAt = now
     user.UpdatedAt = now
     user.Password = string(hashedPassword)
+    user.Disabled = false
     
     return s.userRepo.Create(ctx, user)
 }
 
-func (s *UserService) UpdateUser(ctx context.Context, id string, user *domain.User) error {
+// UpdateUser altera os campos preenchidos do usuário. user.Version deve conter a
+// versão conhecida pelo cliente; zero ignora a verificação.
+func (s *UserService) UpdateUser(ctx context.Context, id string, user *domain.User) (err error) {
+    ctx, span := startSpan(ctx, "UserService.UpdateUser", attribute.String("user.id", id))
+    defer func() { endSpan(span, err) }()
+
     existingUser, err := s.userRepo.FindByID(ctx, id)
     if err != nil {
         return err
     }
+
+    if user.Version != 0 && user.Version != existingUser.Version {
+        return domain.ErrVersionConflict
+    }
     
     if user.Name != "" {
         existingUser.Name = user.Name
@@ -74,7 +118,7 @@ func (s *UserService) UpdateUser(ctx context.Context, id string, user *domain.Us
     if user.Email != "" && user.Email != existingUser.Email {
         userWithEmail, err := s.userRepo.FindByEmail(ctx, user.Email)
         if err == nil && userWithEmail != nil {
-            return domain.ErrInvalidInput
+            return domain.ErrEmailTaken
         }
         existingUser.Email = user.Email
     }
@@ -92,20 +136,148 @@ func (s *UserService) UpdateUser(ctx context.Context, id string, user *domain.Us
     return s.userRepo.Update(ctx, existingUser)
 }
 
-func (s *UserService) DeleteUser(ctx context.Context, id string) error {
-    return s.userRepo.Delete(ctx, id)
+// PatchUser aplica uma alteração parcial sobre o usuário existente. Uma senha
+// preenchida pelo patch é tratada como nova senha; vazia mantém a atual.
+// version zero ignora a verificação de versão.
+func (s *UserService) PatchUser(ctx context.Context, id string, version int64, apply func(user *domain.User) error) (_ *domain.User, err error) {
+    ctx, span := startSpan(ctx, "UserService.PatchUser", attribute.String("user.id", id))
+    defer func() { endSpan(span, err) }()
+
+    existingUser, err := s.userRepo.FindByID(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+
+    if version != 0 && version != existingUser.Version {
+        return nil, domain.ErrVersionConflict
+    }
+
+    user := *existingUser
+    user.Password = ""
+
+    if err := apply(&user); err != nil {
+        return nil, err
+    }
+
+    user.ID = id
+    user.Version = existingUser.Version
+    if err := user.Validate(); err != nil {
+        return nil, err
+    }
+
+    if user.Email != existingUser.Email {
+        userWithEmail, err := s.userRepo.FindByEmail(ctx, user.Email)
+        if err == nil && userWithEmail != nil {
+            return nil, domain.ErrEmailTaken
+        }
+    }
+
+    if user.Password == "" {
+        user.Password = existingUser.Password
+    } else {
+        if len(user.Password) < minPasswordLength {
+            return nil, domain.NewValidationError("password", passwordTooShortMessage)
+        }
+        hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+        if err != nil {
+            return nil, err
+        }
+        user.Password = string(hashedPassword)
+    }
+
+    user.UpdatedAt = time.Now()
+
+    if err := s.userRepo.Update(ctx, &user); err != nil {
+        return nil, err
+    }
+
+    return &user, nil
+}
+
+// DeleteUser remove o usuário se version ainda for a versão atual; zero remove
+// a versão corrente
+func (s *UserService) DeleteUser(ctx context.Context, id string, version int64) (err error) {
+    ctx, span := startSpan(ctx, "UserService.DeleteUser", attribute.String("user.id", id))
+    defer func() { endSpan(span, err) }()
+
+    if version == 0 {
+        user, err := s.userRepo.FindByID(ctx, id)
+        if err != nil {
+            return err
+        }
+        version = user.Version
+    }
+
+    return s.userRepo.Delete(ctx, id, version)
 }
 
-func (s *UserService) Authenticate(ctx context.Context, email, password string) (*domain.User, error) {
+// SetUserDisabled ativa ou desativa o usuário. Um usuário desativado continua
+// cadastrado, mas não consegue mais se autenticar.
+func (s *UserService) SetUserDisabled(ctx context.Context, id string, disabled bool) (_ *domain.User, err error) {
+    ctx, span := startSpan(ctx, "UserService.SetUserDisabled", attribute.String("user.id", id))
+    defer func() { endSpan(span, err) }()
+
+    user, err := s.userRepo.FindByID(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+
+    if user.Disabled == disabled {
+        return user, nil
+    }
+
+    user.Disabled = disabled
+    user.UpdatedAt = time.Now()
+
+    if err := s.userRepo.Update(ctx, user); err != nil {
+        return nil, err
+    }
+
+    return user, nil
+}
+
+// ResetPassword substitui a senha do usuário sem exigir a senha atual
+func (s *UserService) ResetPassword(ctx context.Context, id, password string) (err error) {
+    ctx, span := startSpan(ctx, "UserService.ResetPassword", attribute.String("user.id", id))
+    defer func() { endSpan(span, err) }()
+
+    if len(password) < minPasswordLength {
+        return domain.NewValidationError("password", passwordTooShortMessage)
+    }
+
+    user, err := s.userRepo.FindByID(ctx, id)
+    if err != nil {
+        return err
+    }
+
+    hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+    if err != nil {
+        return err
+    }
+
+    user.Password = string(hashedPassword)
+    user.UpdatedAt = time.Now()
+
+    return s.userRepo.Update(ctx, user)
+}
+
+func (s *UserService) Authenticate(ctx context.Context, email, password string) (_ *domain.User, err error) {
+    ctx, span := startSpan(ctx, "UserService.Authenticate")
+    defer func() { endSpan(span, err) }()
+
     user, err := s.userRepo.FindByEmail(ctx, email)
     if err != nil {
-        return nil, domain.ErrUserNotFound
+        return nil, err
     }
     
     err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
     if err != nil {
         return nil, domain.ErrInvalidInput
     }
+
+    if user.Disabled {
+        return nil, domain.ErrUserDisabled
+    }
     
     return user, nil
 }
\ No newline at end of file
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/domain"
+	"github.com/diogo-aparecido-smartfit/bookflow/backend/internal/repository/memory"
+)
+
+func TestUserServiceAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	service := NewUserService(memory.NewUserRepository())
+
+	user := &domain.User{Name: "Maria", Email: "maria@example.com", Password: "secret1"}
+	if err := service.CreateUser(ctx, user); err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if user.Password == "secret1" {
+		t.Error("CreateUser() stored the plain text password")
+	}
+
+	duplicate := &domain.User{Name: "Outra Maria", Email: "maria@example.com", Password: "secret2"}
+	if err := service.CreateUser(ctx, duplicate); !errors.Is(err, domain.ErrEmailTaken) {
+		t.Errorf("CreateUser() with a taken email error = %v, want ErrEmailTaken", err)
+	}
+
+	if got, err := service.Authenticate(ctx, "maria@example.com", "secret1"); err != nil || got.ID != user.ID {
+		t.Errorf("Authenticate() = %v, %v; want the created user", got, err)
+	}
+	if _, err := service.Authenticate(ctx, "maria@example.com", "wrong"); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Authenticate() with a wrong password error = %v, want ErrInvalidInput", err)
+	}
+	if _, err := service.Authenticate(ctx, "nobody@example.com", "secret1"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("Authenticate() with an unknown email error = %v, want ErrUserNotFound", err)
+	}
+
+	if _, err := service.SetUserDisabled(ctx, user.ID, true); err != nil {
+		t.Fatalf("SetUserDisabled() error = %v", err)
+	}
+	if _, err := service.Authenticate(ctx, "maria@example.com", "secret1"); !errors.Is(err, domain.ErrUserDisabled) {
+		t.Errorf("Authenticate() of a disabled user error = %v, want ErrUserDisabled", err)
+	}
+}
+
+func TestUserServiceCreateUserValidation(t *testing.T) {
+	service := NewUserService(memory.NewUserRepository())
+
+	err := service.CreateUser(context.Background(), &domain.User{Email: "maria@example.com", Password: "123"})
+	var validation *domain.ValidationError
+	if !errors.As(err, &validation) || !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("CreateUser() error = %v, want a ValidationError", err)
+	}
+
+	var fields []string
+	for _, field := range validation.Fields {
+		fields = append(fields, field.Field)
+	}
+	if len(fields) != 2 || fields[0] != "name" || fields[1] != "password" {
+		t.Errorf("CreateUser() invalid fields = %v, want [name password]", fields)
+	}
+}
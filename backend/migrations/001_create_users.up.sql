@@ -7,4 +7,4 @@ CREATE TABLE IF NOT EXISTS users (
     updated_at TIMESTAMP NOT NULL
 );
 
-CREATE INDEX idx_users_email ON users(email);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
\ No newline at end of file
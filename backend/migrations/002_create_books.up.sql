@@ -10,6 +10,6 @@ CREATE TABLE IF NOT EXISTS books (
     updated_at TIMESTAMP NOT NULL
 );
 
-CREATE INDEX idx_books_title ON books(title);
-CREATE INDEX idx_books_author ON books(author);
-CREATE INDEX idx_books_status ON books(status);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_books_title ON books(title);
+CREATE INDEX IF NOT EXISTS idx_books_author ON books(author);
+CREATE INDEX IF NOT EXISTS idx_books_status ON books(status);
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE books DROP COLUMN IF EXISTS subjects;
+ALTER TABLE books DROP COLUMN IF EXISTS publication_year;
+ALTER TABLE books DROP COLUMN IF EXISTS publisher;
+ALTER TABLE books ALTER COLUMN author TYPE VARCHAR(100);
+ALTER TABLE books ALTER COLUMN title TYPE VARCHAR(100);
@@ -0,0 +1,5 @@
+ALTER TABLE books ALTER COLUMN title TYPE VARCHAR(255);
+ALTER TABLE books ALTER COLUMN author TYPE VARCHAR(255);
+ALTER TABLE books ADD COLUMN IF NOT EXISTS publisher VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE books ADD COLUMN IF NOT EXISTS publication_year INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE books ADD COLUMN IF NOT EXISTS subjects TEXT NOT NULL DEFAULT '[]';
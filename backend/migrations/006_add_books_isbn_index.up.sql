@@ -0,0 +1,2 @@
+-- Índice para a busca por ISBN, que ignora hífens e espaços
+CREATE INDEX IF NOT EXISTS idx_books_isbn_digits ON books ((regexp_replace(upper(isbn), '[^0-9X]', '', 'g')));
@@ -0,0 +1 @@
+ALTER TABLE books ADD COLUMN IF NOT EXISTS cover_variants TEXT NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+-- As chaves em andamento antes desta migração podem ser assumidas de imediato
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP NOT NULL DEFAULT '1970-01-01';
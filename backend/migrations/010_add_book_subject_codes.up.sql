@@ -0,0 +1,2 @@
+-- Códigos MARC das subdivisões dos assuntos importados de registros MARC
+ALTER TABLE books ADD COLUMN IF NOT EXISTS subject_codes TEXT NOT NULL DEFAULT '[]';
@@ -0,0 +1,33 @@
+// Package migrations embute os scripts SQL no binário do servidor
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// FS contém os pares NNN_nome.up.sql e NNN_nome.down.sql do Postgres
+//
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFS embed.FS
+
+// SQLite contém as migrações do SQLite, que partem do esquema atual em vez de
+// repetir o histórico do Postgres
+var SQLite = func() fs.FS {
+	sub, err := fs.Sub(sqliteFS, "sqlite")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}()
+
+// For devolve as migrações do driver de banco ("postgres" ou "sqlite")
+func For(driver string) fs.FS {
+	if driver == "sqlite" {
+		return SQLite
+	}
+	return FS
+}
@@ -0,0 +1,44 @@
+-- Esquema equivalente ao das migrações 001 a 008 do Postgres
+CREATE TABLE IF NOT EXISTS users (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    email TEXT NOT NULL UNIQUE,
+    password TEXT NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS books (
+    id TEXT PRIMARY KEY,
+    title TEXT NOT NULL,
+    author TEXT NOT NULL,
+    isbn TEXT,
+    description TEXT,
+    cover_url TEXT,
+    cover_variants TEXT NOT NULL DEFAULT '{}',
+    publisher TEXT NOT NULL DEFAULT '',
+    publication_year INTEGER NOT NULL DEFAULT 0,
+    subjects TEXT NOT NULL DEFAULT '[]',
+    status TEXT NOT NULL DEFAULT 'available',
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_books_created_at ON books(created_at);
+CREATE INDEX IF NOT EXISTS idx_books_updated_at ON books(updated_at);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key TEXT PRIMARY KEY,
+    fingerprint TEXT NOT NULL,
+    completed BOOLEAN NOT NULL DEFAULT FALSE,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    response_headers TEXT NOT NULL DEFAULT '{}',
+    response_body BLOB,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
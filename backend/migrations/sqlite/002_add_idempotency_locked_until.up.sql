@@ -0,0 +1,2 @@
+-- Equivalente à migração 009 do Postgres
+ALTER TABLE idempotency_keys ADD COLUMN locked_until TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00';
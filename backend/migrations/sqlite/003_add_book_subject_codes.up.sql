@@ -0,0 +1,2 @@
+-- Equivalente à migração 010 do Postgres
+ALTER TABLE books ADD COLUMN subject_codes TEXT NOT NULL DEFAULT '[]';
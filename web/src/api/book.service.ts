@@ -14,17 +14,23 @@ export const bookService = {
     return response.data;
   },
 
-  create: async (book: Omit<Book, "id" | "createdAt" | "updatedAt">) => {
+  create: async (
+    book: Omit<Book, "id" | "version" | "createdAt" | "updatedAt">
+  ) => {
     const response = await apiClient.post<Book>("/books", book);
     return response.data;
   },
 
-  update: async (id: string, book: Partial<Book>) => {
-    const response = await apiClient.put<Book>(`/books/${id}`, book);
+  update: async (id: string, book: Partial<Book>, version: number) => {
+    const response = await apiClient.put<Book>(`/books/${id}`, book, {
+      headers: { "If-Match": `"${version}"` },
+    });
     return response.data;
   },
 
-  delete: async (id: string) => {
-    await apiClient.delete(`/books/${id}`);
+  delete: async (id: string, version: number) => {
+    await apiClient.delete(`/books/${id}`, {
+      headers: { "If-Match": `"${version}"` },
+    });
   },
 };
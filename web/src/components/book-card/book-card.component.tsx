@@ -14,7 +14,14 @@ export function BookCard({ book }: BookCardProps) {
       <div className="h-60 max-h-60 bg-gray-200">
         {book.cover_url ? (
           <img
-            src={book.cover_url}
+            src={book.covers?.thumbnail ?? book.cover_url}
+            srcSet={
+              book.covers
+                ? `${book.covers.thumbnail} 200w, ${book.covers.medium} 400w`
+                : undefined
+            }
+            sizes="(min-width: 1024px) 25vw, (min-width: 640px) 50vw, 100vw"
+            loading="lazy"
             alt={`Capa de ${book.title}`}
             className="w-full h-full object-cover"
           />
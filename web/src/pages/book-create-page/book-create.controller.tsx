@@ -55,7 +55,7 @@ export function useBookCreate() {
         navigate(`/books/${response.id}`);
       } catch (error: any) {
         setError(
-          error.response?.data?.error ||
+          error.response?.data?.detail ||
             "Failed to create book. Please try again."
         );
       } finally {
@@ -22,7 +22,7 @@ export function useBookDetail() {
         const data = await bookService.getById(id);
         setBook(data);
       } catch (error: any) {
-        setError(error.response?.data?.error || "Failed to load book details");
+        setError(error.response?.data?.detail || "Failed to load book details");
       } finally {
         setLoading(false);
       }
@@ -33,14 +33,14 @@ export function useBookDetail() {
 
   const handleDelete = useCallback(async () => {
     try {
-      if (!id) return;
+      if (!id || !book) return;
 
-      await bookService.delete(id);
+      await bookService.delete(id, book.version);
       navigate("/books");
     } catch (error: any) {
-      setError(error.response?.data?.error || "Failed to delete book");
+      setError(error.response?.data?.detail || "Failed to delete book");
     }
-  }, [id, navigate]);
+  }, [id, book, navigate]);
 
   const toggleDeleteConfirm = () => {
     setDeleteConfirm((prev) => !prev);
@@ -62,7 +62,7 @@ export function BookDetailPage() {
           <div className="md:w-1/3 bg-gray-200">
             {book.cover_url ? (
               <img
-                src={book.cover_url}
+                src={book.covers?.large ?? book.cover_url}
                 alt={`Capa de ${book.title}`}
                 className="w-full h-full object-cover min-h-[300px]"
               />
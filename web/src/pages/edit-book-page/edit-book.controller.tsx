@@ -30,6 +30,9 @@ export function useEditBook(bookId: string) {
           isbn: bookData.isbn || "",
           description: bookData.description || "",
           cover_url: bookData.cover_url || "",
+          publisher: bookData.publisher || "",
+          publication_year: bookData.publication_year || 0,
+          subjects: bookData.subjects || [],
           status: bookData.status,
         });
       } catch (error) {
@@ -66,7 +69,9 @@ export function useEditBook(bookId: string) {
         return;
       }
 
-      await bookService.update(bookId, formData as Book);
+      if (!book) return;
+
+      await bookService.update(bookId, formData as Book, book.version);
       navigate(`/books/${bookId}`);
     } catch (error) {
       console.error("Error updating book:", error);
@@ -45,7 +45,7 @@ export function useLogin() {
       // eslint-disable-next-line @typescript-eslint/no-explicit-any
     } catch (error: any) {
       setError(
-        error.response?.data?.error || "Login failed. Please try again."
+        error.response?.data?.detail || "Login failed. Please try again."
       );
     } finally {
       setLoading(false);
@@ -35,7 +35,7 @@ export function useRegister() {
       // eslint-disable-next-line @typescript-eslint/no-explicit-any
     } catch (error: any) {
       setError(
-        error.response?.data?.error || "Registration failed. Please try again."
+        error.response?.data?.detail || "Registration failed. Please try again."
       );
     } finally {
       setLoading(false);
@@ -5,11 +5,22 @@ export interface Book {
   isbn: string;
   description: string;
   cover_url: string;
+  covers?: CoverVariants | null;
+  publisher?: string;
+  publication_year?: number;
+  subjects?: string[];
   status: "available" | "borrowed" | "lost";
+  version: number;
   created_at: string;
   updated_at: string;
 }
 
+export interface CoverVariants {
+  thumbnail: string;
+  medium: string;
+  large: string;
+}
+
 export interface User {
   id: string;
   name: string;